@@ -0,0 +1,444 @@
+package ekaweb_client
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/inaneverb/ekaweb/v2"
+	"github.com/inaneverb/ekaweb/v2/private"
+)
+
+type (
+	// BreakerState is a state of the circuit of CircuitBreaker().
+	BreakerState uint8
+
+	// BreakerKeyFunc returns a key of the circuit, the HTTP request belongs to.
+	// Each circuit has its own state.
+	BreakerKeyFunc func(ctx context.Context, method, path string) string
+
+	// CircuitOpenError is an error, that is returned by CircuitBreaker()
+	// interceptor instead of performing HTTP request, if the circuit is open
+	// (or if it's half-open and the limit of probe requests is reached).
+	CircuitOpenError struct {
+		Key        string
+		RetryAfter time.Duration // 0 if the circuit is half-open
+	}
+
+	// BreakerOption is a callback that allows to modify CircuitBreaker()
+	// interceptor under its construction.
+	BreakerOption func(b *_Breaker)
+
+	_Breaker struct {
+		keyFunc          BreakerKeyFunc
+		maxConsecutive   int
+		failureRate      float64
+		minRequests      int
+		window           time.Duration
+		openTimeout      time.Duration
+		halfOpenRequests int
+		idleTimeout      time.Duration
+		isFailure        func(statusCode int, err error) bool
+		onStateChange    func(key string, from, to BreakerState)
+		log              ekaweb.Logger
+
+		mu        sync.Mutex
+		circuits  map[string]*_Circuit
+		nextEvict time.Time
+	}
+
+	_Circuit struct {
+		state       BreakerState
+		generation  uint64
+		expiry      time.Time // end of the window (closed), of the timeout (open)
+		requests    int
+		failures    int
+		consecutive int
+		inFlight    int // half-open only
+		succeeded   int // half-open only
+		lastUsed    time.Time
+	}
+
+	_BreakerStateChange struct {
+		key      string
+		from, to BreakerState
+	}
+)
+
+const (
+	BreakerStateClosed BreakerState = iota
+	BreakerStateOpen
+	BreakerStateHalfOpen
+)
+
+// ErrCircuitOpen is a typed CircuitOpenError "pattern".
+// Use errors.Is(err, ErrCircuitOpen) to check whether an error
+// is CircuitOpenError.
+var ErrCircuitOpen = (*CircuitOpenError)(nil)
+
+// CircuitBreaker returns a client interceptor, that stops performing
+// HTTP requests to the degraded upstream, returning CircuitOpenError instead.
+//
+// Each circuit (see WithBreakerKey()) is closed initially. It's opened,
+// when the number of consecutive failures reaches the threshold
+// (see WithBreakerConsecutiveFailures()) or when the failure rate does
+// (see WithBreakerFailureRate()). After the timeout (see WithBreakerOpenTimeout())
+// it becomes half-open, allowing a limited number of probe requests
+// (see WithBreakerHalfOpenRequests()). If all of them succeed, the circuit
+// is closed, otherwise it's opened again.
+//
+// By default, a failure is an error w/o HTTP response (except context
+// cancellation) or 5xx HTTP status code (see WithBreakerFailureIf()).
+//
+// Closed circuits, that have no requests during the idle timeout, are evicted
+// (see WithBreakerIdleTimeout()), so the number of circuits is bounded
+// by the number of keys, that are in use recently.
+func CircuitBreaker(options ...BreakerOption) ekaweb.ClientInterceptor {
+
+	var b = _Breaker{
+		keyFunc:          BreakerKeyByHost,
+		maxConsecutive:   5,
+		openTimeout:      30 * time.Second,
+		halfOpenRequests: 1,
+		idleTimeout:      5 * time.Minute,
+		isFailure:        defaultIsFailure,
+		circuits:         make(map[string]*_Circuit),
+	}
+
+	for _, option := range options {
+		if option != nil {
+			option(&b)
+		}
+	}
+
+	return func(next ekaweb.Client) ekaweb.Client {
+		return ekaweb.ClientFunc(func(
+			ctx context.Context, method, path string, headers http.Header,
+			req ekaweb.ClientRequest, resp ekaweb.ClientResponse) error {
+
+			var key = b.keyFunc(ctx, method, path)
+
+			var generation, err = b.before(key)
+			if err != nil {
+				return err
+			}
+
+			var proxyResp = ekaweb_private.NewClientResponseRecorder(resp, nil)
+			err = next.Do(ctx, method, path, headers, req, proxyResp.Wrap())
+
+			b.after(key, generation, b.isFailure(proxyResp.StatusCode(), err))
+			return err
+		})
+	}
+}
+
+// BreakerKeyByHost is a BreakerKeyFunc, that returns a host of the requested
+// path, if it's an absolute URL, or an empty string otherwise
+// (meaning the host of ekaweb.WithHostAddr()). It's used by default.
+func BreakerKeyByHost(_ context.Context, _, path string) string {
+	if u, err := url.Parse(path); err == nil {
+		return u.Host
+	}
+	return ""
+}
+
+// BreakerKeyByURLTemplate is a BreakerKeyFunc, that returns a URL template
+// of the HTTP request (see ekaweb.ClientWithURLTemplate()) or the path itself.
+// The path may contain IDs, so if URL template is not set for all requests,
+// the number of keys is not bounded (see WithBreakerIdleTimeout()).
+func BreakerKeyByURLTemplate(ctx context.Context, _, path string) string {
+	return ekaweb.ClientURLTemplate(ctx, path)
+}
+
+// WithBreakerKey sets a BreakerKeyFunc. Default: BreakerKeyByHost.
+func WithBreakerKey(keyFunc BreakerKeyFunc) BreakerOption {
+	return func(b *_Breaker) {
+		if keyFunc != nil {
+			b.keyFunc = keyFunc
+		}
+	}
+}
+
+// WithBreakerConsecutiveFailures sets the number of consecutive failures,
+// the circuit is opened after. Non-positive value disables it. Default: 5.
+func WithBreakerConsecutiveFailures(n int) BreakerOption {
+	return func(b *_Breaker) {
+		b.maxConsecutive = max(n, 0)
+	}
+}
+
+// WithBreakerFailureRate sets the failure rate (0..1), the circuit is opened
+// after, if there were at least 'minRequests' requests during the 'window'.
+// Counters are reset each 'window'. Disabled by default.
+func WithBreakerFailureRate(rate float64, minRequests int, window time.Duration) BreakerOption {
+	return func(b *_Breaker) {
+		if rate > 0 && rate <= 1 && minRequests > 0 && window > 0 {
+			b.failureRate, b.minRequests, b.window = rate, minRequests, window
+		}
+	}
+}
+
+// WithBreakerOpenTimeout sets the time the circuit is open for,
+// before it becomes half-open. Default: 30s.
+func WithBreakerOpenTimeout(timeout time.Duration) BreakerOption {
+	return func(b *_Breaker) {
+		if timeout > 0 {
+			b.openTimeout = timeout
+		}
+	}
+}
+
+// WithBreakerHalfOpenRequests sets the number of probe requests,
+// that are allowed in the half-open state and must succeed to close
+// the circuit. Default: 1.
+func WithBreakerHalfOpenRequests(n int) BreakerOption {
+	return func(b *_Breaker) {
+		if n > 0 {
+			b.halfOpenRequests = n
+		}
+	}
+}
+
+// WithBreakerIdleTimeout sets the time, the closed circuit w/o requests
+// is evicted after (its consecutive failures are forgotten).
+// Non-positive value disables eviction, so use it only if the number
+// of keys is bounded (see WithBreakerKey()). Default: 5m.
+func WithBreakerIdleTimeout(timeout time.Duration) BreakerOption {
+	return func(b *_Breaker) {
+		b.idleTimeout = max(timeout, 0)
+	}
+}
+
+// WithBreakerFailureIf sets a callback, that reports whether the result
+// of HTTP request is a failure. 'statusCode' is 0, if there's no HTTP response.
+func WithBreakerFailureIf(cb func(statusCode int, err error) bool) BreakerOption {
+	return func(b *_Breaker) {
+		if cb != nil {
+			b.isFailure = cb
+		}
+	}
+}
+
+// WithBreakerOnStateChange sets a callback, that is called
+// when the state of any circuit is changed.
+func WithBreakerOnStateChange(cb func(key string, from, to BreakerState)) BreakerOption {
+	return func(b *_Breaker) {
+		b.onStateChange = cb
+	}
+}
+
+// WithBreakerLogger sets a logger, state changes of circuits are logged to.
+func WithBreakerLogger(log ekaweb.Logger) BreakerOption {
+	return func(b *_Breaker) {
+		b.log = log
+	}
+}
+
+func (s BreakerState) String() string {
+	switch s {
+	case BreakerStateClosed:
+		return "closed"
+	case BreakerStateOpen:
+		return "open"
+	case BreakerStateHalfOpen:
+		return "half-open"
+	default:
+		return "unknown"
+	}
+}
+
+func (e *CircuitOpenError) Error() string {
+	const D = "circuit breaker is open"
+	if e == nil {
+		return D
+	}
+	return D + " (key: " + e.Key + ")"
+}
+
+func (e *CircuitOpenError) Is(other error) bool {
+	var _, ok = other.(*CircuitOpenError)
+	return ok
+}
+
+////////////////////////////////////////////////////////////////////////////////
+///// PRIVATE METHODS //////////////////////////////////////////////////////////
+////////////////////////////////////////////////////////////////////////////////
+
+// before checks whether HTTP request may be performed for the circuit
+// with given key, returning the current generation of the circuit.
+func (b *_Breaker) before(key string) (uint64, error) {
+
+	var changes []_BreakerStateChange
+	defer func() { b.notify(changes) }()
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	var now = time.Now()
+	b.evict(now)
+
+	var c = b.circuit(key, now)
+	b.refresh(c, key, now, &changes)
+
+	switch {
+	case c.state == BreakerStateOpen:
+		return 0, &CircuitOpenError{key, c.expiry.Sub(now)}
+
+	case c.state == BreakerStateHalfOpen && c.inFlight >= b.halfOpenRequests:
+		return 0, &CircuitOpenError{Key: key}
+
+	case c.state == BreakerStateHalfOpen:
+		c.inFlight++
+	}
+
+	return c.generation, nil
+}
+
+// after records the result of HTTP request, performed for the circuit
+// with given key. Results of the stale generations are ignored.
+func (b *_Breaker) after(key string, generation uint64, isFailure bool) {
+
+	var changes []_BreakerStateChange
+	defer func() { b.notify(changes) }()
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	var now = time.Now()
+	var c = b.circuit(key, now)
+
+	if b.refresh(c, key, now, &changes); c.generation != generation {
+		return
+	}
+
+	switch c.state {
+	case BreakerStateClosed:
+		c.requests++
+		if !isFailure {
+			c.consecutive = 0
+			return
+		}
+		c.failures++
+		c.consecutive++
+		if b.shouldTrip(c) {
+			b.setState(c, key, BreakerStateOpen, now, &changes)
+		}
+
+	case BreakerStateHalfOpen:
+		c.inFlight--
+		if isFailure {
+			b.setState(c, key, BreakerStateOpen, now, &changes)
+		} else if c.succeeded++; c.succeeded >= b.halfOpenRequests {
+			b.setState(c, key, BreakerStateClosed, now, &changes)
+		}
+	}
+}
+
+// circuit returns the circuit with given key, creating it if necessary,
+// and marks it as used. The mutex must be locked.
+func (b *_Breaker) circuit(key string, now time.Time) *_Circuit {
+	var c = b.circuits[key]
+	if c == nil {
+		c = new(_Circuit)
+		if b.window > 0 {
+			c.expiry = now.Add(b.window)
+		}
+		b.circuits[key] = c
+	}
+	c.lastUsed = now
+	return c
+}
+
+// evict removes closed circuits, that are not used during the idle timeout.
+// The circuits are scanned at most once per the idle timeout.
+// The mutex must be locked.
+func (b *_Breaker) evict(now time.Time) {
+
+	if b.idleTimeout <= 0 || now.Before(b.nextEvict) {
+		return
+	}
+
+	b.nextEvict = now.Add(b.idleTimeout)
+
+	for key, c := range b.circuits {
+		if c.state == BreakerStateClosed && now.Sub(c.lastUsed) >= b.idleTimeout {
+			delete(b.circuits, key)
+		}
+	}
+}
+
+// refresh makes the open circuit half-open after the timeout
+// and resets the failure rate counters of the closed one after the window.
+func (b *_Breaker) refresh(
+	c *_Circuit, key string, now time.Time, changes *[]_BreakerStateChange) {
+
+	switch {
+	case c.expiry.IsZero() || now.Before(c.expiry):
+	case c.state == BreakerStateOpen:
+		b.setState(c, key, BreakerStateHalfOpen, now, changes)
+	case c.state == BreakerStateClosed:
+		// Consecutive failures are not bound to the window.
+		c.requests, c.failures = 0, 0
+		c.expiry = now.Add(b.window)
+	}
+}
+
+func (b *_Breaker) shouldTrip(c *_Circuit) bool {
+	return (b.maxConsecutive > 0 && c.consecutive >= b.maxConsecutive) ||
+		(b.failureRate > 0 && c.requests >= b.minRequests &&
+			float64(c.failures)/float64(c.requests) >= b.failureRate)
+}
+
+// setState changes the state of the circuit, starting its new generation.
+func (b *_Breaker) setState(c *_Circuit, key string,
+	to BreakerState, now time.Time, changes *[]_BreakerStateChange) {
+
+	var from = c.state
+	*c = _Circuit{state: to, generation: c.generation + 1}
+
+	switch {
+	case to == BreakerStateOpen:
+		c.expiry = now.Add(b.openTimeout)
+	case to == BreakerStateClosed && b.window > 0:
+		c.expiry = now.Add(b.window)
+	}
+
+	if from != to {
+		*changes = append(*changes, _BreakerStateChange{key, from, to})
+	}
+}
+
+// notify calls the callback and logs state changes.
+// The mutex must not be locked.
+func (b *_Breaker) notify(changes []_BreakerStateChange) {
+	for _, change := range changes {
+		if b.onStateChange != nil {
+			b.onStateChange(change.key, change.from, change.to)
+		}
+		if b.log == nil {
+			continue
+		}
+		const D = "Client.CircuitBreaker: Circuit %q state is changed: %s -> %s."
+		if change.to == BreakerStateOpen {
+			b.log.Warn(D, change.key, change.from, change.to)
+		} else {
+			b.log.Info(D, change.key, change.from, change.to)
+		}
+	}
+}
+
+// defaultIsFailure is a default callback, that reports whether the result
+// of HTTP request is a failure.
+func defaultIsFailure(statusCode int, err error) bool {
+	switch {
+	case statusCode >= 500:
+		return true
+	case statusCode != 0 || err == nil:
+		return false
+	default:
+		return !errors.Is(err, context.Canceled)
+	}
+}
@@ -0,0 +1,42 @@
+package ekaweb_client
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBreakerEvict(t *testing.T) {
+
+	var b = _Breaker{
+		maxConsecutive:   1,
+		openTimeout:      time.Minute,
+		halfOpenRequests: 1,
+		idleTimeout:      time.Minute,
+		circuits:         make(map[string]*_Circuit),
+	}
+
+	var now = time.Now()
+
+	b.evict(now)
+	b.circuit("closed", now)
+	b.circuit("open", now).state = BreakerStateOpen
+
+	// The circuits are not scanned until the idle timeout is passed.
+
+	b.evict(now.Add(time.Second))
+	b.circuit("recent", now.Add(59*time.Second))
+	require.Len(t, b.circuits, 3)
+
+	b.evict(now.Add(time.Minute))
+	require.Len(t, b.circuits, 2)
+	require.Contains(t, b.circuits, "open")
+	require.Contains(t, b.circuits, "recent")
+
+	// Eviction may be disabled.
+
+	b.idleTimeout = 0
+	b.evict(now.Add(time.Hour))
+	require.Len(t, b.circuits, 2)
+}
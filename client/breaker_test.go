@@ -0,0 +1,54 @@
+package ekaweb_client_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/inaneverb/ekaweb/client/v2"
+	"github.com/inaneverb/ekaweb/v2"
+)
+
+func TestCircuitBreaker(t *testing.T) {
+
+	var attempts int
+	var statusCodes = []int{500, 0, 200, 200}
+	var changes []string
+
+	var c = ekaweb.WrapClient(
+		newFakeClient(&attempts, nil, statusCodes...),
+		ekaweb_client.CircuitBreaker(
+			ekaweb_client.WithBreakerConsecutiveFailures(2),
+			ekaweb_client.WithBreakerOpenTimeout(20*time.Millisecond),
+			ekaweb_client.WithBreakerOnStateChange(func(key string, from, to ekaweb_client.BreakerState) {
+				changes = append(changes, from.String()+"->"+to.String())
+			}),
+		),
+	)
+
+	var do = func() error {
+		return c.Do(context.Background(), ekaweb.MethodGet, "/", nil, nil, nil)
+	}
+
+	require.Error(t, do())
+	require.Error(t, do())
+
+	var err = do()
+	require.ErrorIs(t, err, ekaweb_client.ErrCircuitOpen)
+	require.Equal(t, 2, attempts, "request must not be performed, if the circuit is open")
+
+	var openErr *ekaweb_client.CircuitOpenError
+	require.True(t, errors.As(err, &openErr))
+	require.Greater(t, openErr.RetryAfter, time.Duration(0))
+
+	time.Sleep(30 * time.Millisecond)
+
+	require.NoError(t, do())
+	require.NoError(t, do())
+	require.Equal(t, 4, attempts)
+
+	require.Equal(t, []string{"closed->open", "open->half-open", "half-open->closed"}, changes)
+}
@@ -4,7 +4,7 @@ go 1.21.0
 
 require (
 	github.com/goccy/go-json v0.10.2
-	github.com/inaneverb/ekaweb/v2 v2.0.4
+	github.com/inaneverb/ekaweb/v2 v2.1.1
 	github.com/stretchr/testify v1.8.2
 )
 
@@ -0,0 +1,57 @@
+package ekaweb_client
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/inaneverb/ekaweb/v2"
+	"github.com/inaneverb/ekaweb/v2/private"
+)
+
+type (
+	// BeforeHook is called before each HTTP request. It may modify
+	// HTTP headers (it gets a copy) or abort the request, returning an error.
+	BeforeHook func(ctx context.Context, method, path string, headers http.Header) error
+
+	// AfterHook is called after each HTTP request. 'statusCode' is 0,
+	// if there's no HTTP response (e.g. network error).
+	// 'err' is an error that is returned by ekaweb.Client.Do().
+	AfterHook func(ctx context.Context, method, path string, statusCode int, err error)
+)
+
+// Hooks returns a client interceptor, that calls 'before' before
+// and 'after' after each HTTP request. Any of them may be nil.
+// Returns nil (no interceptor) if both are nil.
+//
+// Used after Retry() (see ekaweb.WrapClient()), hooks are called
+// for each attempt, used before - once for all attempts.
+func Hooks(before BeforeHook, after AfterHook) ekaweb.ClientInterceptor {
+
+	if before == nil && after == nil {
+		return nil
+	}
+
+	return func(next ekaweb.Client) ekaweb.Client {
+		return ekaweb.ClientFunc(func(
+			ctx context.Context, method, path string, headers http.Header,
+			req ekaweb.ClientRequest, resp ekaweb.ClientResponse) error {
+
+			if before != nil {
+				headers = cloneHeaders(headers)
+				if err := before(ctx, method, path, headers); err != nil {
+					return err
+				}
+			}
+
+			if after == nil {
+				return next.Do(ctx, method, path, headers, req, resp)
+			}
+
+			var proxyResp = ekaweb_private.NewClientResponseRecorder(resp, nil)
+			var err = next.Do(ctx, method, path, headers, req, proxyResp.Wrap())
+
+			after(ctx, method, path, proxyResp.StatusCode(), err)
+			return err
+		})
+	}
+}
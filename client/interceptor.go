@@ -0,0 +1,112 @@
+package ekaweb_client
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+
+	"github.com/inaneverb/ekaweb/v2"
+	"github.com/inaneverb/ekaweb/v2/middleware"
+	"github.com/inaneverb/ekaweb/v2/private"
+)
+
+// InjectHeaders returns a client interceptor, that calls 'cb'
+// for each outgoing HTTP request, allowing it to set HTTP headers.
+// The headers, passed to Client.Do(), are not modified: 'cb' gets a copy.
+// Returns nil (no interceptor) if 'cb' is nil.
+func InjectHeaders(cb func(ctx context.Context, headers http.Header)) ekaweb.ClientInterceptor {
+
+	if cb == nil {
+		return nil
+	}
+
+	return func(next ekaweb.Client) ekaweb.Client {
+		return ekaweb.ClientFunc(func(
+			ctx context.Context, method, path string, headers http.Header,
+			req ekaweb_private.ClientRequest, resp ekaweb_private.ClientResponse) error {
+
+			headers = cloneHeaders(headers)
+			cb(ctx, headers)
+
+			return next.Do(ctx, method, path, headers, req, resp)
+		})
+	}
+}
+
+// InjectRequestID returns a client interceptor, that sets "X-Request-ID"
+// HTTP header of the outgoing HTTP request, using the request ID
+// of the incoming one (see ekaweb_middleware.ForwardRequestID()).
+// If there's no such request ID, 'fallback' is used to generate it (if any).
+// The header, that is already set, is kept as is.
+func InjectRequestID(fallback func(ctx context.Context) string) ekaweb.ClientInterceptor {
+	return InjectHeaders(func(ctx context.Context, headers http.Header) {
+
+		if headers.Get(ekaweb.HeaderXRequestID) != "" {
+			return
+		}
+
+		var reqID = ekaweb_middleware.RequestIDFromContext(ctx)
+		if reqID == "" && fallback != nil {
+			reqID = fallback(ctx)
+		}
+
+		if reqID != "" {
+			headers.Set(ekaweb.HeaderXRequestID, reqID)
+		}
+	})
+}
+
+// InjectBearerToken returns a client interceptor, that sets
+// "Authorization: Bearer <token>" HTTP header of the outgoing HTTP request,
+// using the token that is returned by 'getter'. If 'getter' returns an error,
+// the HTTP request is not performed and that error is returned.
+// Returns nil (no interceptor) if 'getter' is nil.
+func InjectBearerToken(getter func(ctx context.Context) (string, error)) ekaweb.ClientInterceptor {
+
+	if getter == nil {
+		return nil
+	}
+
+	return func(next ekaweb.Client) ekaweb.Client {
+		return ekaweb.ClientFunc(func(
+			ctx context.Context, method, path string, headers http.Header,
+			req ekaweb_private.ClientRequest, resp ekaweb_private.ClientResponse) error {
+
+			var token, err = getter(ctx)
+			if err != nil {
+				return fmt.Errorf("failed to get bearer token: %w", err)
+			}
+
+			headers = cloneHeaders(headers)
+			headers.Set(ekaweb.HeaderAuthorization, "Bearer "+token)
+
+			return next.Do(ctx, method, path, headers, req, resp)
+		})
+	}
+}
+
+// InjectBasicAuth returns a client interceptor, that sets
+// "Authorization: Basic <credentials>" HTTP header of the outgoing HTTP request.
+func InjectBasicAuth(username, password string) ekaweb.ClientInterceptor {
+
+	var credentials = base64.StdEncoding.EncodeToString([]byte(username + ":" + password))
+	var value = "Basic " + credentials
+
+	return InjectHeaders(func(_ context.Context, headers http.Header) {
+		headers.Set(ekaweb.HeaderAuthorization, value)
+	})
+}
+
+////////////////////////////////////////////////////////////////////////////////
+///// PRIVATE FUNCTIONS ////////////////////////////////////////////////////////
+////////////////////////////////////////////////////////////////////////////////
+
+// cloneHeaders returns a copy of given HTTP headers,
+// or a new empty http.Header if 'headers' is nil.
+func cloneHeaders(headers http.Header) http.Header {
+	if headers == nil {
+		return make(http.Header, 1)
+	}
+	return headers.Clone()
+}
@@ -0,0 +1,60 @@
+package ekaweb_client_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/inaneverb/ekaweb/client/v2"
+	"github.com/inaneverb/ekaweb/v2"
+)
+
+func newHeadersRecorder(to *http.Header) ekaweb.Client {
+	return ekaweb.ClientFunc(func(
+		_ context.Context, _, _ string, headers http.Header,
+		_ ekaweb.ClientRequest, _ ekaweb.ClientResponse) error {
+
+		*to = headers
+		return nil
+	})
+}
+
+func TestInterceptors(t *testing.T) {
+
+	var got http.Header
+	var orig = http.Header{"X-Custom": {"1"}}
+
+	var c = ekaweb.WrapClient(newHeadersRecorder(&got),
+		ekaweb_client.InjectRequestID(func(context.Context) string { return "req-1" }),
+		ekaweb_client.InjectBasicAuth("user", "pass"),
+		nil,
+	)
+
+	var err = c.Do(context.Background(), ekaweb.MethodGet, "/", orig, nil, nil)
+	require.NoError(t, err)
+
+	require.Equal(t, "1", got.Get("X-Custom"))
+	require.Equal(t, "req-1", got.Get(ekaweb.HeaderXRequestID))
+	require.Equal(t, "Basic dXNlcjpwYXNz", got.Get(ekaweb.HeaderAuthorization))
+	require.Len(t, orig, 1, "original headers must not be modified")
+}
+
+func TestInjectBearerToken(t *testing.T) {
+
+	var got http.Header
+	var token, tokenErr = "abc", error(nil)
+
+	var c = ekaweb.WrapClient(newHeadersRecorder(&got),
+		ekaweb_client.InjectBearerToken(func(context.Context) (string, error) {
+			return token, tokenErr
+		}))
+
+	require.NoError(t, c.Do(context.Background(), ekaweb.MethodGet, "/", nil, nil, nil))
+	require.Equal(t, "Bearer abc", got.Get(ekaweb.HeaderAuthorization))
+
+	tokenErr = errors.New("no token")
+	require.ErrorIs(t, c.Do(context.Background(), ekaweb.MethodGet, "/", nil, nil, nil), tokenErr)
+}
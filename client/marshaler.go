@@ -0,0 +1,51 @@
+package ekaweb_client
+
+import (
+	"fmt"
+)
+
+type (
+	// Marshaler is a binary codec (e.g. protobuf, msgpack),
+	// used by RequestMarshal() and ResponseUnmarshal().
+	Marshaler interface {
+		MIMEType() string
+		Marshal(v any) ([]byte, error)
+		Unmarshal(data []byte, v any) error
+	}
+
+	marshaler struct {
+		mimeType  string
+		marshal   func(v any) ([]byte, error)
+		unmarshal func(data []byte, v any) error
+	}
+)
+
+// NewMarshaler returns Marshaler, that is bound to the given 'mimeType'
+// and uses given functions, like msgpack.Marshal() and msgpack.Unmarshal().
+// Any of functions may be nil, meaning that Marshaler is only
+// for decoding or encoding.
+func NewMarshaler(
+	mimeType string,
+	marshal func(v any) ([]byte, error),
+	unmarshal func(data []byte, v any) error) Marshaler {
+
+	return &marshaler{mimeType, marshal, unmarshal}
+}
+
+func (m *marshaler) MIMEType() string {
+	return m.mimeType
+}
+
+func (m *marshaler) Marshal(v any) ([]byte, error) {
+	if m.marshal == nil {
+		return nil, fmt.Errorf("marshaler has no encoder (MIME type: %s)", m.mimeType)
+	}
+	return m.marshal(v)
+}
+
+func (m *marshaler) Unmarshal(data []byte, v any) error {
+	if m.unmarshal == nil {
+		return fmt.Errorf("marshaler has no decoder (MIME type: %s)", m.mimeType)
+	}
+	return m.unmarshal(data, v)
+}
@@ -0,0 +1,610 @@
+package ekaweb_client
+
+import (
+	"encoding"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unsafe"
+)
+
+type (
+	// _QueryEncoder is a compiled URI query encoder of some type.
+	// It's built once per type and cached (see getQueryEncoder()).
+	//
+	// Struct fields are accessed by their offsets, so the reflection
+	// is used only for maps, interfaces and encoding.TextMarshaler implementors.
+	_QueryEncoder struct {
+		fields []_QueryField // if the type is a struct
+
+		mapValue *_QueryField // if the type is a map
+	}
+
+	// _QueryField is a compiled encoder of some struct field
+	// (or map value, then 'name' and 'offset' are not used).
+	_QueryField struct {
+		name      string // already escaped
+		offset    uintptr
+		omitempty bool
+		comma     bool
+
+		// Lists (slices, arrays) are encoded element by element.
+
+		kind     reflect.Kind
+		elemSize uintptr
+		arrayLen int
+
+		// Embedded structs (or pointers to them) are encoded as a part
+		// of the outer one.
+
+		embedded    *_QueryEncoder
+		embeddedPtr bool
+
+		appendValue _QueryAppender
+		isZero      func(p unsafe.Pointer) bool
+		nilable     bool // pointer or interface (of list element), isZero is nil check
+		typ         reflect.Type
+	}
+
+	// _QueryAppender appends an escaped value, located at 'p', to 'to'.
+	_QueryAppender func(to []byte, p unsafe.Pointer) ([]byte, error)
+
+	// _QueryCacheEntry is a value of queryEncoders.
+	_QueryCacheEntry struct {
+		enc *_QueryEncoder
+		err error
+	}
+
+	// _QueryAppenderKey is a key of queryAppenders.
+	_QueryAppenderKey struct {
+		typ    reflect.Type
+		layout string
+	}
+
+	// _QueryAppenderCacheEntry is a value of queryAppenders.
+	_QueryAppenderCacheEntry struct {
+		appendValue _QueryAppender
+		err         error
+	}
+
+	// _SliceHeader is a runtime representation of a slice.
+	_SliceHeader struct {
+		Data unsafe.Pointer
+		Len  int
+		Cap  int
+	}
+)
+
+const (
+	// QueryTagName is a name of the struct tag, that contains a name
+	// of the URI query parameter and its options: "omitempty", "comma".
+	// The "uri" and "form" tags are used as fallback.
+	QueryTagName = "query"
+
+	// QueryLayoutTagName is a name of the struct tag, that contains a layout
+	// of time.Time (see time.Format()) or "unix", "unixmilli".
+	// Default: time.RFC3339.
+	QueryLayoutTagName = "layout"
+)
+
+var (
+	queryEncoders  sync.Map // reflect.Type -> _QueryCacheEntry
+	queryAppenders sync.Map // _QueryAppenderKey -> _QueryAppenderCacheEntry
+
+	typeTime          = reflect.TypeOf(time.Time{})
+	typeTextMarshaler = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+)
+
+// getQueryEncoder returns a compiled URI query encoder of 't',
+// compiling and caching it, if it's not yet.
+func getQueryEncoder(t reflect.Type) (*_QueryEncoder, error) {
+
+	if entry, ok := queryEncoders.Load(t); ok {
+		return entry.(_QueryCacheEntry).enc, entry.(_QueryCacheEntry).err
+	}
+
+	var enc, err = compileQueryEncoder(t)
+	var entry, _ = queryEncoders.LoadOrStore(t, _QueryCacheEntry{enc, err})
+
+	return entry.(_QueryCacheEntry).enc, entry.(_QueryCacheEntry).err
+}
+
+// getQueryAppender returns a compiled appender of the value of type 't',
+// compiling and caching it, if it's not yet. It's used for the dynamic
+// types of interface values.
+func getQueryAppender(t reflect.Type, layout string) (_QueryAppender, error) {
+
+	var key = _QueryAppenderKey{t, layout}
+	if entry, ok := queryAppenders.Load(key); ok {
+		return entry.(_QueryAppenderCacheEntry).appendValue, entry.(_QueryAppenderCacheEntry).err
+	}
+
+	var appendValue, _, err = compileQueryValue(t, layout)
+	var entry, _ = queryAppenders.LoadOrStore(key, _QueryAppenderCacheEntry{appendValue, err})
+
+	return entry.(_QueryAppenderCacheEntry).appendValue, entry.(_QueryAppenderCacheEntry).err
+}
+
+// encode appends the URI query, encoded from 'v', to 'to'.
+// 'v' must be of the type, the encoder is compiled for.
+func (e *_QueryEncoder) encode(to []byte, v reflect.Value) ([]byte, error) {
+
+	if e.mapValue != nil {
+		return e.encodeMap(to, v)
+	}
+
+	// The struct is copied only if it's not addressable,
+	// i.e. passed by value, not by pointer.
+
+	if !v.CanAddr() {
+		var ptr = reflect.New(v.Type())
+		ptr.Elem().Set(v)
+		v = ptr.Elem()
+	}
+
+	return e.encodeStruct(to, v.Addr().UnsafePointer())
+}
+
+////////////////////////////////////////////////////////////////////////////////
+///// PRIVATE METHODS //////////////////////////////////////////////////////////
+////////////////////////////////////////////////////////////////////////////////
+
+func (e *_QueryEncoder) encodeStruct(to []byte, p unsafe.Pointer) ([]byte, error) {
+
+	var err error
+
+	for i, n := 0, len(e.fields); i < n && err == nil; i++ {
+		var f = &e.fields[i]
+		var fp = unsafe.Add(p, f.offset)
+
+		switch {
+		case f.embedded != nil && f.embeddedPtr:
+			if fp = *(*unsafe.Pointer)(fp); fp != nil {
+				to, err = f.embedded.encodeStruct(to, fp)
+			}
+
+		case f.embedded != nil:
+			to, err = f.embedded.encodeStruct(to, fp)
+
+		default:
+			to, err = f.encode(to, f.name, fp)
+		}
+	}
+
+	return to, err
+}
+
+// encodeMap encodes a map with string keys. The keys are sorted,
+// so the result is deterministic. Empty keys are skipped.
+func (e *_QueryEncoder) encodeMap(to []byte, v reflect.Value) ([]byte, error) {
+
+	var keys = v.MapKeys()
+	sort.Slice(keys, func(i, j int) bool {
+		return keys[i].String() < keys[j].String()
+	})
+
+	var value = reflect.New(v.Type().Elem()).Elem()
+	var valuePtr = value.Addr().UnsafePointer()
+
+	var err error
+
+	for i, n := 0, len(keys); i < n && err == nil; i++ {
+		if keys[i].Len() == 0 {
+			continue
+		}
+		value.Set(v.MapIndex(keys[i]))
+		var name = string(appendQueryEscape(nil, keys[i].String()))
+		to, err = e.mapValue.encode(to, name, valuePtr)
+	}
+
+	return to, err
+}
+
+// encode appends "name=value" pair(s) of the value located at 'p' to 'to'.
+func (f *_QueryField) encode(to []byte, name string, p unsafe.Pointer) ([]byte, error) {
+
+	switch f.kind {
+	case reflect.Slice:
+		var sh = (*_SliceHeader)(p)
+		return f.encodeList(to, name, sh.Data, sh.Len)
+
+	case reflect.Array:
+		return f.encodeList(to, name, p, f.arrayLen)
+	}
+
+	// Nil pointers and interfaces are always omitted.
+
+	if (f.omitempty || f.nilable) && f.isZero(p) {
+		return to, nil
+	}
+
+	to = appendQueryName(to, name)
+	return f.appendValue(to, p)
+}
+
+// encodeList encodes 'n' elements starting at 'p' either as repeated
+// "name=value" pairs or as a single comma separated one.
+// Empty lists and nil elements (pointers, interfaces) are omitted.
+func (f *_QueryField) encodeList(
+	to []byte, name string, p unsafe.Pointer, n int) ([]byte, error) {
+
+	var err error
+	var written = 0
+
+	for i := 0; i < n && err == nil; i++ {
+		var ep = unsafe.Add(p, uintptr(i)*f.elemSize)
+		if f.nilable && f.isZero(ep) {
+			continue
+		}
+		switch {
+		case written == 0 || !f.comma:
+			to = appendQueryName(to, name)
+		default:
+			to = append(to, ',')
+		}
+		to, err = f.appendValue(to, ep)
+		written++
+	}
+
+	return to, err
+}
+
+func compileQueryEncoder(t reflect.Type) (*_QueryEncoder, error) {
+
+	switch t.Kind() {
+	case reflect.Struct:
+		return compileQueryStruct(t, nil)
+
+	case reflect.Map:
+		if t.Key().Kind() != reflect.String {
+			const E = "unsupported map key type (%s); only string is allowed"
+			return nil, fmt.Errorf(E, t.Key().String())
+		}
+
+		var f, err = compileQueryField(t.Elem(), "")
+		if err != nil {
+			return nil, fmt.Errorf("unsupported map value: %w", err)
+		}
+		return &_QueryEncoder{mapValue: f}, nil
+
+	default:
+		return nil, fmt.Errorf("unsupported type (%s)", t.String())
+	}
+}
+
+// compileQueryStruct compiles the encoder of struct 't'. 'visiting' contains
+// embedded structs, that are being compiled, to prevent infinite recursion.
+func compileQueryStruct(t reflect.Type, visiting []reflect.Type) (*_QueryEncoder, error) {
+
+	for _, visited := range visiting {
+		if visited == t {
+			return nil, fmt.Errorf("recursive embedded struct (%s)", t.String())
+		}
+	}
+
+	visiting = append(visiting, t)
+
+	var e = _QueryEncoder{fields: make([]_QueryField, 0, t.NumField())}
+
+	for i, n := 0, t.NumField(); i < n; i++ {
+		var sf = t.Field(i)
+
+		var tag, ok = sf.Tag.Lookup(QueryTagName)
+		if !ok {
+			if tag, ok = sf.Tag.Lookup("uri"); !ok {
+				tag = sf.Tag.Get("form")
+			}
+		}
+
+		var name, opts, _ = strings.Cut(tag, ",")
+		if name == "-" && opts == "" {
+			continue
+		}
+
+		// Embedded structs w/o explicit name are inlined.
+		// Unexported embedded structs may have exported fields.
+
+		if embedded, isPtr := queryEmbeddedStruct(sf); embedded != nil && name == "" {
+			var inner, err = compileQueryStruct(embedded, visiting)
+			if err != nil {
+				return nil, err
+			}
+			e.fields = append(e.fields, _QueryField{
+				offset: sf.Offset, embedded: inner, embeddedPtr: isPtr})
+			continue
+		}
+
+		if !sf.IsExported() {
+			continue
+		}
+
+		if name == "" {
+			name = strings.ToLower(sf.Name)
+		}
+
+		var f, err = compileQueryField(sf.Type, sf.Tag.Get(QueryLayoutTagName))
+		if err != nil {
+			return nil, fmt.Errorf("unsupported field (name: %s): %w", sf.Name, err)
+		}
+
+		f.name = string(appendQueryEscape(nil, name))
+		f.offset = sf.Offset
+
+		for opts != "" {
+			var opt string
+			opt, opts, _ = strings.Cut(opts, ",")
+			switch opt {
+			case "omitempty":
+				f.omitempty = true
+			case "comma":
+				f.comma = true
+			}
+		}
+
+		e.fields = append(e.fields, *f)
+	}
+
+	return &e, nil
+}
+
+// compileQueryField compiles the encoder of the value of type 't',
+// that may be a list of values.
+func compileQueryField(t reflect.Type, layout string) (*_QueryField, error) {
+
+	var f = _QueryField{kind: reflect.Invalid, typ: t}
+	var elemType = t
+
+	if (t.Kind() == reflect.Slice || t.Kind() == reflect.Array) && !isQueryAtomType(t) {
+		f.kind, elemType = t.Kind(), t.Elem()
+		f.elemSize = elemType.Size()
+		if t.Kind() == reflect.Array {
+			f.arrayLen = t.Len()
+		}
+	}
+
+	var err error
+	if f.appendValue, f.isZero, err = compileQueryValue(elemType, layout); err != nil {
+		return nil, err
+	}
+
+	f.nilable = elemType != typeTime &&
+		(elemType.Kind() == reflect.Pointer || elemType.Kind() == reflect.Interface)
+
+	return &f, nil
+}
+
+// compileQueryValue returns an appender and zero checker of the value
+// of type 't'. Pointers are dereferenced.
+func compileQueryValue(
+	t reflect.Type, layout string) (_QueryAppender, func(p unsafe.Pointer) bool, error) {
+
+	switch {
+	case t == typeTime:
+		return compileQueryTime(layout), func(p unsafe.Pointer) bool {
+			return (*time.Time)(p).IsZero()
+		}, nil
+
+	case t.Kind() == reflect.Interface:
+		return compileQueryInterface(t, layout), func(p unsafe.Pointer) bool {
+			var v = reflect.NewAt(t, p).Elem()
+			return v.IsNil() || v.Elem().Kind() == reflect.Pointer && v.Elem().IsNil()
+		}, nil
+
+	case t.Kind() == reflect.Pointer:
+		var appendElem, _, err = compileQueryValue(t.Elem(), layout)
+		if err != nil {
+			return nil, nil, err
+		}
+		return func(to []byte, p unsafe.Pointer) ([]byte, error) {
+				if p = *(*unsafe.Pointer)(p); p == nil {
+					return to, nil
+				}
+				return appendElem(to, p)
+			}, func(p unsafe.Pointer) bool {
+				return *(*unsafe.Pointer)(p) == nil
+			}, nil
+
+	case t.Implements(typeTextMarshaler) || reflect.PointerTo(t).Implements(typeTextMarshaler):
+		return func(to []byte, p unsafe.Pointer) ([]byte, error) {
+				var m = reflect.NewAt(t, p).Interface().(encoding.TextMarshaler)
+				var text, err = m.MarshalText()
+				return appendQueryEscape(to, text), err
+			}, func(p unsafe.Pointer) bool {
+				return reflect.NewAt(t, p).Elem().IsZero()
+			}, nil
+	case t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.Uint8:
+		return func(to []byte, p unsafe.Pointer) ([]byte, error) {
+				return appendQueryEscape(to, *(*[]byte)(p)), nil
+			}, func(p unsafe.Pointer) bool {
+				return len(*(*[]byte)(p)) == 0
+			}, nil
+
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return func(to []byte, p unsafe.Pointer) ([]byte, error) {
+			return strconv.AppendBool(to, *(*bool)(p)), nil
+		}, isZeroOf[bool], nil
+
+	case reflect.Int:
+		return appendQueryInt[int], isZeroOf[int], nil
+	case reflect.Int8:
+		return appendQueryInt[int8], isZeroOf[int8], nil
+	case reflect.Int16:
+		return appendQueryInt[int16], isZeroOf[int16], nil
+	case reflect.Int32:
+		return appendQueryInt[int32], isZeroOf[int32], nil
+	case reflect.Int64:
+		return appendQueryInt[int64], isZeroOf[int64], nil
+
+	case reflect.Uint:
+		return appendQueryUint[uint], isZeroOf[uint], nil
+	case reflect.Uint8:
+		return appendQueryUint[uint8], isZeroOf[uint8], nil
+	case reflect.Uint16:
+		return appendQueryUint[uint16], isZeroOf[uint16], nil
+	case reflect.Uint32:
+		return appendQueryUint[uint32], isZeroOf[uint32], nil
+	case reflect.Uint64:
+		return appendQueryUint[uint64], isZeroOf[uint64], nil
+
+	case reflect.Float32:
+		return func(to []byte, p unsafe.Pointer) ([]byte, error) {
+			return appendQueryFloat(to, float64(*(*float32)(p)), 32), nil
+		}, isZeroOf[float32], nil
+
+	case reflect.Float64:
+		return func(to []byte, p unsafe.Pointer) ([]byte, error) {
+			return appendQueryFloat(to, *(*float64)(p), 64), nil
+		}, isZeroOf[float64], nil
+
+	case reflect.String:
+		return func(to []byte, p unsafe.Pointer) ([]byte, error) {
+			return appendQueryEscape(to, *(*string)(p)), nil
+		}, isZeroOf[string], nil
+
+	default:
+		return nil, nil, fmt.Errorf("unsupported type (%s)", t.String())
+	}
+}
+
+// compileQueryTime returns an appender of time.Time using 'layout'.
+func compileQueryTime(layout string) _QueryAppender {
+
+	switch layout {
+	case "unix":
+		return func(to []byte, p unsafe.Pointer) ([]byte, error) {
+			return strconv.AppendInt(to, (*time.Time)(p).Unix(), 10), nil
+		}
+
+	case "unixmilli":
+		return func(to []byte, p unsafe.Pointer) ([]byte, error) {
+			return strconv.AppendInt(to, (*time.Time)(p).UnixMilli(), 10), nil
+		}
+
+	case "":
+		layout = time.RFC3339
+	}
+
+	return func(to []byte, p unsafe.Pointer) ([]byte, error) {
+		var buf [64]byte
+		return appendQueryEscape(to, (*time.Time)(p).AppendFormat(buf[:0], layout)), nil
+	}
+}
+
+// compileQueryInterface returns an appender of the interface value
+// of type 't', that uses the cached appender of its dynamic type
+// (see getQueryAppender()). Nil value is appended as an empty one.
+func compileQueryInterface(t reflect.Type, layout string) _QueryAppender {
+	return func(to []byte, p unsafe.Pointer) ([]byte, error) {
+
+		var v = reflect.NewAt(t, p).Elem()
+		if v.IsNil() {
+			return to, nil
+		}
+
+		v = v.Elem()
+
+		var appendValue, err = getQueryAppender(v.Type(), layout)
+		if err != nil {
+			return to, err
+		}
+
+		// The dynamic value is not addressable.
+
+		var ptr = reflect.New(v.Type())
+		ptr.Elem().Set(v)
+
+		return appendValue(to, ptr.UnsafePointer())
+	}
+}
+
+// queryEmbeddedStruct returns the type of the embedded struct (or pointer
+// to struct) field, if it is, and whether it's a pointer.
+// Structs, that are encoded as values (e.g. time.Time), are not reported.
+func queryEmbeddedStruct(sf reflect.StructField) (reflect.Type, bool) {
+
+	if !sf.Anonymous {
+		return nil, false
+	}
+
+	var t, isPtr = sf.Type, false
+	if t.Kind() == reflect.Pointer {
+		t, isPtr = t.Elem(), true
+	}
+
+	switch {
+	case t.Kind() != reflect.Struct || t == typeTime:
+		return nil, false
+
+	case t.Implements(typeTextMarshaler) || reflect.PointerTo(t).Implements(typeTextMarshaler):
+		return nil, false
+
+	default:
+		return t, isPtr
+	}
+}
+
+// isQueryAtomType reports whether list type 't' is encoded as a single value.
+func isQueryAtomType(t reflect.Type) bool {
+	return t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.Uint8 ||
+		t.Implements(typeTextMarshaler) ||
+		reflect.PointerTo(t).Implements(typeTextMarshaler)
+}
+
+func isZeroOf[T comparable](p unsafe.Pointer) bool {
+	var zero T
+	return *(*T)(p) == zero
+}
+
+func appendQueryInt[T int | int8 | int16 | int32 | int64](
+	to []byte, p unsafe.Pointer) ([]byte, error) {
+
+	return strconv.AppendInt(to, int64(*(*T)(p)), 10), nil
+}
+
+func appendQueryUint[T uint | uint8 | uint16 | uint32 | uint64](
+	to []byte, p unsafe.Pointer) ([]byte, error) {
+
+	return strconv.AppendUint(to, uint64(*(*T)(p)), 10), nil
+}
+
+func appendQueryFloat(to []byte, f float64, bitSize int) []byte {
+	var buf [32]byte
+	return appendQueryEscape(to, strconv.AppendFloat(buf[:0], f, 'g', -1, bitSize))
+}
+
+// appendQueryName appends "&name=" (or "name=" if it's the first one) to 'to'.
+func appendQueryName(to []byte, name string) []byte {
+	if len(to) != 0 {
+		to = append(to, '&')
+	}
+	to = append(to, name...)
+	return append(to, '=')
+}
+
+// appendQueryEscape is the same as url.QueryEscape(),
+// but appends the result to 'to' w/o allocations.
+func appendQueryEscape[S string | []byte](to []byte, s S) []byte {
+
+	const Hex = "0123456789ABCDEF"
+
+	for i, n := 0, len(s); i < n; i++ {
+		switch c := s[i]; {
+		case 'a' <= c && c <= 'z', 'A' <= c && c <= 'Z', '0' <= c && c <= '9',
+			c == '-', c == '_', c == '.', c == '~':
+			to = append(to, c)
+		case c == ' ':
+			to = append(to, '+')
+		default:
+			to = append(to, '%', Hex[c>>4], Hex[c&15])
+		}
+	}
+
+	return to
+}
@@ -0,0 +1,63 @@
+package ekaweb_client
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/inaneverb/ekaweb/v2"
+)
+
+type (
+	requestCodec struct {
+		codec  ekaweb.Codec
+		source any
+	}
+
+	requestMarshal struct {
+		marshaler Marshaler
+		source    any
+	}
+)
+
+// RequestCodec returns ekaweb.ClientRequest, that encodes 'source'
+// using the encoder of 'codec' (see ekaweb.NewCodec()).
+// The MIME type of the codec is used as Content-Type.
+//
+// This way the same codecs (e.g. msgpack) may be used both by the server
+// (see ekaweb.WithCodecs()) and by the client.
+func RequestCodec(codec ekaweb.Codec, source any) ekaweb.ClientRequest {
+	return &requestCodec{codec, source}
+}
+
+// RequestMarshal returns ekaweb.ClientRequest, that encodes 'source'
+// using 'marshaler' (see NewMarshaler()). The MIME type of the marshaler
+// is used as Content-Type.
+func RequestMarshal(marshaler Marshaler, source any) ekaweb.ClientRequest {
+	return &requestMarshal{marshaler, source}
+}
+
+func (w *requestCodec) Data() ([]byte, error) {
+
+	if w.codec.EncoderGetter == nil {
+		return nil, fmt.Errorf("codec has no encoder (MIME type: %s)", w.codec.MIMEType)
+	}
+
+	var buf bytes.Buffer
+	if err := w.codec.EncoderGetter(&buf).Encode(w.source); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+func (w *requestCodec) ContentType() string {
+	return w.codec.MIMEType
+}
+
+func (w *requestMarshal) Data() ([]byte, error) {
+	return w.marshaler.Marshal(w.source)
+}
+
+func (w *requestMarshal) ContentType() string {
+	return w.marshaler.MIMEType()
+}
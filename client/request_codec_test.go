@@ -0,0 +1,142 @@
+package ekaweb_client_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"io"
+	"mime"
+	"mime/multipart"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/inaneverb/ekaweb/client/v2"
+	"github.com/inaneverb/ekaweb/v2"
+)
+
+type Item struct {
+	XMLName xml.Name `xml:"item" json:"-" query:"-"`
+	ID      int      `xml:"id" json:"id" query:"id"`
+	Name    string   `xml:"name" json:"name" query:"name"`
+}
+
+func TestRequestForm(t *testing.T) {
+
+	var req = ekaweb_client.RequestForm(&Item{ID: 1, Name: "a b"})
+
+	var data, err = req.Data()
+	require.NoError(t, err)
+	require.Equal(t, "id=1&name=a+b", string(data))
+	require.Equal(t, ekaweb.MIMEApplicationForm, req.ContentType())
+}
+
+func TestRequestXML_ResponseXML(t *testing.T) {
+
+	var req = ekaweb_client.RequestXML(&Item{ID: 1, Name: "a"})
+
+	var data, err = req.Data()
+	require.NoError(t, err)
+	require.Equal(t, "<item><id>1</id><name>a</name></item>", string(data))
+	require.Equal(t, ekaweb.MIMEApplicationXML, req.ContentType())
+
+	var dest Item
+	require.NoError(t, ekaweb_client.ResponseXML(&dest).FromData(200, data))
+	require.Equal(t, "a", dest.Name)
+}
+
+func TestRequestRaw_ResponseRaw(t *testing.T) {
+
+	var req = ekaweb_client.RequestRaw([]byte{1, 2}, "")
+	require.Equal(t, ekaweb.MIMEOctetStream, req.ContentType())
+
+	req = ekaweb_client.RequestRaw([]byte("hi"), ekaweb.MIMETextPlain)
+	require.Equal(t, ekaweb.MIMETextPlain, req.ContentType())
+
+	var buf = []byte("reused")
+	var dest []byte
+
+	require.NoError(t, ekaweb_client.ResponseRaw(&dest).FromData(200, buf))
+	copy(buf, "xxxxxx")
+	require.Equal(t, "reused", string(dest))
+}
+
+func TestRequestCodec_ResponseCodec(t *testing.T) {
+
+	var codec = ekaweb.NewCodec("application/x-json", json.NewEncoder, json.NewDecoder)
+	var req = ekaweb_client.RequestCodec(codec, &Item{ID: 2})
+
+	var data, err = req.Data()
+	require.NoError(t, err)
+	require.Equal(t, "application/x-json", req.ContentType())
+
+	var dest Item
+	require.NoError(t, ekaweb_client.ResponseCodec(codec, &dest).FromData(200, data))
+	require.Equal(t, 2, dest.ID)
+
+	var encOnly = ekaweb.Codec{MIMEType: "application/x-none"}
+	_, err = ekaweb_client.RequestCodec(encOnly, &dest).Data()
+	require.ErrorContains(t, err, "codec has no encoder")
+}
+
+func TestRequestMarshal_ResponseUnmarshal(t *testing.T) {
+
+	var m = ekaweb_client.NewMarshaler("application/x-json", json.Marshal, json.Unmarshal)
+	var req = ekaweb_client.RequestMarshal(m, &Item{ID: 3})
+
+	var data, err = req.Data()
+	require.NoError(t, err)
+	require.Equal(t, "application/x-json", req.ContentType())
+
+	var dest Item
+	require.NoError(t, ekaweb_client.ResponseUnmarshal(m, &dest).FromData(200, data))
+	require.Equal(t, 3, dest.ID)
+
+	m = ekaweb_client.NewMarshaler("application/x-none", nil, nil)
+	require.ErrorContains(t,
+		ekaweb_client.ResponseUnmarshal(m, &dest).FromData(200, data),
+		"marshaler has no decoder")
+}
+
+func TestRequestMultipart(t *testing.T) {
+
+	var req = ekaweb_client.RequestMultipart(
+		ekaweb_client.MultipartField("title", "report"),
+		ekaweb_client.MultipartFile("file", `a "b".csv`, strings.NewReader("1,2")),
+		ekaweb_client.MultipartPart{
+			Name: "meta", ContentType: ekaweb.MIMEApplicationJSON,
+			Body: strings.NewReader(`{}`),
+		},
+	)
+
+	var mediaType, params, err = mime.ParseMediaType(req.ContentType())
+	require.NoError(t, err)
+	require.Equal(t, ekaweb.MIMEMultipartForm, mediaType)
+
+	data, err := req.Data()
+	require.NoError(t, err)
+
+	// The data is encoded once, since the readers are consumed.
+
+	data2, err := req.Data()
+	require.NoError(t, err)
+	require.Equal(t, data, data2)
+
+	var form, errRead = multipart.NewReader(bytes.NewReader(data), params["boundary"]).
+		ReadForm(1 << 20)
+	require.NoError(t, errRead)
+
+	require.Equal(t, []string{"report"}, form.Value["title"])
+	require.Equal(t, []string{"{}"}, form.Value["meta"])
+	require.Len(t, form.File["file"], 1)
+
+	var fh = form.File["file"][0]
+	require.Equal(t, `a "b".csv`, fh.Filename)
+	require.Equal(t, ekaweb.MIMEOctetStream, fh.Header.Get(ekaweb.HeaderContentType))
+
+	var f, errOpen = fh.Open()
+	require.NoError(t, errOpen)
+	var content, _ = io.ReadAll(f)
+	require.Equal(t, "1,2", string(content))
+}
@@ -0,0 +1,20 @@
+package ekaweb_client
+
+import (
+	"github.com/inaneverb/ekaweb/v2"
+)
+
+type requestForm struct {
+	requestQuery
+}
+
+// RequestForm returns ekaweb.ClientRequest, that encodes 'source'
+// as "application/x-www-form-urlencoded" HTTP request body.
+// The encoding rules are the same as RequestQuery() has.
+func RequestForm(source any) ekaweb.ClientRequest {
+	return &requestForm{requestQuery{source}}
+}
+
+func (w *requestForm) ContentType() string {
+	return ekaweb.MIMEApplicationForm
+}
@@ -0,0 +1,125 @@
+package ekaweb_client
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/textproto"
+	"strings"
+
+	"github.com/inaneverb/ekaweb/v2"
+)
+
+type (
+	// MultipartPart is a part of "multipart/form-data" HTTP request body.
+	// See MultipartField() and MultipartFile().
+	MultipartPart struct {
+		Name        string
+		FileName    string // the part is a file, if it's not empty
+		ContentType string // default: "application/octet-stream" for files
+		Body        io.Reader
+	}
+
+	requestMultipart struct {
+		parts    []MultipartPart
+		boundary string
+		data     []byte
+		err      error
+		encoded  bool
+	}
+)
+
+// quoteEscaper is the same as mime/multipart uses.
+var quoteEscaper = strings.NewReplacer("\\", "\\\\", `"`, "\\\"")
+
+// RequestMultipart returns ekaweb.ClientRequest, that encodes 'parts'
+// as "multipart/form-data" HTTP request body.
+//
+// The bodies of parts are read only once: the encoded data is kept
+// and reused, if the request is sent again (e.g. retried).
+func RequestMultipart(parts ...MultipartPart) ekaweb.ClientRequest {
+	var boundary = multipart.NewWriter(io.Discard).Boundary()
+	return &requestMultipart{parts: parts, boundary: boundary}
+}
+
+// MultipartField returns MultipartPart, that is a regular form field.
+func MultipartField(name, value string) MultipartPart {
+	return MultipartPart{Name: name, Body: strings.NewReader(value)}
+}
+
+// MultipartFile returns MultipartPart, that is a file
+// with "application/octet-stream" content type.
+func MultipartFile(name, fileName string, body io.Reader) MultipartPart {
+	return MultipartPart{Name: name, FileName: fileName, Body: body}
+}
+
+func (w *requestMultipart) Data() ([]byte, error) {
+	if !w.encoded {
+		w.data, w.err = w.encode()
+		w.encoded = true
+	}
+	return w.data, w.err
+}
+
+func (w *requestMultipart) ContentType() string {
+	return ekaweb.MIMEMultipartForm + "; boundary=" + w.boundary
+}
+
+////////////////////////////////////////////////////////////////////////////////
+///// PRIVATE METHODS //////////////////////////////////////////////////////////
+////////////////////////////////////////////////////////////////////////////////
+
+func (w *requestMultipart) encode() ([]byte, error) {
+
+	var buf bytes.Buffer
+	var mw = multipart.NewWriter(&buf)
+
+	if err := mw.SetBoundary(w.boundary); err != nil {
+		return nil, err
+	}
+
+	for i, n := 0, len(w.parts); i < n; i++ {
+		var pw, err = mw.CreatePart(w.parts[i].header())
+		if err == nil && w.parts[i].Body != nil {
+			_, err = io.Copy(pw, w.parts[i].Body)
+		}
+		if err != nil {
+			const E = "failed to write multipart part (name: %s): %w"
+			return nil, fmt.Errorf(E, w.parts[i].Name, err)
+		}
+	}
+
+	if err := mw.Close(); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// header returns MIME header of the part.
+func (p *MultipartPart) header() textproto.MIMEHeader {
+
+	var disposition = `form-data; name="` + escapeQuotes(p.Name) + `"`
+	var contentType = p.ContentType
+
+	if p.FileName != "" {
+		disposition += `; filename="` + escapeQuotes(p.FileName) + `"`
+		if contentType == "" {
+			contentType = ekaweb.MIMEOctetStream
+		}
+	}
+
+	var h = make(textproto.MIMEHeader, 2)
+	h.Set(ekaweb.HeaderContentDisposition, disposition)
+
+	if contentType != "" {
+		h.Set(ekaweb.HeaderContentType, contentType)
+	}
+
+	return h
+}
+
+func escapeQuotes(s string) string {
+	return quoteEscaper.Replace(s)
+}
@@ -2,20 +2,32 @@ package ekaweb_client
 
 import (
 	"fmt"
-	"net/url"
 	"reflect"
-	"strings"
 
 	"github.com/inaneverb/ekaweb/v2/private"
 )
 
-// TODO: This is not optimized version of URI query encoding.
-//  You should reject usage of Golang reflect package and rewrite all this code.
-
 type requestQuery struct {
 	source any
 }
 
+// RequestQuery returns ekaweb.ClientRequest, that encodes 'source'
+// as URI query. The 'source' is a struct, a pointer to struct
+// or a map with string keys.
+//
+// The struct fields are encoded by the name from the QueryTagName tag
+// ("uri" and "form" tags are used as fallback) or by the lowercased field name.
+// Supported tag options:
+//   - "omitempty": zero values are omitted;
+//   - "comma": lists are encoded as "k=v1,v2" instead of "k=v1&k=v2".
+//
+// Supported types are: bool, integers, floats, strings, []byte,
+// time.Time (see QueryLayoutTagName), encoding.TextMarshaler implementors,
+// pointers to them (nil is omitted) and slices, arrays of them.
+// Embedded structs w/o explicit name are inlined.
+//
+// The encoder of each type is compiled once and cached.
+// Prefer passing a pointer to struct to avoid its copying.
 func RequestQuery(source any) ekaweb_private.ClientRequest {
 	return &requestQuery{source}
 }
@@ -35,124 +47,23 @@ func (w *requestQuery) Data() ([]byte, error) {
 		v = v.Elem()
 	}
 
-	switch {
-	case v.Kind() == reflect.Struct:
-		return w.encodeStruct(v)
-
-	case v.Kind() == reflect.Map && v.Type().Key().Kind() != reflect.String:
-		const E = "unsupported map key type (%s); only string is allowed"
-		return nil, fmt.Errorf(E, v.Type().Key().String())
-
-	case v.Kind() == reflect.Map:
-		return w.encodeMap(v)
-
-	default:
-		return nil, fmt.Errorf("unsupported type (%s)", v.Type().String())
-	}
-}
-
-func (w *requestQuery) ContentType() string {
-	return ""
-}
-
-////////////////////////////////////////////////////////////////////////////////
-
-func (w *requestQuery) encodeStruct(v reflect.Value) ([]byte, error) {
-
-	const TagName1 = "uri"
-	const TagName2 = "form"
-
-	var buf = make([]byte, 0, 64)
-
-	var vf = reflect.VisibleFields(v.Type())
-	for i, n := 0, len(vf); i < n; i++ {
-
-		switch {
-		case !vf[i].IsExported():
-			continue
-
-		case !w.isAtom(vf[i].Type.Kind()):
-			const E = "non atomic nested field (offset: %d, name: %s)"
-			return nil, fmt.Errorf(E, i, vf[i].Name)
-		}
-
-		var name string
-		if name = vf[i].Tag.Get(TagName1); name == "" {
-			name = vf[i].Tag.Get(TagName2)
-		}
-
-		var omitempty bool
-
-		if omitempty = strings.HasSuffix(name, ",omitempty"); omitempty {
-			name = name[:len(name)-10]
-		}
-
-		switch {
-		case name == "" && vf[i].Anonymous:
-			continue
-		case name == "":
-			name = strings.ToLower(vf[i].Name)
-		}
-
-		buf = w.appendAtom(buf, name, v.Field(i), omitempty)
-	}
-
-	return w.cleanUpBuffer(buf)
-}
-
-func (w *requestQuery) encodeMap(v reflect.Value) ([]byte, error) {
-
-	var buf = make([]byte, 0, 64)
-
-	for iter := v.MapRange(); iter.Next(); {
-		var name = iter.Key().String()
-		var v = iter.Value()
-
-		switch {
-		case name == "":
-			continue
-
-		case w.isAtom(v.Kind()):
-			return nil, fmt.Errorf("non atomic map value (name: %s)", name)
-		}
-
-		buf = w.appendAtom(buf, name, v, false)
+	var enc, err = getQueryEncoder(v.Type())
+	if err != nil {
+		return nil, err
 	}
 
-	return w.cleanUpBuffer(buf)
-}
-
-func (w *requestQuery) appendAtom(
-	to []byte, name string, v reflect.Value, omitempty bool) []byte {
-
-	if v.IsZero() && omitempty {
-		return to
+	var buf []byte
+	if buf, err = enc.encode(make([]byte, 0, 64), v); err != nil {
+		return nil, err
 	}
 
-	to = append(to, name...)
-	to = append(to, '=')
-	to = append(to, url.QueryEscape(fmt.Sprintf("%v", v.Interface()))...)
-	to = append(to, '&')
-
-	return to
-}
-
-func (w *requestQuery) cleanUpBuffer(buf []byte) ([]byte, error) {
-	if buf[len(buf)-1] == '&' {
-		buf = buf[:len(buf)-1]
-	}
 	if len(buf) == 0 {
 		buf = nil
 	}
+
 	return buf, nil
 }
 
-func (_ *requestQuery) isAtom(k reflect.Kind) bool {
-	return k == reflect.Bool || k == reflect.Int || k == reflect.Uint ||
-		k == reflect.Int8 || k == reflect.Int16 ||
-		k == reflect.Int32 || k == reflect.Int64 ||
-		k == reflect.Uint8 || k == reflect.Uint16 ||
-		k == reflect.Uint32 || k == reflect.Uint64 ||
-		k == reflect.Float32 || k == reflect.Float64 ||
-		k == reflect.String
+func (w *requestQuery) ContentType() string {
+	return ""
 }
@@ -2,7 +2,12 @@ package ekaweb_client_test
 
 import (
 	"fmt"
+	"net"
+	"net/url"
+	"reflect"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 
@@ -15,9 +20,40 @@ type T struct {
 	Empty           string `form:"empty,omitempty"`
 	NameFromGoEmpty string `form:",omitempty"`
 	Age             int
-	unexported      bool `form:"should_not_be_added" json:"should_not_be_added"`
+	unexported      bool `form:"should_not_be_added"`
 }
 
+type (
+	Paging struct {
+		Page  int `query:"page,omitempty"`
+		Limit int `query:"limit,omitempty"`
+	}
+
+	Filter struct {
+		Sort string `query:"sort"`
+	}
+
+	Search struct {
+		Paging
+		*Filter
+
+		Query   string      `query:"q"`
+		Tags    []string    `query:"tag"`
+		IDs     []int64     `query:"ids,comma"`
+		Since   time.Time   `query:"since" layout:"2006-01-02"`
+		Until   time.Time   `query:"until,omitempty" layout:"unix"`
+		Created time.Time   `query:"created,omitempty"`
+		Addr    net.IP      `query:"addr,omitempty"`
+		Score   *float64    `query:"score"`
+		Exact   bool        `query:"exact,omitempty"`
+		Skip    string      `query:"-"`
+		Window  [2]uint8    `query:"w"`
+		Raw     []byte      `query:"raw,omitempty"`
+		Ptrs    []*string   `query:"p,omitempty"`
+		Times   []time.Time `query:"t,omitempty"`
+	}
+)
+
 func emptyT() *T {
 	return new(T)
 }
@@ -31,6 +67,23 @@ func filledT() *T {
 	}
 }
 
+func filledSearch() *Search {
+	var score = 0.5
+	return &Search{
+		Paging: Paging{Page: 2},
+		Filter: &Filter{Sort: "-date"},
+		Query:  "a&b c",
+		Tags:   []string{"x", "y/z"},
+		IDs:    []int64{1, 2, 3},
+		Since:  time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+		Until:  time.Unix(1700000000, 0),
+		Addr:   net.IPv4(127, 0, 0, 1),
+		Score:  &score,
+		Skip:   "skipped",
+		Window: [2]uint8{1, 2},
+	}
+}
+
 func TestRequestQuery(t *testing.T) {
 
 	var q = filledT()
@@ -39,7 +92,123 @@ func TestRequestQuery(t *testing.T) {
 	var data, err = wq.Data()
 	require.NoError(t, err)
 
-	fmt.Printf("%s\n", data)
+	const Expected = "not_a_name=John+Doe&position=Demigod" +
+		"&namefromgoempty=Lorem+Ipsum&age=322"
+
+	require.Equal(t, Expected, string(data))
+
+	// Passing by value gives the same result.
+
+	data, err = ekaweb_client.RequestQuery(*q).Data()
+	require.NoError(t, err)
+	require.Equal(t, Expected, string(data))
+
+	data, err = ekaweb_client.RequestQuery(emptyT()).Data()
+	require.NoError(t, err)
+	require.Equal(t, "not_a_name=&position=&age=0", string(data))
+}
+
+func TestRequestQuery_Search(t *testing.T) {
+
+	var data, err = ekaweb_client.RequestQuery(filledSearch()).Data()
+	require.NoError(t, err)
+
+	const Expected = "page=2&sort=-date&q=a%26b+c&tag=x&tag=y%2Fz&ids=1,2,3" +
+		"&since=2024-01-02&until=1700000000&addr=127.0.0.1&score=0.5" +
+		"&w=1&w=2"
+
+	require.Equal(t, Expected, string(data))
+
+	// The comma form is still a valid URI query.
+
+	var values, errParse = url.ParseQuery(string(data))
+	require.NoError(t, errParse)
+	require.Equal(t, []string{"x", "y/z"}, values["tag"])
+	require.Equal(t, "a&b c", values.Get("q"))
+
+	// Nil list elements are omitted.
+
+	var a, b = "a", "b"
+
+	data, err = ekaweb_client.RequestQuery(&struct {
+		Ptrs  []*string `query:"p"`
+		Comma []*string `query:"c,comma"`
+		Any   []any     `query:"any"`
+	}{
+		Ptrs:  []*string{nil, &a, nil, &b},
+		Comma: []*string{nil, &a, &b},
+		Any:   []any{1, nil, "x"},
+	}).Data()
+
+	require.NoError(t, err)
+	require.Equal(t, "p=a&p=b&c=a,b&any=1&any=x", string(data))
+
+	// Nil embedded pointer and nil pointer fields are omitted.
+
+	data, err = ekaweb_client.RequestQuery(&Search{}).Data()
+	require.NoError(t, err)
+	require.Equal(t, "q=&since=0001-01-01&w=0&w=0", string(data))
+}
+
+func TestRequestQuery_Map(t *testing.T) {
+
+	// The values of interface type are encoded by their dynamic types.
+	// Nil ones are omitted.
+
+	var since = time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	var data, err = ekaweb_client.RequestQuery(map[string]any{
+		"s": "a b", "i": 42, "f": 0.5, "b": true, "t": since,
+		"ip": net.IPv4(127, 0, 0, 1), "p": &since, "nil": nil, "nilp": (*int)(nil),
+	}).Data()
+
+	require.NoError(t, err)
+	require.Equal(t, "b=true&f=0.5&i=42&ip=127.0.0.1"+
+		"&p=2024-01-02T03%3A04%3A05Z&s=a+b&t=2024-01-02T03%3A04%3A05Z", string(data))
+
+	_, err = ekaweb_client.RequestQuery(map[string]any{"x": struct{}{}}).Data()
+	require.ErrorContains(t, err, "unsupported type (struct {})")
+
+	data, err = ekaweb_client.RequestQuery(map[string]any{}).Data()
+	require.NoError(t, err)
+	require.Empty(t, data)
+
+	data, err = ekaweb_client.RequestQuery(url.Values{
+		"b": {"2", "3"}, "a": {"1"}, "": {"skipped"}, "c d": {"&"},
+	}).Data()
+
+	require.NoError(t, err)
+	require.Equal(t, "a=1&b=2&b=3&c+d=%26", string(data))
+
+	data, err = ekaweb_client.RequestQuery(map[string]int{"x": 1}).Data()
+	require.NoError(t, err)
+	require.Equal(t, "x=1", string(data))
+
+	_, err = ekaweb_client.RequestQuery(map[int]string{}).Data()
+	require.ErrorContains(t, err, "unsupported map key type")
+}
+
+func TestRequestQuery_Unsupported(t *testing.T) {
+
+	type Nested struct {
+		Inner struct{ A int }
+	}
+
+	type Recursive struct {
+		*Recursive
+	}
+
+	var _, err = ekaweb_client.RequestQuery(&Nested{}).Data()
+	require.ErrorContains(t, err, "unsupported field (name: Inner)")
+
+	_, err = ekaweb_client.RequestQuery(&Recursive{}).Data()
+	require.ErrorContains(t, err, "recursive embedded struct")
+
+	_, err = ekaweb_client.RequestQuery(42).Data()
+	require.ErrorContains(t, err, "unsupported type")
+
+	_, err = ekaweb_client.RequestQuery((*T)(nil)).Data()
+	require.ErrorContains(t, err, "nil source")
 }
 
 func BenchmarkRequestQuery(b *testing.B) {
@@ -52,3 +221,70 @@ func BenchmarkRequestQuery(b *testing.B) {
 		_, _ = wq.Data()
 	}
 }
+
+// BenchmarkRequestQuery_Reflect is a baseline for BenchmarkRequestQuery:
+// the struct is walked using reflection for each encoding.
+func BenchmarkRequestQuery_Reflect(b *testing.B) {
+	b.ReportAllocs()
+
+	var q = filledT()
+
+	for i := 0; i < b.N; i++ {
+		_ = reflectQuery(reflect.ValueOf(q).Elem())
+	}
+}
+
+func BenchmarkRequestQuery_Search(b *testing.B) {
+	b.ReportAllocs()
+
+	var q = filledSearch()
+	var wq = ekaweb_client.RequestQuery(q)
+
+	for i := 0; i < b.N; i++ {
+		_, _ = wq.Data()
+	}
+}
+
+func BenchmarkRequestQuery_Map(b *testing.B) {
+	b.ReportAllocs()
+
+	var q = url.Values{"a": {"1"}, "b": {"2", "3"}, "c": {"x y"}}
+	var wq = ekaweb_client.RequestQuery(q)
+
+	for i := 0; i < b.N; i++ {
+		_, _ = wq.Data()
+	}
+}
+
+// reflectQuery encodes atomic fields of struct 'v' the same way
+// RequestQuery() does, but using reflection for each field.
+func reflectQuery(v reflect.Value) []byte {
+
+	var buf = make([]byte, 0, 64)
+
+	var vf = reflect.VisibleFields(v.Type())
+	for i, n := 0, len(vf); i < n; i++ {
+		if !vf[i].IsExported() {
+			continue
+		}
+
+		var name, opts, _ = strings.Cut(vf[i].Tag.Get("form"), ",")
+		if name == "" {
+			name = strings.ToLower(vf[i].Name)
+		}
+
+		var fv = v.Field(i)
+		if fv.IsZero() && opts == "omitempty" {
+			continue
+		}
+
+		if len(buf) != 0 {
+			buf = append(buf, '&')
+		}
+		buf = append(buf, name...)
+		buf = append(buf, '=')
+		buf = append(buf, url.QueryEscape(fmt.Sprintf("%v", fv.Interface()))...)
+	}
+
+	return buf
+}
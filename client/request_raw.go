@@ -0,0 +1,27 @@
+package ekaweb_client
+
+import (
+	"github.com/inaneverb/ekaweb/v2"
+)
+
+type requestRaw struct {
+	data        []byte
+	contentType string
+}
+
+// RequestRaw returns ekaweb.ClientRequest, that sends 'data' as is.
+// If 'contentType' is empty, "application/octet-stream" is used.
+func RequestRaw(data []byte, contentType string) ekaweb.ClientRequest {
+	if contentType == "" {
+		contentType = ekaweb.MIMEOctetStream
+	}
+	return &requestRaw{data, contentType}
+}
+
+func (w *requestRaw) Data() ([]byte, error) {
+	return w.data, nil
+}
+
+func (w *requestRaw) ContentType() string {
+	return w.contentType
+}
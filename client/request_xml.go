@@ -0,0 +1,24 @@
+package ekaweb_client
+
+import (
+	"encoding/xml"
+
+	"github.com/inaneverb/ekaweb/v2"
+)
+
+type requestXML struct {
+	source any
+}
+
+// RequestXML returns ekaweb.ClientRequest, that encodes 'source' as XML.
+func RequestXML(source any) ekaweb.ClientRequest {
+	return &requestXML{source}
+}
+
+func (w *requestXML) Data() ([]byte, error) {
+	return xml.Marshal(w.source)
+}
+
+func (w *requestXML) ContentType() string {
+	return ekaweb.MIMEApplicationXML
+}
@@ -0,0 +1,47 @@
+package ekaweb_client
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/inaneverb/ekaweb/v2"
+)
+
+type (
+	responseCodec struct {
+		codec ekaweb.Codec
+		dest  any
+	}
+
+	responseUnmarshal struct {
+		marshaler Marshaler
+		dest      any
+	}
+)
+
+// ResponseCodec returns ekaweb.ClientResponse, that decodes HTTP response body
+// into 'dest' using the decoder of 'codec' (see ekaweb.NewCodec())
+// regardless of HTTP status code.
+func ResponseCodec(codec ekaweb.Codec, dest any) ekaweb.ClientResponse {
+	return &responseCodec{codec, dest}
+}
+
+// ResponseUnmarshal returns ekaweb.ClientResponse, that decodes HTTP response
+// body into 'dest' using 'marshaler' (see NewMarshaler())
+// regardless of HTTP status code.
+func ResponseUnmarshal(marshaler Marshaler, dest any) ekaweb.ClientResponse {
+	return &responseUnmarshal{marshaler, dest}
+}
+
+func (r *responseCodec) FromData(_ int, data []byte) error {
+
+	if r.codec.DecoderGetter == nil {
+		return fmt.Errorf("codec has no decoder (MIME type: %s)", r.codec.MIMEType)
+	}
+
+	return r.codec.DecoderGetter(bytes.NewReader(data)).Decode(r.dest)
+}
+
+func (r *responseUnmarshal) FromData(_ int, data []byte) error {
+	return r.marshaler.Unmarshal(data, r.dest)
+}
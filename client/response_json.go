@@ -7,13 +7,20 @@ import (
 )
 
 type responseJSON struct {
-	dest any
+	dest      any
+	skipEmpty bool
 }
 
+// ResponseJSON returns ekaweb.ClientResponse, that decodes JSON into 'dest'
+// regardless of HTTP status code. Use ResponseJSONOrError()
+// or ResponseJSONWithError() to decode only successful HTTP responses.
 func ResponseJSON(dest any) ekaweb_private.ClientResponse {
-	return &responseJSON{dest}
+	return &responseJSON{dest, false}
 }
 
 func (r *responseJSON) FromData(_ int, data []byte) error {
+	if r.skipEmpty && len(data) == 0 {
+		return nil
+	}
 	return json.Unmarshal(data, r.dest)
 }
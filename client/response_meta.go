@@ -0,0 +1,194 @@
+package ekaweb_client
+
+import (
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/inaneverb/ekaweb/v2"
+)
+
+type (
+	// ResponseMeta is a metadata of the HTTP response: HTTP status code,
+	// headers and trailers. It's filled by ResponseWithMeta()
+	// and ResponseJSONWithMeta() decoders.
+	ResponseMeta struct {
+		StatusCode int
+		Header     http.Header
+		Trailer    http.Header
+	}
+
+	// RateLimit is a rate limit state of the upstream,
+	// reported by HTTP headers of its response. See ResponseMeta.RateLimit().
+	RateLimit struct {
+		Limit     int
+		Remaining int
+		Reset     time.Duration
+	}
+
+	responseMeta struct {
+		orig ekaweb.ClientResponse
+		meta *ResponseMeta
+	}
+
+	responseMetaStream struct {
+		*responseMeta
+	}
+)
+
+// ResponseWithMeta returns ekaweb.ClientResponse, that fills 'meta'
+// and then passes the data to 'resp'. If 'resp' is nil, the body is ignored
+// regardless of HTTP status code. If 'resp' is ekaweb.ClientResponseStream,
+// so is the returned one.
+//
+// The client must support ekaweb.ClientResponseHeaders to fill
+// headers and trailers. Otherwise, only HTTP status code is filled.
+func ResponseWithMeta(
+	resp ekaweb.ClientResponse, meta *ResponseMeta) ekaweb.ClientResponse {
+
+	var r = &responseMeta{resp, meta}
+	if _, ok := resp.(ekaweb.ClientResponseStream); ok {
+		return responseMetaStream{r}
+	}
+	return r
+}
+
+// ResponseJSONWithMeta is the same as ResponseJSON(), but also fills 'meta'.
+// Unlike ResponseJSON(), the empty body (e.g. 204 or 304) is not decoded.
+func ResponseJSONWithMeta(dest any, meta *ResponseMeta) ekaweb.ClientResponse {
+	return ResponseWithMeta(&responseJSON{dest, true}, meta)
+}
+
+// IsOK reports whether HTTP status code is 2xx.
+func (m *ResponseMeta) IsOK() bool {
+	return m.StatusCode >= 200 && m.StatusCode <= 299
+}
+
+// ETag returns a value of the "ETag" HTTP header.
+func (m *ResponseMeta) ETag() string {
+	return m.Header.Get(ekaweb.HeaderETag)
+}
+
+// Location returns a value of the "Location" HTTP header.
+func (m *ResponseMeta) Location() string {
+	return m.Header.Get(ekaweb.HeaderLocation)
+}
+
+// Link returns the target URI of the "Link" HTTP header (RFC 8288)
+// with the given relation type (e.g. "next"), or an empty string.
+// All "Link" HTTP headers are taken into account.
+func (m *ResponseMeta) Link(rel string) string {
+
+	for _, value := range m.Header.Values(ekaweb.HeaderLink) {
+		if target, ok := findLink(value, rel); ok {
+			return target
+		}
+	}
+
+	return ""
+}
+
+// RetryAfter returns a delay from the "Retry-After" HTTP header,
+// that is either a number of seconds or an HTTP date.
+func (m *ResponseMeta) RetryAfter() (time.Duration, bool) {
+	return parseRetryAfter(m.Header.Get(ekaweb.HeaderRetryAfter))
+}
+
+// RateLimit returns a rate limit state from the "RateLimit-Limit",
+// "RateLimit-Remaining", "RateLimit-Reset" HTTP headers or their "X-" prefixed
+// versions. The reset is a number of seconds. Returns false,
+// if neither limit nor remaining is present.
+func (m *ResponseMeta) RateLimit() (RateLimit, bool) {
+
+	var get = func(name string) (int, bool) {
+		var value = m.Header.Get(name)
+		if value == "" {
+			value = m.Header.Get("X-" + name)
+		}
+		var n, err = strconv.Atoi(strings.TrimSpace(value))
+		return n, err == nil
+	}
+
+	var rl RateLimit
+	var okLimit, okRemaining bool
+	var reset int
+
+	rl.Limit, okLimit = get("RateLimit-Limit")
+	rl.Remaining, okRemaining = get("RateLimit-Remaining")
+	reset, _ = get("RateLimit-Reset")
+
+	rl.Reset = time.Duration(max(reset, 0)) * time.Second
+	return rl, okLimit || okRemaining
+}
+
+func (r *responseMeta) FromHeaders(statusCode int, header, trailer http.Header) {
+	r.meta.StatusCode, r.meta.Header, r.meta.Trailer = statusCode, header, trailer
+	if respHeaders, ok := r.orig.(ekaweb.ClientResponseHeaders); ok {
+		respHeaders.FromHeaders(statusCode, header, trailer)
+	}
+}
+
+func (r *responseMeta) FromData(statusCode int, data []byte) error {
+	r.meta.StatusCode = statusCode
+	if r.orig == nil {
+		return nil
+	}
+	return r.orig.FromData(statusCode, data)
+}
+
+func (r responseMetaStream) FromStream(
+	statusCode int, header http.Header, body io.Reader) error {
+
+	r.meta.StatusCode, r.meta.Header = statusCode, header
+	return r.orig.(ekaweb.ClientResponseStream).FromStream(statusCode, header, body)
+}
+
+////////////////////////////////////////////////////////////////////////////////
+///// PRIVATE METHODS //////////////////////////////////////////////////////////
+////////////////////////////////////////////////////////////////////////////////
+
+// findLink looks for the link with the given relation type in the value
+// of the "Link" HTTP header, e.g. `<https://a/b?page=2>; rel="next"`.
+func findLink(value, rel string) (string, bool) {
+
+	for value != "" {
+		var start = strings.IndexByte(value, '<')
+		if start == -1 {
+			return "", false
+		}
+		var end = strings.IndexByte(value[start:], '>')
+		if end == -1 {
+			return "", false
+		}
+
+		var target = value[start+1 : start+end]
+		value = value[start+end+1:]
+
+		// Parameters last until the next link, that is separated by comma.
+		// Commas inside quoted parameters are not expected in "rel".
+
+		var params = value
+		if next := strings.IndexByte(value, '<'); next != -1 {
+			params, value = value[:next], value[next:]
+		} else {
+			value = ""
+		}
+
+		for _, param := range strings.Split(params, ";") {
+			var key, paramValue, _ = strings.Cut(strings.TrimSpace(param), "=")
+			if !strings.EqualFold(strings.TrimSpace(key), "rel") {
+				continue
+			}
+			paramValue = strings.Trim(strings.TrimSpace(paramValue), `",`)
+			for _, relType := range strings.Fields(paramValue) {
+				if strings.EqualFold(relType, rel) {
+					return target, true
+				}
+			}
+		}
+	}
+
+	return "", false
+}
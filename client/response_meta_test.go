@@ -0,0 +1,114 @@
+package ekaweb_client_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/inaneverb/ekaweb/client/v2"
+	"github.com/inaneverb/ekaweb/v2"
+)
+
+// newMetaClient returns ekaweb.Client, that responds with given
+// HTTP status code, headers, trailers and body.
+func newMetaClient(
+	statusCode int, header, trailer http.Header, body string) ekaweb.Client {
+
+	return ekaweb.ClientFunc(func(
+		_ context.Context, _, _ string, _ http.Header,
+		_ ekaweb.ClientRequest, resp ekaweb.ClientResponse) error {
+
+		if respHeaders, ok := resp.(ekaweb.ClientResponseHeaders); ok {
+			respHeaders.FromHeaders(statusCode, header, trailer)
+		}
+		return resp.FromData(statusCode, []byte(body))
+	})
+}
+
+func TestResponseJSONWithMeta(t *testing.T) {
+
+	var header, trailer = make(http.Header), make(http.Header)
+	header.Set(ekaweb.HeaderETag, `"v1"`)
+	header.Set(ekaweb.HeaderLocation, "/items/1")
+	trailer.Set("X-Checksum", "abc")
+
+	var client = newMetaClient(http.StatusCreated, header, trailer, `{"id":1}`)
+
+	var dest struct{ ID int }
+	var meta ekaweb_client.ResponseMeta
+
+	var err = client.Do(context.Background(), ekaweb.MethodPost, "/items", nil,
+		nil, ekaweb_client.ResponseJSONWithMeta(&dest, &meta))
+
+	require.NoError(t, err)
+	require.Equal(t, 1, dest.ID)
+	require.Equal(t, http.StatusCreated, meta.StatusCode)
+	require.True(t, meta.IsOK())
+	require.Equal(t, `"v1"`, meta.ETag())
+	require.Equal(t, "/items/1", meta.Location())
+	require.Equal(t, "abc", meta.Trailer.Get("X-Checksum"))
+}
+
+func TestResponseJSONWithMeta_Empty(t *testing.T) {
+
+	var header = make(http.Header)
+	header.Set(ekaweb.HeaderETag, `"v1"`)
+	var client = newMetaClient(http.StatusNotModified, header, nil, "")
+
+	var dest struct{ ID int }
+	var meta ekaweb_client.ResponseMeta
+
+	var err = client.Do(context.Background(), ekaweb.MethodGet, "/items/1", nil,
+		nil, ekaweb_client.ResponseJSONWithMeta(&dest, &meta))
+
+	require.NoError(t, err)
+	require.False(t, meta.IsOK())
+	require.Equal(t, `"v1"`, meta.ETag())
+}
+
+func TestResponseMeta_Link(t *testing.T) {
+
+	var meta = ekaweb_client.ResponseMeta{Header: http.Header{
+		ekaweb.HeaderLink: {
+			`<https://a.b/items?page=2>; rel="next", <https://a.b/items?page=9>; rel="last"`,
+			`<https://a.b/items?page=1>; rel="first prev"`,
+		},
+	}}
+
+	require.Equal(t, "https://a.b/items?page=2", meta.Link("next"))
+	require.Equal(t, "https://a.b/items?page=9", meta.Link("last"))
+	require.Equal(t, "https://a.b/items?page=1", meta.Link("prev"))
+	require.Equal(t, "https://a.b/items?page=1", meta.Link("FIRST"))
+	require.Empty(t, meta.Link("self"))
+}
+
+func TestResponseMeta_RateLimit(t *testing.T) {
+
+	var meta = ekaweb_client.ResponseMeta{Header: http.Header{
+		"X-Ratelimit-Limit":     {"100"},
+		"X-Ratelimit-Remaining": {"7"},
+		"X-Ratelimit-Reset":     {"30"},
+	}}
+
+	var rl, ok = meta.RateLimit()
+	require.True(t, ok)
+	require.Equal(t, ekaweb_client.RateLimit{100, 7, 30 * time.Second}, rl)
+
+	_, ok = (&ekaweb_client.ResponseMeta{}).RateLimit()
+	require.False(t, ok)
+}
+
+func TestResponseWithMeta_NoResponse(t *testing.T) {
+
+	var client = newMetaClient(http.StatusInternalServerError, nil, nil, "oops")
+	var meta ekaweb_client.ResponseMeta
+
+	var err = client.Do(context.Background(), ekaweb.MethodGet, "/", nil,
+		nil, ekaweb_client.ResponseWithMeta(nil, &meta))
+
+	require.NoError(t, err)
+	require.Equal(t, http.StatusInternalServerError, meta.StatusCode)
+}
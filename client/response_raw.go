@@ -0,0 +1,21 @@
+package ekaweb_client
+
+import (
+	"github.com/inaneverb/ekaweb/v2"
+)
+
+type responseRaw struct {
+	dest *[]byte
+}
+
+// ResponseRaw returns ekaweb.ClientResponse, that copies HTTP response body
+// to 'dest' regardless of HTTP status code.
+func ResponseRaw(dest *[]byte) ekaweb.ClientResponse {
+	return &responseRaw{dest}
+}
+
+// FromData copies the data, because the client may reuse its buffer.
+func (r *responseRaw) FromData(_ int, data []byte) error {
+	*r.dest = append((*r.dest)[:0], data...)
+	return nil
+}
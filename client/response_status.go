@@ -0,0 +1,142 @@
+package ekaweb_client
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/goccy/go-json"
+
+	"github.com/inaneverb/ekaweb/v2"
+)
+
+type (
+	// StatusError is an error of the HTTP response with non 2xx HTTP status
+	// code, returned by ResponseJSONOrError() and ResponseJSONWithError().
+	// Use errors.As() to extract it.
+	//
+	// If the body is a JSON error, produced by ekaweb_respondent middleware
+	// (its Manifest), its fields are decoded as well.
+	StatusError struct {
+		StatusCode int         `json:"-"`
+		Header     http.Header `json:"-"`
+
+		// Body is the beginning of the HTTP response body,
+		// up to StatusErrorBodyLimit bytes.
+		Body []byte `json:"-"`
+
+		Message      string   `json:"error"`
+		ErrorID      string   `json:"error_id,omitempty"`
+		ErrorCode    int      `json:"error_code"`
+		ErrorDetail  string   `json:"error_detail,omitempty"`
+		ErrorDetails []string `json:"error_details,omitempty"`
+	}
+
+	responseStatus struct {
+		success any
+		failure any
+		header  http.Header
+	}
+)
+
+// StatusErrorBodyLimit is the maximum length of StatusError.Body.
+const StatusErrorBodyLimit = 1024
+
+// ResponseJSONOrError returns ekaweb.ClientResponse, that decodes JSON
+// into 'dest' only if HTTP status code is 2xx (the empty body is not decoded).
+// Otherwise, *StatusError is returned.
+func ResponseJSONOrError(dest any) ekaweb.ClientResponse {
+	return &responseStatus{success: dest}
+}
+
+// ResponseJSONWithError is the same as ResponseJSONOrError(), but also
+// decodes JSON into 'failure' if HTTP status code is not 2xx. *StatusError
+// is returned anyway in that case, the decoding error of 'failure' is ignored.
+func ResponseJSONWithError(success, failure any) ekaweb.ClientResponse {
+	return &responseStatus{success: success, failure: failure}
+}
+
+// StatusCodeOf returns HTTP status code of *StatusError in the chain of 'err',
+// or 0 if there's no one.
+func StatusCodeOf(err error) int {
+	var statusErr *StatusError
+	if errors.As(err, &statusErr) {
+		return statusErr.StatusCode
+	}
+	return 0
+}
+
+func (e *StatusError) Error() string {
+
+	var b strings.Builder
+	_, _ = fmt.Fprintf(&b, "HTTP status code is %d", e.StatusCode)
+
+	switch {
+	case e.Message != "":
+		b.WriteString(": ")
+		b.WriteString(e.Message)
+		if e.ErrorDetail != "" {
+			b.WriteString(": ")
+			b.WriteString(e.ErrorDetail)
+		}
+		if e.ErrorID != "" {
+			b.WriteString(" (error id: ")
+			b.WriteString(e.ErrorID)
+			b.WriteString(")")
+		}
+
+	case len(e.Body) != 0:
+		b.WriteString(": ")
+		b.Write(e.Body)
+	}
+
+	return b.String()
+}
+
+func (r *responseStatus) FromHeaders(_ int, header, _ http.Header) {
+	r.header = header
+}
+
+func (r *responseStatus) FromData(statusCode int, data []byte) error {
+
+	if statusCode >= 200 && statusCode <= 299 {
+		if r.success == nil || len(data) == 0 {
+			return nil
+		}
+		return json.Unmarshal(data, r.success)
+	}
+
+	if r.failure != nil && len(data) != 0 {
+		_ = json.Unmarshal(data, r.failure)
+	}
+
+	return newStatusError(statusCode, r.header, data)
+}
+
+////////////////////////////////////////////////////////////////////////////////
+///// PRIVATE METHODS //////////////////////////////////////////////////////////
+////////////////////////////////////////////////////////////////////////////////
+
+// newStatusError returns *StatusError, decoding the fields of ekaweb_respondent
+// Manifest from 'data' if it's a JSON object.
+func newStatusError(statusCode int, header http.Header, data []byte) *StatusError {
+
+	var e = StatusError{StatusCode: statusCode, Header: header}
+
+	if bytes.HasPrefix(bytes.TrimSpace(data), []byte("{")) {
+		if err := json.Unmarshal(data, &e); err != nil {
+			e = StatusError{StatusCode: statusCode, Header: header}
+		}
+	}
+
+	// The data may be reused by the client, so it's copied.
+
+	var n = min(len(data), StatusErrorBodyLimit)
+	if n != 0 {
+		e.Body = append(make([]byte, 0, n), data[:n]...)
+	}
+
+	return &e
+}
@@ -0,0 +1,84 @@
+package ekaweb_client_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/inaneverb/ekaweb/client/v2"
+	"github.com/inaneverb/ekaweb/v2"
+)
+
+func TestResponseJSONOrError(t *testing.T) {
+
+	var client = newMetaClient(http.StatusOK, nil, nil, `{"id":1}`)
+	var dest struct{ ID int }
+
+	var err = client.Do(context.Background(), ekaweb.MethodGet, "/", nil,
+		nil, ekaweb_client.ResponseJSONOrError(&dest))
+
+	require.NoError(t, err)
+	require.Equal(t, 1, dest.ID)
+}
+
+func TestResponseJSONOrError_Manifest(t *testing.T) {
+
+	const Body = `{"error":"Not found","error_id":"abc","error_code":404,` +
+		`"error_detail":"no such user"}`
+
+	var header = make(http.Header)
+	header.Set("X-Request-Id", "42")
+
+	var client = newMetaClient(http.StatusNotFound, header, nil, Body)
+	var dest struct{ ID int }
+
+	var err = client.Do(context.Background(), ekaweb.MethodGet, "/", nil,
+		nil, ekaweb_client.ResponseJSONOrError(&dest))
+
+	var statusErr *ekaweb_client.StatusError
+	require.True(t, errors.As(err, &statusErr))
+	require.Zero(t, dest.ID)
+
+	require.Equal(t, http.StatusNotFound, statusErr.StatusCode)
+	require.Equal(t, "42", statusErr.Header.Get("X-Request-Id"))
+	require.Equal(t, Body, string(statusErr.Body))
+	require.Equal(t, "Not found", statusErr.Message)
+	require.Equal(t, "abc", statusErr.ErrorID)
+	require.Equal(t, 404, statusErr.ErrorCode)
+	require.Equal(t, "no such user", statusErr.ErrorDetail)
+	require.Equal(t, http.StatusNotFound, ekaweb_client.StatusCodeOf(err))
+
+	require.Equal(t,
+		"HTTP status code is 404: Not found: no such user (error id: abc)",
+		err.Error())
+}
+
+func TestResponseJSONWithError(t *testing.T) {
+
+	var body = `<html>` + string(make([]byte, 2*ekaweb_client.StatusErrorBodyLimit))
+	var client = newMetaClient(http.StatusBadGateway, nil, nil, body)
+
+	var success struct{ ID int }
+	var failure struct{ Error string }
+
+	var err = client.Do(context.Background(), ekaweb.MethodGet, "/", nil,
+		nil, ekaweb_client.ResponseJSONWithError(&success, &failure))
+
+	var statusErr *ekaweb_client.StatusError
+	require.True(t, errors.As(err, &statusErr))
+	require.Equal(t, http.StatusBadGateway, statusErr.StatusCode)
+	require.Len(t, statusErr.Body, ekaweb_client.StatusErrorBodyLimit)
+	require.Empty(t, statusErr.Message)
+	require.Empty(t, failure.Error)
+
+	client = newMetaClient(http.StatusBadRequest, nil, nil, `{"error":"bad"}`)
+	err = client.Do(context.Background(), ekaweb.MethodGet, "/", nil,
+		nil, ekaweb_client.ResponseJSONWithError(&success, &failure))
+
+	require.Equal(t, http.StatusBadRequest, ekaweb_client.StatusCodeOf(err))
+	require.Equal(t, "bad", failure.Error)
+	require.Zero(t, ekaweb_client.StatusCodeOf(errors.New("other")))
+}
@@ -0,0 +1,21 @@
+package ekaweb_client
+
+import (
+	"encoding/xml"
+
+	"github.com/inaneverb/ekaweb/v2"
+)
+
+type responseXML struct {
+	dest any
+}
+
+// ResponseXML returns ekaweb.ClientResponse, that decodes XML into 'dest'
+// regardless of HTTP status code.
+func ResponseXML(dest any) ekaweb.ClientResponse {
+	return &responseXML{dest}
+}
+
+func (r *responseXML) FromData(_ int, data []byte) error {
+	return xml.Unmarshal(data, r.dest)
+}
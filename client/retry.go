@@ -0,0 +1,258 @@
+package ekaweb_client
+
+import (
+	"context"
+	"errors"
+	"math"
+	"math/rand"
+	"net/http"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/inaneverb/ekaweb/v2"
+	"github.com/inaneverb/ekaweb/v2/private"
+)
+
+type (
+	// RetryOption is a callback that allows to modify Retry() interceptor
+	// under its construction.
+	RetryOption func(r *_Retry)
+
+	_Retry struct {
+		maxAttempts    int
+		initialBackoff time.Duration
+		maxBackoff     time.Duration
+		maxElapsed     time.Duration
+		methods        []string
+		statusCodes    []int
+		retryIf        func(err error) bool
+	}
+)
+
+// Retry returns a client interceptor, that retries failed HTTP requests
+// using exponential backoff with jitter. Only idempotent HTTP methods
+// are retried by default (see WithRetryMethods()).
+//
+// The HTTP request is retried, if:
+//   - there's no HTTP response (network error, etc), see WithRetryIf();
+//   - HTTP status code is one of WithRetryStatusCodes().
+//
+// The "Retry-After" HTTP header of the response is honored (if the client
+// passes headers, see ekaweb.ClientResponseHeaders), overriding the backoff,
+// but it's clamped to the maximum backoff (see WithRetryBackoff()).
+// The retry never happens, if it wouldn't fit the elapsed time budget
+// (see WithRetryMaxElapsed()) or the deadline of context.Context.
+// In that case the last HTTP response is decoded as usual.
+//
+// The request data (ekaweb.ClientRequest) is encoded for each attempt.
+// Requests with streaming body (ekaweb.ClientRequestStream) are not retried.
+func Retry(options ...RetryOption) ekaweb.ClientInterceptor {
+
+	var r = _Retry{
+		maxAttempts:    3,
+		initialBackoff: 100 * time.Millisecond,
+		maxBackoff:     10 * time.Second,
+		methods: []string{
+			ekaweb.MethodGet, ekaweb.MethodHead, ekaweb.MethodOptions,
+			ekaweb.MethodTrace, ekaweb.MethodPut, ekaweb.MethodDelete,
+		},
+		statusCodes: []int{
+			http.StatusTooManyRequests, http.StatusBadGateway,
+			http.StatusServiceUnavailable, http.StatusGatewayTimeout,
+		},
+		retryIf: defaultRetryIf,
+	}
+
+	for _, option := range options {
+		if option != nil {
+			option(&r)
+		}
+	}
+
+	return func(next ekaweb.Client) ekaweb.Client {
+		return ekaweb.ClientFunc(func(
+			ctx context.Context, method, path string, headers http.Header,
+			req ekaweb.ClientRequest, resp ekaweb.ClientResponse) error {
+
+			return r.do(next, ctx, method, path, headers, req, resp)
+		})
+	}
+}
+
+// WithRetryMaxAttempts sets the maximum number of attempts,
+// including the first one. Default: 3.
+func WithRetryMaxAttempts(n int) RetryOption {
+	return func(r *_Retry) {
+		if n > 0 {
+			r.maxAttempts = n
+		}
+	}
+}
+
+// WithRetryBackoff sets the initial and the maximum backoff.
+// The backoff is doubled for each attempt. Defaults: 100ms, 10s.
+func WithRetryBackoff(initial, max time.Duration) RetryOption {
+	return func(r *_Retry) {
+		if initial > 0 && max >= initial {
+			r.initialBackoff, r.maxBackoff = initial, max
+		}
+	}
+}
+
+// WithRetryMaxElapsed sets the time budget for all attempts,
+// starting from the first one. The deadline of context.Context
+// (if any) is taken into account anyway. Default: no budget.
+func WithRetryMaxElapsed(d time.Duration) RetryOption {
+	return func(r *_Retry) {
+		r.maxElapsed = max(d, 0)
+	}
+}
+
+// WithRetryMethods sets HTTP methods, requests of which may be retried.
+// Default: GET, HEAD, OPTIONS, TRACE, PUT, DELETE.
+func WithRetryMethods(methods ...string) RetryOption {
+	return func(r *_Retry) {
+		r.methods = methods
+	}
+}
+
+// WithRetryStatusCodes sets HTTP status codes, that leads to retry.
+// Default: 429, 502, 503, 504.
+func WithRetryStatusCodes(statusCodes ...int) RetryOption {
+	return func(r *_Retry) {
+		r.statusCodes = statusCodes
+	}
+}
+
+// WithRetryIf sets a callback, that reports whether an error,
+// returned w/o HTTP response, should lead to retry.
+// Default: any error except context.Canceled and context.DeadlineExceeded.
+func WithRetryIf(cb func(err error) bool) RetryOption {
+	return func(r *_Retry) {
+		if cb != nil {
+			r.retryIf = cb
+		}
+	}
+}
+
+////////////////////////////////////////////////////////////////////////////////
+///// PRIVATE METHODS //////////////////////////////////////////////////////////
+////////////////////////////////////////////////////////////////////////////////
+
+func (r *_Retry) do(
+	next ekaweb.Client, ctx context.Context, method, path string,
+	headers http.Header, req ekaweb.ClientRequest, resp ekaweb.ClientResponse) error {
+
+	// The stream of request body cannot be sent twice.
+
+	var _, isStream = req.(ekaweb.ClientRequestStream)
+
+	if r.maxAttempts <= 1 || isStream || !slices.Contains(r.methods, method) {
+		return next.Do(ctx, method, path, headers, req, resp)
+	}
+
+	var deadline time.Time
+	if r.maxElapsed > 0 {
+		deadline = time.Now().Add(r.maxElapsed)
+	}
+	if ctxDeadline, ok := ctx.Deadline(); ok {
+		if deadline.IsZero() || ctxDeadline.Before(deadline) {
+			deadline = ctxDeadline
+		}
+	}
+
+	for attempt := 1; ; attempt++ {
+		var isLast = attempt >= r.maxAttempts
+		var delay time.Duration
+
+		// The HTTP response with retryable HTTP status code is skipped,
+		// (not decoded) only if the next attempt is possible.
+
+		var proxyResp = ekaweb_private.NewClientResponseRecorder(resp,
+			func(statusCode int, header http.Header) bool {
+				if isLast || !slices.Contains(r.statusCodes, statusCode) {
+					return false
+				}
+				delay = r.backoff(attempt, header)
+				return r.fits(deadline, delay)
+			})
+
+		var err = next.Do(ctx, method, path, headers, req, proxyResp.Wrap())
+
+		switch {
+		case proxyResp.Skipped():
+
+		case err == nil || isLast || proxyResp.StatusCode() != 0 ||
+			ctx.Err() != nil || !r.retryIf(err):
+
+			return err
+
+		default:
+			if delay = r.backoff(attempt, nil); !r.fits(deadline, delay) {
+				return err
+			}
+		}
+
+		var timer = time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// backoff returns a delay before the next attempt. It's a value
+// of the "Retry-After" HTTP header (if any) or an exponential backoff
+// with equal jitter otherwise. Both never exceed the maximum backoff.
+func (r *_Retry) backoff(attempt int, header http.Header) time.Duration {
+
+	if retryAfter, ok := parseRetryAfter(header.Get(ekaweb.HeaderRetryAfter)); ok {
+		return min(retryAfter, r.maxBackoff)
+	}
+
+	// Compare before shifting, so the backoff never overflows.
+
+	var backoff = r.maxBackoff
+	if shift := attempt - 1; shift < 63 && r.initialBackoff <= r.maxBackoff>>shift {
+		backoff = r.initialBackoff << shift
+	}
+
+	var half = backoff / 2
+	return half + time.Duration(rand.Int63n(int64(half)+1))
+}
+
+// fits reports whether the next attempt after 'delay' is started
+// before the 'deadline' (if any).
+func (_ *_Retry) fits(deadline time.Time, delay time.Duration) bool {
+	return deadline.IsZero() || time.Now().Add(delay).Before(deadline)
+}
+
+// parseRetryAfter parses a value of the "Retry-After" HTTP header,
+// that is either a number of seconds or an HTTP date.
+func parseRetryAfter(value string) (time.Duration, bool) {
+
+	if value == "" {
+		return 0, false
+	}
+
+	if seconds, err := strconv.ParseInt(value, 10, 64); err == nil {
+		const MaxSeconds = int64(math.MaxInt64 / time.Second)
+		return time.Duration(min(max(seconds, 0), MaxSeconds)) * time.Second, true
+	}
+
+	if date, err := http.ParseTime(value); err == nil {
+		return max(time.Until(date), 0), true
+	}
+
+	return 0, false
+}
+
+// defaultRetryIf is a default callback, that reports whether an error,
+// returned w/o HTTP response, should lead to retry.
+func defaultRetryIf(err error) bool {
+	return !errors.Is(err, context.Canceled) &&
+		!errors.Is(err, context.DeadlineExceeded)
+}
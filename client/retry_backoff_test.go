@@ -0,0 +1,46 @@
+package ekaweb_client
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/inaneverb/ekaweb/v2"
+)
+
+func TestRetryBackoff(t *testing.T) {
+
+	var r = _Retry{initialBackoff: 10 * time.Second, maxBackoff: time.Minute}
+
+	for attempt := 1; attempt <= 100; attempt++ {
+		var expected = time.Minute
+		if attempt <= 3 {
+			expected = r.initialBackoff << (attempt - 1)
+		}
+
+		var backoff = r.backoff(attempt, nil)
+		require.GreaterOrEqual(t, backoff, expected/2, attempt)
+		require.LessOrEqual(t, backoff, expected, attempt)
+	}
+
+	r = _Retry{initialBackoff: time.Nanosecond, maxBackoff: time.Duration(1<<63 - 1)}
+	require.Positive(t, r.backoff(64, nil))
+}
+
+func TestRetryBackoff_RetryAfter(t *testing.T) {
+
+	var r = _Retry{initialBackoff: time.Second, maxBackoff: time.Minute}
+	var header = func(value string) http.Header {
+		return http.Header{ekaweb.HeaderRetryAfter: {value}}
+	}
+
+	require.Equal(t, 5*time.Second, r.backoff(1, header("5")))
+	require.Equal(t, time.Duration(0), r.backoff(1, header("-5")))
+	require.Equal(t, time.Minute, r.backoff(1, header("3600")))
+	require.Equal(t, time.Minute, r.backoff(1, header("99999999999999999")))
+
+	var date = time.Now().Add(time.Hour).UTC().Format(http.TimeFormat)
+	require.Equal(t, time.Minute, r.backoff(1, header(date)))
+}
@@ -0,0 +1,121 @@
+package ekaweb_client_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/inaneverb/ekaweb/client/v2"
+	"github.com/inaneverb/ekaweb/v2"
+)
+
+type fakeResponse struct {
+	statusCode int
+	header     http.Header
+}
+
+func (r *fakeResponse) FromData(statusCode int, _ []byte) error {
+	r.statusCode = statusCode
+	return nil
+}
+
+// newFakeClient returns ekaweb.Client, that responds with given HTTP status
+// codes one by one (0 means network error), counting attempts.
+func newFakeClient(attempts *int, header http.Header, statusCodes ...int) ekaweb.Client {
+	return ekaweb.ClientFunc(func(
+		_ context.Context, _, _ string, _ http.Header,
+		_ ekaweb.ClientRequest, resp ekaweb.ClientResponse) error {
+
+		var statusCode = statusCodes[min(*attempts, len(statusCodes)-1)]
+		*attempts++
+
+		if statusCode == 0 {
+			return errors.New("connection refused")
+		}
+		if respHeaders, ok := resp.(ekaweb.ClientResponseHeaders); ok {
+			respHeaders.FromHeaders(statusCode, header, nil)
+		}
+		return resp.FromData(statusCode, nil)
+	})
+}
+
+func TestRetry(t *testing.T) {
+
+	var backoff = ekaweb_client.WithRetryBackoff(time.Millisecond, 2*time.Millisecond)
+
+	t.Run("Status", func(t *testing.T) {
+		var attempts int
+		var resp fakeResponse
+		var c = ekaweb.WrapClient(newFakeClient(&attempts, nil, 503, 0, 200),
+			ekaweb_client.Retry(backoff))
+
+		require.NoError(t, c.Do(context.Background(), ekaweb.MethodGet, "/", nil, nil, &resp))
+		require.Equal(t, 3, attempts)
+		require.Equal(t, 200, resp.statusCode)
+	})
+
+	t.Run("MaxAttempts", func(t *testing.T) {
+		var attempts int
+		var resp fakeResponse
+		var c = ekaweb.WrapClient(newFakeClient(&attempts, nil, 503),
+			ekaweb_client.Retry(backoff, ekaweb_client.WithRetryMaxAttempts(2)))
+
+		require.NoError(t, c.Do(context.Background(), ekaweb.MethodGet, "/", nil, nil, &resp))
+		require.Equal(t, 2, attempts)
+		require.Equal(t, 503, resp.statusCode, "the last response must be decoded")
+	})
+
+	t.Run("NonIdempotent", func(t *testing.T) {
+		var attempts int
+		var c = ekaweb.WrapClient(newFakeClient(&attempts, nil, 0),
+			ekaweb_client.Retry(backoff))
+
+		require.Error(t, c.Do(context.Background(), ekaweb.MethodPost, "/", nil, nil, nil))
+		require.Equal(t, 1, attempts)
+	})
+
+	t.Run("RetryAfterExceedsMaxBackoff", func(t *testing.T) {
+		var attempts int
+		var resp fakeResponse
+		var header = http.Header{ekaweb.HeaderRetryAfter: {"10"}}
+		var c = ekaweb.WrapClient(newFakeClient(&attempts, header, 429, 200),
+			ekaweb_client.Retry(backoff))
+
+		require.NoError(t, c.Do(context.Background(), ekaweb.MethodGet, "/", nil, nil, &resp))
+		require.Equal(t, 2, attempts)
+		require.Equal(t, 200, resp.statusCode)
+	})
+
+	t.Run("RetryAfterExceedsDeadline", func(t *testing.T) {
+		var attempts int
+		var resp fakeResponse
+		var header = http.Header{ekaweb.HeaderRetryAfter: {"10"}}
+		var c = ekaweb.WrapClient(newFakeClient(&attempts, header, 429, 200),
+			ekaweb_client.Retry(ekaweb_client.WithRetryBackoff(time.Millisecond, time.Minute)))
+
+		var ctx, cancel = context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+
+		require.NoError(t, c.Do(ctx, ekaweb.MethodGet, "/", nil, nil, &resp))
+		require.Equal(t, 1, attempts)
+		require.Equal(t, 429, resp.statusCode)
+	})
+
+	t.Run("Hooks", func(t *testing.T) {
+		var attempts int
+		var statusCodes []int
+		var after = func(_ context.Context, _, _ string, statusCode int, _ error) {
+			statusCodes = append(statusCodes, statusCode)
+		}
+		var c = ekaweb.WrapClient(newFakeClient(&attempts, nil, 0, 502, 204),
+			ekaweb_client.Retry(backoff),
+			ekaweb_client.Hooks(nil, after))
+
+		require.NoError(t, c.Do(context.Background(), ekaweb.MethodGet, "/", nil, nil, nil))
+		require.Equal(t, []int{0, 502, 204}, statusCodes)
+	})
+}
@@ -0,0 +1,63 @@
+package ekaweb_client
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+
+	"github.com/inaneverb/ekaweb/v2"
+)
+
+type (
+	requestStream struct {
+		body        io.Reader
+		length      int64
+		contentType string
+	}
+
+	responseStream struct {
+		cb func(statusCode int, header http.Header, body io.Reader) error
+	}
+)
+
+// RequestStream returns ekaweb.ClientRequestStream, that sends HTTP request
+// body from 'body' w/o full buffering. The 'length' is the size of the body
+// or -1 if it's unknown. The stream cannot be sent twice (e.g. retried).
+func RequestStream(body io.Reader, length int64, contentType string) ekaweb.ClientRequest {
+	return &requestStream{body, max(length, -1), contentType}
+}
+
+// ResponseStream returns ekaweb.ClientResponseStream, that passes
+// HTTP response body to 'cb' w/o full buffering, regardless of HTTP status code.
+// The body is valid only until 'cb' returns.
+func ResponseStream(
+	cb func(statusCode int, header http.Header, body io.Reader) error) ekaweb.ClientResponse {
+
+	return &responseStream{cb}
+}
+
+func (w *requestStream) Stream() (io.Reader, int64, error) {
+	return w.body, w.length, nil
+}
+
+// Data reads the whole stream. It's used only by the clients,
+// that don't support ekaweb.ClientRequestStream.
+func (w *requestStream) Data() ([]byte, error) {
+	return io.ReadAll(w.body)
+}
+
+func (w *requestStream) ContentType() string {
+	return w.contentType
+}
+
+func (w *responseStream) FromStream(
+	statusCode int, header http.Header, body io.Reader) error {
+
+	return w.cb(statusCode, header, body)
+}
+
+// FromData is used only by the clients,
+// that don't support ekaweb.ClientResponseStream.
+func (w *responseStream) FromData(statusCode int, data []byte) error {
+	return w.cb(statusCode, nil, bytes.NewReader(data))
+}
@@ -0,0 +1,60 @@
+package ekaweb_client_test
+
+import (
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/inaneverb/ekaweb/client/v2"
+	"github.com/inaneverb/ekaweb/v2"
+)
+
+func TestRequestStream(t *testing.T) {
+
+	var req = ekaweb_client.RequestStream(strings.NewReader("body"), -10, "text/plain")
+	require.Equal(t, "text/plain", req.ContentType())
+
+	var body, length, err = req.(ekaweb.ClientRequestStream).Stream()
+	require.NoError(t, err)
+	require.Equal(t, int64(-1), length, "negative length means unknown")
+
+	// The clients w/o stream support read the whole stream.
+
+	data, err := req.Data()
+	require.NoError(t, err)
+	require.Equal(t, "body", string(data))
+
+	rest, _ := io.ReadAll(body)
+	require.Empty(t, rest, "the stream cannot be sent twice")
+}
+
+func TestResponseStream(t *testing.T) {
+
+	var statusCode int
+	var header http.Header
+	var body string
+
+	var resp = ekaweb_client.ResponseStream(func(code int, h http.Header, r io.Reader) error {
+		var data, err = io.ReadAll(r)
+		statusCode, header, body = code, h, string(data)
+		return err
+	})
+
+	var err = resp.(ekaweb.ClientResponseStream).FromStream(
+		http.StatusCreated, http.Header{"X-Test": {"1"}}, strings.NewReader("stream"))
+
+	require.NoError(t, err)
+	require.Equal(t, http.StatusCreated, statusCode)
+	require.Equal(t, "1", header.Get("X-Test"))
+	require.Equal(t, "stream", body)
+
+	// The clients w/o stream support pass the whole body.
+
+	require.NoError(t, resp.FromData(http.StatusNotFound, []byte("data")))
+	require.Equal(t, http.StatusNotFound, statusCode)
+	require.Nil(t, header)
+	require.Equal(t, "data", body)
+}
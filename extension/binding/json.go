@@ -24,15 +24,25 @@ func (jsonBinding) Bind(req *http.Request, obj any) error {
 	if req == nil || req.Body == nil {
 		return fmt.Errorf("invalid request")
 	}
-	return decodeJSON(req.Context(), req.Body, obj)
+	return decodeJSON(req.Context(), req.Header.Get("Content-Type"), req.Body, obj)
 }
 
 func (jsonBinding) BindBody(body []byte, obj any) error {
-	return decodeJSON(nil, bytes.NewReader(body), obj)
+	return decodeJSON(nil, "", bytes.NewReader(body), obj)
 }
 
-func decodeJSON(ctx context.Context, r io.Reader, obj any) error {
-	var err = ekaweb_private.DecodeStream(ctx, r, obj)
+// decodeJSON decodes 'r' to 'obj' using the decoder of the codec,
+// that is chosen by Content-Type (see ekaweb.WithCodecs()),
+// or the default one, if there's no context or Content-Type.
+func decodeJSON(ctx context.Context, contentType string, r io.Reader, obj any) error {
+
+	var err error
+	if ctx != nil {
+		err = ekaweb_private.DecodeStreamWithMIME(ctx, contentType, r, obj)
+	} else {
+		err = ekaweb_private.DecodeStream(ctx, r, obj)
+	}
+
 	if err != nil {
 		return err
 	}
@@ -1,8 +1,10 @@
 package ekaweb_fasthttp
 
 import (
+	"bytes"
 	"context"
 	"fmt"
+	"io"
 	"net/http"
 	"net/url"
 	"strings"
@@ -32,9 +34,15 @@ func (c *Client) Do(
 	// It allows us to skip performing operations if encoding is failed.
 
 	var data []byte
+	var bodyStream io.Reader
+	var bodyLength int64
 	var err error
 
-	if req != nil {
+	if reqStream, ok := req.(ekaweb.ClientRequestStream); ok {
+		if bodyStream, bodyLength, err = reqStream.Stream(); err != nil {
+			return fmt.Errorf("failed to open request stream: %w", err)
+		}
+	} else if req != nil {
 		if data, err = req.Data(); err != nil {
 			//c.e(err, "Failed to encode request.", method, nil, nil, nil)
 			return fmt.Errorf("failed to encode request: %w", err)
@@ -65,8 +73,15 @@ func (c *Client) Do(
 	// Apply request's data if it was generated.
 	// Apply it as query parameters for GET & DELETE methods;
 	// apply as HTTP body for other methods.
+	// The stream is always applied as HTTP body.
 
 	switch {
+	case bodyStream != nil:
+		if mimeType := req.ContentType(); mimeType != "" {
+			fhReq.Header.SetContentType(mimeType)
+		}
+		fhReq.SetBodyStream(bodyStream, int(max(bodyLength, -1)))
+
 	case len(data) == 0:
 		// Skip setting request data.
 
@@ -95,6 +110,9 @@ func (c *Client) Do(
 	fhReq.Header.SetMethod(method)
 	fhReq.SetRequestURI(fhUri.String())
 
+	var respStream, isRespStream = resp.(ekaweb.ClientResponseStream)
+	fhResp.StreamBody = isRespStream
+
 	// Ok, we're ready to perform HTTP request.
 
 	if deadLine, ok := ctx.Deadline(); ok && !deadLine.IsZero() {
@@ -112,15 +130,41 @@ func (c *Client) Do(
 
 	var statusCode = fhResp.StatusCode()
 	var isOK = statusCode >= 200 && statusCode <= 299
-	var respBody = fhResp.Body()
+	var respHeader, respTrailer http.Header
+
+	if respHeaders, ok := resp.(ekaweb.ClientResponseHeaders); ok {
+		respHeader, respTrailer = convertHeaders(&fhResp.Header)
+
+		// The stream is passed as is, so trailers are not available yet.
+
+		if isRespStream {
+			respTrailer = nil
+		}
+
+		respHeaders.FromHeaders(statusCode, respHeader, respTrailer)
+	}
 
 	switch {
 	case !isOK && resp == nil:
 		const E = "HTTP status code is %d, but response is not declared"
 		err = fmt.Errorf(E, statusCode)
 
+	case isRespStream:
+		if respHeader == nil {
+			respHeader, _ = convertHeaders(&fhResp.Header)
+		}
+
+		// The body may be already read, if it's small enough.
+
+		var respBody = fhResp.BodyStream()
+		if respBody == nil {
+			respBody = bytes.NewReader(fhResp.Body())
+		}
+
+		err = respStream.FromStream(statusCode, respHeader, respBody)
+
 	case resp != nil:
-		err = resp.FromData(statusCode, respBody)
+		err = resp.FromData(statusCode, fhResp.Body())
 	}
 
 	{
@@ -131,6 +175,31 @@ func (c *Client) Do(
 	return err
 }
 
+// convertHeaders returns HTTP headers and trailers of fasthttp.ResponseHeader
+// as http.Header. Trailers are nil, if there's no announced trailers.
+// fasthttp stores trailers along with headers, so they are split by keys.
+func convertHeaders(from *fasthttp.ResponseHeader) (header, trailer http.Header) {
+
+	from.VisitAllTrailer(func(key []byte) {
+		if trailer == nil {
+			trailer = make(http.Header, 1)
+		}
+		trailer[http.CanonicalHeaderKey(string(key))] = nil
+	})
+
+	header = make(http.Header, from.Len())
+	from.VisitAll(func(key, value []byte) {
+		var canonicalKey = http.CanonicalHeaderKey(string(key))
+		if _, isTrailer := trailer[canonicalKey]; isTrailer {
+			trailer[canonicalKey] = append(trailer[canonicalKey], string(value))
+		} else {
+			header[canonicalKey] = append(header[canonicalKey], string(value))
+		}
+	})
+
+	return header, trailer
+}
+
 //func (c *Client) e(
 //	err error, description, method string,
 //	uri *fasthttp.URI, req *fasthttp.Request, respBody []byte) {
@@ -180,6 +249,7 @@ func (c *Client) Do(
 func NewClient(options ...ekaweb.ClientOption) ekaweb.Client {
 
 	var client Client
+	var interceptors []ekaweb.ClientInterceptor
 	client.origin = new(fasthttp.Client)
 
 	client.origin.MaxIdleConnDuration = 30 * time.Second
@@ -213,8 +283,11 @@ func NewClient(options ...ekaweb.ClientOption) ekaweb.Client {
 			if option.Log != nil {
 				client.log = option.Log
 			}
+
+		case *ekaweb_private.ClientOptionInterceptors:
+			interceptors = append(interceptors, option.Interceptors...)
 		}
 	}
 
-	return &client
+	return ekaweb.WrapClient(&client, interceptors...)
 }
@@ -0,0 +1,216 @@
+package ekaweb_fasthttp_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/inaneverb/ekaweb/framework/fasthttp/v2"
+	"github.com/inaneverb/ekaweb/v2"
+)
+
+type (
+	// pipeRequest is ekaweb.ClientRequestStream of given length.
+	pipeRequest struct {
+		r      io.Reader
+		length int64
+	}
+
+	// bodyRecorder is ekaweb.ClientResponseStream, that reads the body
+	// by 'chunk' bytes, calling 'onChunk' after each of them.
+	bodyRecorder struct {
+		chunk   int
+		onChunk func()
+
+		statusCode int
+		header     http.Header
+		chunks     []string
+		isStream   bool
+	}
+)
+
+func (r *pipeRequest) Stream() (io.Reader, int64, error) { return r.r, r.length, nil }
+func (r *pipeRequest) Data() ([]byte, error)             { return nil, errors.New("unexpected Data()") }
+func (r *pipeRequest) ContentType() string               { return "application/octet-stream" }
+
+func (r *bodyRecorder) FromData(statusCode int, data []byte) error {
+	r.statusCode, r.chunks = statusCode, []string{string(data)}
+	return nil
+}
+
+func (r *bodyRecorder) FromStream(statusCode int, header http.Header, body io.Reader) error {
+	r.statusCode, r.header, r.isStream = statusCode, header, true
+
+	for {
+		var buf = make([]byte, max(r.chunk, 1))
+		var n, err = io.ReadFull(body, buf)
+		if n > 0 {
+			r.chunks = append(r.chunks, string(buf[:n]))
+		}
+		switch {
+		case errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF):
+			return nil
+		case err != nil:
+			return err
+		case r.onChunk != nil:
+			r.onChunk()
+		}
+	}
+}
+
+func (r *bodyRecorder) body() string {
+	return strings.Join(r.chunks, "")
+}
+
+func newClient(t *testing.T, handler http.HandlerFunc) ekaweb.Client {
+	var server = httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+	return ekaweb_fasthttp.NewClient(ekaweb.WithHostAddr(server.URL))
+}
+
+func TestClient_RequestStream(t *testing.T) {
+
+	var client = newClient(t, func(w http.ResponseWriter, r *http.Request) {
+		var body, _ = io.ReadAll(r.Body)
+		w.Header().Set("X-Length", strconv.FormatInt(r.ContentLength, 10))
+		w.Header().Set("X-Chunked", strconv.FormatBool(len(r.TransferEncoding) > 0))
+		w.Header().Set("X-Content-Type", r.Header.Get(ekaweb.HeaderContentType))
+		_, _ = w.Write(body)
+	})
+
+	for _, tt := range []struct {
+		body    string
+		length  int64
+		expLen  string
+		chunked string
+	}{
+		{"known", 5, "5", "false"},
+		{"unknown", -1, "-1", "true"},
+		{"", 0, "0", "false"},
+	} {
+		var resp = bodyRecorder{chunk: 64}
+		var req = pipeRequest{strings.NewReader(tt.body), tt.length}
+
+		var err = client.Do(context.Background(), ekaweb.MethodPut, "/", nil, &req, &resp)
+		require.NoError(t, err, tt.body)
+
+		require.Equal(t, tt.body, resp.body())
+		require.Equal(t, tt.expLen, resp.header.Get("X-Length"), tt.body)
+		require.Equal(t, tt.chunked, resp.header.Get("X-Chunked"), tt.body)
+		require.Equal(t, "application/octet-stream", resp.header.Get("X-Content-Type"))
+	}
+}
+
+func TestClient_RequestStream_NotBuffered(t *testing.T) {
+
+	var firstReceived = make(chan struct{})
+
+	var client = newClient(t, func(w http.ResponseWriter, r *http.Request) {
+		var first = make([]byte, 3)
+		_, _ = io.ReadFull(r.Body, first)
+		close(firstReceived)
+		var rest, _ = io.ReadAll(r.Body)
+		_, _ = w.Write(append(first, rest...))
+	})
+
+	// The rest of the body is written only after the server
+	// has received its beginning.
+
+	var pr, pw = io.Pipe()
+	go func() {
+		_, _ = io.WriteString(pw, "abc")
+		select {
+		case <-firstReceived:
+			_, _ = io.WriteString(pw, "def")
+			_ = pw.Close()
+		case <-time.After(5 * time.Second):
+			_ = pw.CloseWithError(errors.New("request stream is buffered"))
+		}
+	}()
+
+	var resp = bodyRecorder{chunk: 64}
+	var err = client.Do(context.Background(), ekaweb.MethodPost, "/", nil, &pipeRequest{pr, -1}, &resp)
+
+	require.NoError(t, err)
+	require.Equal(t, "abcdef", resp.body())
+}
+
+func TestClient_ResponseStream(t *testing.T) {
+
+	var chunkRead = make(chan struct{}, 1)
+
+	var client = newClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Test", "live")
+		w.WriteHeader(http.StatusPartialContent)
+
+		// The body is large enough not to be read by fasthttp at once.
+
+		var chunk = strings.Repeat("x", 64<<10)
+		for i := 0; i < 3; i++ {
+			_, _ = io.WriteString(w, chunk)
+			w.(http.Flusher).Flush()
+			select {
+			case <-chunkRead:
+			case <-time.After(5 * time.Second):
+				_, _ = io.WriteString(w, "timeout")
+				return
+			}
+		}
+	})
+
+	var resp = bodyRecorder{chunk: 64 << 10}
+	resp.onChunk = func() { chunkRead <- struct{}{} }
+
+	var err = client.Do(context.Background(), ekaweb.MethodGet, "/", nil, nil, &resp)
+
+	require.NoError(t, err)
+	require.True(t, resp.isStream)
+	require.Equal(t, http.StatusPartialContent, resp.statusCode)
+	require.Equal(t, "live", resp.header.Get("X-Test"))
+	require.Len(t, resp.chunks, 3)
+	require.Equal(t, 3*64<<10, len(resp.body()))
+}
+
+func TestClient_ResponseStream_Small(t *testing.T) {
+
+	// fasthttp reads the small body along with headers,
+	// so it's passed as a stream from the buffer.
+
+	var client = newClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Test", "small")
+		_, _ = io.WriteString(w, "small body")
+	})
+
+	var resp = bodyRecorder{chunk: 64}
+	var err = client.Do(context.Background(), ekaweb.MethodGet, "/", nil, nil, &resp)
+
+	require.NoError(t, err)
+	require.True(t, resp.isStream)
+	require.Equal(t, http.StatusOK, resp.statusCode)
+	require.Equal(t, "small", resp.header.Get("X-Test"))
+	require.Equal(t, "small body", resp.body())
+
+	// There's no body stream at all, if the body is skipped.
+
+	client = newClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Test", "no content")
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	resp = bodyRecorder{chunk: 64}
+	err = client.Do(context.Background(), ekaweb.MethodGet, "/", nil, nil, &resp)
+
+	require.NoError(t, err)
+	require.True(t, resp.isStream)
+	require.Equal(t, http.StatusNoContent, resp.statusCode)
+	require.Equal(t, "no content", resp.header.Get("X-Test"))
+	require.Empty(t, resp.body())
+}
@@ -5,14 +5,18 @@ go 1.21
 require (
 	github.com/inaneverb/ekacore/ekaunsafe/v4 v4.0.0
 	github.com/inaneverb/ekaweb/v2 v2.0.3
+	github.com/stretchr/testify v1.8.2
 	github.com/valyala/fasthttp v1.47.0
 )
 
 require (
 	github.com/andybalholm/brotli v1.0.5 // indirect
 	github.com/cespare/xxhash/v2 v2.2.0 // indirect
+	github.com/davecgh/go-spew v1.1.1 // indirect
 	github.com/inaneverb/ekacore/ekaarr/v4 v4.0.0 // indirect
 	github.com/inaneverb/ekacore/ekaext/v4 v4.0.0 // indirect
 	github.com/klauspost/compress v1.16.3 // indirect
+	github.com/pmezard/go-difflib v1.0.0 // indirect
 	github.com/valyala/bytebufferpool v1.0.0 // indirect
+	gopkg.in/yaml.v3 v3.0.1 // indirect
 )
@@ -1,7 +1,10 @@
 package ekaweb_fasthttp
 
 import (
-	"strings"
+	"context"
+	"crypto/tls"
+	"log"
+	"net"
 	"time"
 
 	"github.com/valyala/fasthttp"
@@ -13,20 +16,117 @@ import (
 )
 
 type Server struct {
-	origin *fasthttp.Server
-	addr   string
+	origin          *fasthttp.Server
+	listeners       ekaweb_private.ServerListeners
+	opened          []net.Listener // bound by AsyncStart()
+	drainer         *ekaweb_private.ServerDrainer
+	done            *ekaweb_private.ServerDone
+	tls             *ekaweb_private.ServerTLS
+	tlsErr          error
+	drainPeriod     time.Duration
+	shutdownTimeout time.Duration
 }
 
 ////////////////////////////////////////////////////////////////////////////////
 ////////////////////////////////////////////////////////////////////////////////
 
+// AsyncStart binds the listeners synchronously, so the errors like
+// "address already in use" are returned, and then serves HTTP requests
+// in the background. The serving error is reported by Wait().
 func (s *Server) AsyncStart() error {
-	go func() { _ = s.origin.ListenAndServe(s.addr) }()
+
+	if s.tlsErr != nil {
+		return s.tlsErr
+	}
+
+	// The same network fasthttp.Server.ListenAndServe() uses.
+	var listeners, err = s.listeners.Listen("tcp4")
+	if err != nil {
+		return err
+	}
+
+	s.tls.Start(s.logTLSError)
+
+	s.opened = listeners
+
+	for i, n := 0, len(listeners); i < n; i++ {
+		var ln = listeners[i]
+		if s.tls != nil {
+			ln = tls.NewListener(ln, s.tls.Config)
+		}
+		go s.serveListener(ln)
+	}
+
 	return nil
 }
 
 func (s *Server) Stop() error {
-	return s.origin.Shutdown()
+	var ctx, cancelFunc = context.WithTimeout(context.Background(), s.shutdownTimeout)
+	defer cancelFunc()
+	return s.StopContext(ctx)
+}
+
+// StopContext gracefully shuts down the server. Keep-alive connections
+// are closed after their current HTTP requests right from the start
+// of the drain period. fasthttp cannot close active connections forcibly,
+// so if 'ctx' is done before all HTTP requests are served,
+// the remaining connections are closed after their current HTTP requests.
+func (s *Server) StopContext(ctx context.Context) error {
+
+	var err = s.drainer.Drain(ctx, s.drainPeriod)
+	s.drainer.GoAway()
+
+	if errShutdown := s.origin.ShutdownWithContext(ctx); err == nil {
+		err = errShutdown
+	}
+
+	s.tls.Stop()
+	s.done.Finish(nil)
+	return err
+}
+
+func (s *Server) Done() <-chan struct{} {
+	return s.done.Done()
+}
+
+func (s *Server) Wait() error {
+	return s.done.Wait()
+}
+
+// serve is fasthttp.RequestHandler, that injects ekaweb_private.ServerDrainer
+// into fasthttp.RequestCtx (it's used as context.Context of http.Request
+// by fasthttpadaptor), closing keep-alive connection while draining.
+func (s *Server) serve(next fasthttp.RequestHandler) fasthttp.RequestHandler {
+	return func(ctx *fasthttp.RequestCtx) {
+		if s.drainer.IsDraining() {
+			ctx.SetConnectionClose()
+		}
+		ctx.SetUserValue(ekaweb_private.ServerDrainerContextKey(), s.drainer)
+		next(ctx)
+	}
+}
+
+// serveListener serves HTTP(S) requests from 'ln' until the server is stopped.
+// If serving fails, other listeners are closed (fasthttp.Server.Serve()
+// returns nil for them), so the server is not left half-alive
+// after Wait() is returned.
+func (s *Server) serveListener(ln net.Listener) {
+	if err := s.origin.Serve(ln); err != nil {
+		for i, n := 0, len(s.opened); i < n; i++ {
+			_ = s.opened[i].Close()
+		}
+		s.tls.Stop()
+		s.done.Finish(err)
+	}
+}
+
+// logTLSError logs the error of reloading TLS certificate.
+func (s *Server) logTLSError(err error) {
+	if s.origin.Logger != nil {
+		s.origin.Logger.Printf("%s", err.Error())
+	} else {
+		log.Printf("ekaweb: %s", err.Error())
+	}
 }
 
 ////////////////////////////////////////////////////////////////////////////////
@@ -36,10 +136,16 @@ func NewServer(options ...ekaweb.ServerOption) ekaweb.Server {
 
 	var server Server
 	server.origin = new(fasthttp.Server)
+	server.drainer = ekaweb_private.NewServerDrainer()
+	server.done = ekaweb_private.NewServerDone()
+	server.shutdownTimeout = 5 * time.Second
 
 	server.origin.IdleTimeout = 10 * time.Second
 	server.origin.TCPKeepalive = true
 
+	var tlsOption *ekaweb_private.ServerOptionTLS
+	var authOption *ekaweb_private.ServerOptionClientAuth
+
 	for i, n := 0, len(options); i < n; i++ {
 		if ekaunsafe.UnpackInterface(options[i]).Word == nil {
 			continue
@@ -55,16 +161,32 @@ func NewServer(options ...ekaweb.ServerOption) ekaweb.Server {
 				server.origin.IdleTimeout = option.Duration
 			}
 
+		case *ekaweb_private.ServerOptionShutdown:
+			server.drainPeriod = max(option.DrainPeriod, 0)
+			if option.Timeout > 0 {
+				server.shutdownTimeout = option.Timeout
+			}
+
+		case *ekaweb_private.ServerOptionTLS:
+			tlsOption = option
+
+		case *ekaweb_private.ServerOptionClientAuth:
+			authOption = option
+
 		case *ekaweb_private.ServerOptionHandler:
 			if ekaunsafe.UnpackInterface(option.Handler).Word != nil {
-				server.origin.Handler =
-					fasthttpadaptor.NewFastHTTPHandler(option.Handler)
+				server.origin.Handler = server.serve(
+					fasthttpadaptor.NewFastHTTPHandler(option.Handler))
 			}
 
 		case *ekaweb_private.ServerOptionListenAddr:
-			if option.Addr = strings.TrimSpace(option.Addr); option.Addr != "" {
-				server.addr = option.Addr
-			}
+			server.listeners.AddAddr(option)
+
+		case *ekaweb_private.ServerOptionListener:
+			server.listeners.AddListener(option)
+
+		case *ekaweb_private.ServerOptionSystemd:
+			server.listeners.EnableSystemd()
 
 		case *ekaweb_private.ClientServerOptionLogger:
 			if option.Log != nil {
@@ -81,5 +203,14 @@ func NewServer(options ...ekaweb.ServerOption) ekaweb.Server {
 		}
 	}
 
+	server.tls, server.tlsErr = ekaweb_private.NewServerTLS(tlsOption, authOption)
+
+	// fasthttp.Server.ListenAndServe() listens on a random port
+	// if the address is empty, so does this one.
+
+	if server.listeners.IsEmpty() {
+		server.listeners.AddAddr(&ekaweb_private.ServerOptionListenAddr{Addr: ":0"})
+	}
+
 	return &server
 }
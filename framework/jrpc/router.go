@@ -24,7 +24,9 @@ type _JRpcRouter struct {
 // jRPC method.
 //
 // WARNING! Middleware won't be executed if no requested method is found,
-// or request is malformed.
+// or request is malformed. The only exception is OPTIONS HTTP requests
+// (like CORS preflights), which are not parsed as jRPC requests at all,
+// so the middlewares could answer them.
 //
 // NOTE. It guarantees, that jRPC context is initialized and presented
 // if middlewares is invoked.
@@ -172,6 +174,16 @@ func (j *_JRpcRouter) initMiddleware(next ekaweb.Handler) ekaweb.Handler {
 		ekaweb_private.UkvsInsertCodec(ctx, codec)
 
 		// Step 2.
+		// OPTIONS HTTP requests has no jRPC body (like CORS preflights).
+		// Pass them to user's middlewares as is, allowing them to answer.
+		// If no one did, it's an error of not registered jRPC method.
+
+		if r.Method == http.MethodOptions {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		// Step 3.
 		// Try to parse body of incoming request, considering it's jRPC request.
 		// It extracts jRPC ID, jRPC method, jRPC params. Params are saved back
 		// as the real payload of jRPC request, but id & method are returned.
@@ -182,7 +194,7 @@ func (j *_JRpcRouter) initMiddleware(next ekaweb.Handler) ekaweb.Handler {
 			return // early exit: malformed jRPC request
 		}
 
-		// Step 3.
+		// Step 4.
 		// Check if jRPC method is provided. Lookup for requested jRPC method.
 
 		if jCtx.Method == "" {
@@ -196,7 +208,7 @@ func (j *_JRpcRouter) initMiddleware(next ekaweb.Handler) ekaweb.Handler {
 			return // early exit: no such jRPC method found
 		}
 
-		// Step 4.
+		// Step 5.
 		// Execute next middleware.
 
 		next.ServeHTTP(w, r)
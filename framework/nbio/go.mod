@@ -5,6 +5,7 @@ go 1.21
 require (
 	github.com/inaneverb/ekacore/ekaunsafe/v4 v4.0.0
 	github.com/inaneverb/ekaweb/v2 v2.0.4
+	github.com/lesismal/llib v1.1.12
 	github.com/lesismal/nbio v1.3.15
 )
 
@@ -12,7 +13,6 @@ require (
 	github.com/cespare/xxhash/v2 v2.2.0 // indirect
 	github.com/inaneverb/ekacore/ekaarr/v4 v4.0.0 // indirect
 	github.com/inaneverb/ekacore/ekaext/v4 v4.0.0 // indirect
-	github.com/lesismal/llib v1.1.12 // indirect
 	golang.org/x/crypto v0.6.0 // indirect
 	golang.org/x/sys v0.5.0 // indirect
 )
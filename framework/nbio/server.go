@@ -1,7 +1,10 @@
 package ekaweb_nbio
 
 import (
-	"strings"
+	"context"
+	"net"
+	"strconv"
+	"time"
 
 	"github.com/inaneverb/ekacore/ekaunsafe/v4"
 	"github.com/inaneverb/ekaweb/v2"
@@ -12,19 +15,106 @@ import (
 )
 
 type Server struct {
-	origin *nbhttp.Server
+	origin          *nbhttp.Server
+	config          nbhttp.Config
+	listeners       ekaweb_private.ServerListeners
+	drainer         *ekaweb_private.ServerDrainer
+	done            *ekaweb_private.ServerDone
+	tls             *ekaweb_private.ServerTLS
+	tlsErr          error
+	drainPeriod     time.Duration
+	shutdownTimeout time.Duration
 }
 
 ////////////////////////////////////////////////////////////////////////////////
 ////////////////////////////////////////////////////////////////////////////////
 
+// AsyncStart binds the listeners synchronously and then serves HTTP requests
+// in the background. nbio has no terminal serving errors, so Wait()
+// reports only the shutdown (see StopContext()).
 func (s *Server) AsyncStart() error {
-	return s.origin.Start()
+
+	if s.tlsErr != nil {
+		return s.tlsErr
+	}
+
+	var config = s.config
+	var addrs []string
+
+	// nbio opens listeners by itself using Config.Listen(),
+	// so the already opened ones are passed by their indexes as addresses.
+
+	if !s.listeners.IsEmpty() {
+		var listeners, err = s.listeners.Listen("tcp")
+		if err != nil {
+			return err
+		}
+
+		for i, n := 0, len(listeners); i < n; i++ {
+			addrs = append(addrs, strconv.Itoa(i))
+		}
+
+		config.Listen = func(_, addr string) (net.Listener, error) {
+			var i, _ = strconv.Atoi(addr)
+			return listeners[i], nil
+		}
+	}
+
+	if s.tls != nil {
+		config.AddrsTLS = addrs
+	} else {
+		config.Addrs = addrs
+	}
+
+	s.origin = nbhttp.NewServer(config)
+
+	if err := s.origin.Start(); err != nil {
+		return err
+	}
+
+	s.tls.Start(func(err error) { logging.Error("ekaweb: %s", err.Error()) })
+	return nil
 }
 
 func (s *Server) Stop() error {
-	s.origin.Stop()
-	return nil
+	var ctx, cancelFunc = context.WithTimeout(context.Background(), s.shutdownTimeout)
+	defer cancelFunc()
+	return s.StopContext(ctx)
+}
+
+// StopContext gracefully shuts down the server. Keep-alive connections
+// are closed after their current HTTP requests right from the start
+// of the drain period. nbio closes all connections at once when shutting down,
+// so in-flight HTTP requests are waited before (still accepting new ones).
+// If 'ctx' is done before, the remaining connections are closed forcibly.
+func (s *Server) StopContext(ctx context.Context) error {
+
+	var err = s.drainer.Drain(ctx, s.drainPeriod)
+	s.drainer.GoAway()
+
+	if errWait := s.drainer.Wait(ctx); err == nil {
+		err = errWait
+	}
+
+	// The origin is created by AsyncStart().
+
+	if s.origin != nil {
+		if errShutdown := s.origin.Shutdown(ctx); err == nil {
+			err = errShutdown
+		}
+	}
+
+	s.tls.Stop()
+	s.done.Finish(nil)
+	return err
+}
+
+func (s *Server) Done() <-chan struct{} {
+	return s.done.Done()
+}
+
+func (s *Server) Wait() error {
+	return s.done.Wait()
 }
 
 ////////////////////////////////////////////////////////////////////////////////
@@ -32,7 +122,16 @@ func (s *Server) Stop() error {
 
 func NewServer(options ...ekaweb.ServerOption) ekaweb.Server {
 
-	var nbioConfig nbhttp.Config
+	var server = Server{
+		drainer:         ekaweb_private.NewServerDrainer(),
+		done:            ekaweb_private.NewServerDone(),
+		shutdownTimeout: 5 * time.Second,
+	}
+
+	server.config.Context = server.drainer.WithContext(context.Background())
+
+	var tlsOption *ekaweb_private.ServerOptionTLS
+	var authOption *ekaweb_private.ServerOptionClientAuth
 
 	for i, n := 0, len(options); i < n; i++ {
 		if ekaunsafe.UnpackInterface(options[i]).Word == nil {
@@ -40,15 +139,31 @@ func NewServer(options ...ekaweb.ServerOption) ekaweb.Server {
 		}
 
 		switch option := options[i].(type) {
+		case *ekaweb_private.ServerOptionShutdown:
+			server.drainPeriod = max(option.DrainPeriod, 0)
+			if option.Timeout > 0 {
+				server.shutdownTimeout = option.Timeout
+			}
+
+		case *ekaweb_private.ServerOptionTLS:
+			tlsOption = option
+
+		case *ekaweb_private.ServerOptionClientAuth:
+			authOption = option
+
 		case *ekaweb_private.ServerOptionHandler:
 			if ekaunsafe.UnpackInterface(option.Handler).Word != nil {
-				nbioConfig.Handler = option.Handler
+				server.config.Handler = server.drainer.Handler(option.Handler)
 			}
 
 		case *ekaweb_private.ServerOptionListenAddr:
-			if option.Addr = strings.TrimSpace(option.Addr); option.Addr != "" {
-				nbioConfig.Addrs = append(nbioConfig.Addrs, option.Addr)
-			}
+			server.listeners.AddAddr(option)
+
+		case *ekaweb_private.ServerOptionListener:
+			server.listeners.AddListener(option)
+
+		case *ekaweb_private.ServerOptionSystemd:
+			server.listeners.EnableSystemd()
 
 		case *ekaweb_private.ClientServerOptionLogger:
 			if option.Log != nil {
@@ -60,5 +175,15 @@ func NewServer(options ...ekaweb.ServerOption) ekaweb.Server {
 		}
 	}
 
-	return &Server{nbhttp.NewServer(nbioConfig)}
+	server.tls, server.tlsErr = ekaweb_private.NewServerTLS(tlsOption, authOption)
+
+	if server.tls != nil {
+		server.config.TLSConfig, server.tlsErr = convertTLSConfig(server.tls.Config)
+
+		if server.config.Handler != nil {
+			server.config.Handler = tlsHandler(server.config.Handler)
+		}
+	}
+
+	return &server
 }
@@ -0,0 +1,90 @@
+package ekaweb_nbio
+
+import (
+	"crypto/tls"
+	"errors"
+	"net/http"
+
+	llibtls "github.com/lesismal/llib/std/crypto/tls"
+	"github.com/lesismal/nbio/nbhttp"
+)
+
+// convertTLSConfig returns llib's tls.Config (nbio uses llib's fork
+// of crypto/tls), that is built from the crypto/tls one.
+// GetConfigForClient and verification callbacks are not supported,
+// so an error is returned, if GetConfigForClient is the only source
+// of certificates.
+func convertTLSConfig(from *tls.Config) (*llibtls.Config, error) {
+
+	if len(from.Certificates) == 0 && from.GetCertificate == nil {
+		const D = "no TLS certificate (GetConfigForClient is not supported by nbio)"
+		return nil, errors.New(D)
+	}
+
+	var to = llibtls.Config{
+		ClientCAs:    from.ClientCAs,
+		ClientAuth:   llibtls.ClientAuthType(from.ClientAuth),
+		MinVersion:   from.MinVersion,
+		MaxVersion:   from.MaxVersion,
+		NextProtos:   from.NextProtos,
+		CipherSuites: from.CipherSuites,
+	}
+
+	for i, n := 0, len(from.Certificates); i < n; i++ {
+		to.Certificates = append(to.Certificates, *convertCertificate(&from.Certificates[i]))
+	}
+
+	if getCertificate := from.GetCertificate; getCertificate != nil {
+		to.GetCertificate = func(hello *llibtls.ClientHelloInfo) (*llibtls.Certificate, error) {
+			var cert, err = getCertificate(&tls.ClientHelloInfo{
+				CipherSuites:      hello.CipherSuites,
+				ServerName:        hello.ServerName,
+				SupportedProtos:   hello.SupportedProtos,
+				SupportedVersions: hello.SupportedVersions,
+				Conn:              hello.Conn,
+			})
+			if err != nil || cert == nil {
+				return nil, err
+			}
+			return convertCertificate(cert), nil
+		}
+	}
+
+	return &to, nil
+}
+
+func convertCertificate(from *tls.Certificate) *llibtls.Certificate {
+	return &llibtls.Certificate{
+		Certificate:                 from.Certificate,
+		PrivateKey:                  from.PrivateKey,
+		OCSPStaple:                  from.OCSPStaple,
+		SignedCertificateTimestamps: from.SignedCertificateTimestamps,
+		Leaf:                        from.Leaf,
+	}
+}
+
+// tlsHandler returns http.Handler, that fills http.Request.TLS
+// (nbio doesn't do it) from the TLS connection, the request is read from.
+func tlsHandler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+
+		var res, _ = w.(*nbhttp.Response)
+		if res != nil && res.Parser != nil && res.Parser.Processor != nil {
+			if conn, ok := res.Parser.Processor.Conn().(*llibtls.Conn); ok {
+				var state = conn.ConnectionState()
+				r.TLS = &tls.ConnectionState{
+					Version:            state.Version,
+					HandshakeComplete:  state.HandshakeComplete,
+					DidResume:          state.DidResume,
+					CipherSuite:        state.CipherSuite,
+					NegotiatedProtocol: state.NegotiatedProtocol,
+					ServerName:         state.ServerName,
+					PeerCertificates:   state.PeerCertificates,
+					VerifiedChains:     state.VerifiedChains,
+				}
+			}
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
@@ -29,6 +29,10 @@ type Conn struct {
 	options *Options // parameters, conn is created with
 
 	originConn *websocket.Conn // nbio WebSocket connection object
+
+	// cancelOnShutdown unregisters the callback, that closes the connection
+	// with CloseCodeGoingAway when the server is shutting down.
+	cancelOnShutdown func()
 }
 
 func (c *Conn) ID() string {
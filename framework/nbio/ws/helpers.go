@@ -26,6 +26,7 @@ const (
 )
 
 const (
+	CloseCodeGoingAway        = ekaweb_socket.CloseCodeGoingAway
 	CloseCodeNoStatusReceived = ekaweb_socket.CloseCodeNoStatusReceived
 	CloseCodeInternalError    = ekaweb_socket.CloseCodeInternalError
 	CloseCodeAbnormal         = ekaweb_socket.CloseCodeAbnormal
@@ -55,5 +56,14 @@ func applyErrorHandler(conn *Conn, err error) {
 }
 
 func duplicateHTTPRequestContext(ctx context.Context) (context.Context, func()) {
-	return context.WithCancel(ekaweb_private.UkvsStealTo(ctx, context.Background()))
+	var newCtx = ekaweb_private.UkvsStealTo(ctx, context.Background())
+
+	// The server's drainer is kept to allow the connection to know
+	// whether the server is shutting down (see ekaweb.OnServerShutdown()).
+
+	if drainer := ekaweb_private.ServerDrainerFromContext(ctx); drainer != nil {
+		newCtx = drainer.WithContext(newCtx)
+	}
+
+	return context.WithCancel(newCtx)
 }
@@ -73,6 +73,10 @@ func makeOnOpenCallback(
 	return _NbioWebSocketOnOpenCallback(func(originConn *websocket.Conn) {
 		conn := makeConn(ctx, options, originConn)
 
+		conn.cancelOnShutdown = ekaweb.OnServerShutdown(ctx, func() {
+			conn.CloseWithCode(CloseCodeGoingAway)
+		})
+
 		if err := handler.OnOpen(conn); err != nil {
 			applyErrorHandler(conn, err)
 		}
@@ -89,6 +93,10 @@ func makeOnCloseCallback(
 		var wrappedConn = connFromOrigin(originConn)
 		var cc, ccDetail = wrappedConn.getCloseData()
 
+		if wrappedConn != nil && wrappedConn.cancelOnShutdown != nil {
+			wrappedConn.cancelOnShutdown()
+		}
+
 		// The response close message is sent already. So it doesn't matter
 		// whether user return true or false from OnClose.
 
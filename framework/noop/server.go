@@ -1,13 +1,27 @@
 package ekaweb_noop
 
 import (
+	"context"
+
 	"github.com/inaneverb/ekaweb/v2"
 )
 
 type nopeServer struct{}
 
+// closedChan is a channel that is closed, returned by nopeServer.Done().
+var closedChan = func() chan struct{} {
+	var ch = make(chan struct{})
+	close(ch)
+	return ch
+}()
+
 func (_ *nopeServer) AsyncStart() error { return nil }
 func (_ *nopeServer) Stop() error       { return nil }
+func (_ *nopeServer) Wait() error       { return nil }
+
+func (_ *nopeServer) Done() <-chan struct{} { return closedChan }
+
+func (_ *nopeServer) StopContext(_ context.Context) error { return nil }
 
 // NewServer returns an ekaweb.Server that does nothing at all.
 // Its methods always returns nil as error.
@@ -0,0 +1,22 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/inaneverb/ekaweb/framework/radix/v2"
+	"github.com/inaneverb/ekaweb/v2"
+)
+
+func main() {
+	var r = ekaweb_radix.NewRouter(ekaweb.WithServerName("radix.example"))
+	r.Get("/users/{user_id:[0-9]+}", handler)
+	r.Group("/static").Get("/*", handler) // <-- catch all
+	panic(http.ListenAndServe(":8081", r.Build()))
+}
+
+func handler(w http.ResponseWriter, r *http.Request) {
+	_, _ = fmt.Fprintf(w,
+		"Hello from:\n\tRegistered route: %s\n\tActual route: %s\n\tUser: %s\n",
+		ekaweb.RoutePath(r), r.RequestURI, ekaweb.URLVarGet(r, "user_id"))
+}
@@ -0,0 +1,14 @@
+module github.com/inaneverb/ekaweb/framework/radix/v2
+
+go 1.21
+
+require (
+	github.com/inaneverb/ekacore/ekaunsafe/v4 v4.0.0
+	github.com/inaneverb/ekaweb/v2 v2.1.1
+)
+
+require (
+	github.com/cespare/xxhash/v2 v2.2.0 // indirect
+	github.com/inaneverb/ekacore/ekaarr/v4 v4.0.0 // indirect
+	github.com/inaneverb/ekacore/ekaext/v4 v4.0.0 // indirect
+)
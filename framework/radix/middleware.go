@@ -0,0 +1,27 @@
+package ekaweb_radix
+
+import (
+	"net/http"
+
+	"github.com/inaneverb/ekaweb/v2"
+	"github.com/inaneverb/ekaweb/v2/private"
+)
+
+type _MiddlewareInvalidatePath struct{}
+
+func (*_MiddlewareInvalidatePath) CheckErrorBefore() bool {
+	return false
+}
+
+// Callback treats all paths as invalid ones. The dispatcher of Router
+// overwrites it, when the route is found.
+func (*_MiddlewareInvalidatePath) Callback(next ekaweb.Handler) ekaweb.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ekaweb_private.UkvsSetPathNotFoundOrNotAllowed(r.Context(), true)
+		next.ServeHTTP(w, r)
+	})
+}
+
+func newInvalidatePathMiddleware() ekaweb.Middleware {
+	return &_MiddlewareInvalidatePath{}
+}
@@ -0,0 +1,398 @@
+package ekaweb_radix
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/inaneverb/ekacore/ekaunsafe/v4"
+	"github.com/inaneverb/ekaweb/v2"
+	"github.com/inaneverb/ekaweb/v2/middleware"
+	"github.com/inaneverb/ekaweb/v2/private"
+)
+
+// Router is an ekaweb.Router implementation, that is built on top of
+// its own radix tree. It supports static paths, URL variables ({param}),
+// URL variables with regexp ({param:regex}) and catch-all (*) segments.
+//
+// Matched URL variables and registered route path are stored directly
+// to the UKVS, so ekaweb.URLVarGet() and ekaweb.RoutePath() work as is.
+type Router struct {
+	root        *_Root
+	parent      *Router
+	prefix      string
+	middlewares []ekaweb.Middleware
+}
+
+// _Root is a state, that is shared between the root Router and all its groups.
+type _Root struct {
+	tree        _Node
+	middlewares []ekaweb.Middleware // global; applied before routing
+	fallbacks   []_Fallback         // NotFound, MethodNotAllowed handlers
+	stripSlash  bool
+	redirSlash  bool
+	paramsPool  sync.Pool
+}
+
+// _Fallback is NotFound and MethodNotAllowed handlers of some Router
+// (root or group). The most specific (by prefix) one is used.
+type _Fallback struct {
+	owner      *Router
+	notFound   ekaweb.Handler
+	notAllowed ekaweb.Handler
+}
+
+// _Dispatcher is a final handler of Router. It looks up for the route
+// and calls its handler, or the fallback one if route is not found.
+type _Dispatcher struct {
+	root      *_Root
+	fallbacks []_Fallback // sorted by prefix length (longest first)
+}
+
+////////////////////////////////////////////////////////////////////////////////
+///// Router interface implementation checker //////////////////////////////////
+////////////////////////////////////////////////////////////////////////////////
+
+var _ ekaweb.Router = (*Router)(nil)
+
+////////////////////////////////////////////////////////////////////////////////
+///// Router interface implementation //////////////////////////////////////////
+////////////////////////////////////////////////////////////////////////////////
+
+func (r *Router) Use(components ...any) ekaweb.Router {
+
+	var checkError = ekaweb_private.NewCheckErrorMiddleware()
+	var middlewares, _ = ekaweb_private.BuildHandlerOut(components, checkError, true)
+
+	if r.parent == nil {
+		r.root.middlewares = append(r.root.middlewares, middlewares...)
+	} else {
+		r.middlewares = append(r.middlewares, middlewares...)
+	}
+
+	return r
+}
+
+func (r *Router) Group(prefix string, middlewares ...any) ekaweb.Router {
+
+	prefix = strings.TrimRight(strings.TrimSpace(prefix), "/")
+	if prefix != "" && prefix[0] != '/' {
+		prefix = "/" + prefix
+	}
+
+	var child = &Router{root: r.root, parent: r, prefix: r.prefix + prefix}
+	child.Use(middlewares...)
+
+	return child
+}
+
+func (r *Router) Get(path string, middlewaresAndHandler ...any) ekaweb.Router {
+	return r.reg(_MethodGet, path, middlewaresAndHandler)
+}
+
+func (r *Router) Head(path string, middlewaresAndHandler ...any) ekaweb.Router {
+	return r.reg(_MethodHead, path, middlewaresAndHandler)
+}
+
+func (r *Router) Post(path string, middlewaresAndHandler ...any) ekaweb.Router {
+	return r.reg(_MethodPost, path, middlewaresAndHandler)
+}
+
+func (r *Router) Put(path string, middlewaresAndHandler ...any) ekaweb.Router {
+	return r.reg(_MethodPut, path, middlewaresAndHandler)
+}
+
+func (r *Router) Delete(path string, middlewaresAndHandler ...any) ekaweb.Router {
+	return r.reg(_MethodDelete, path, middlewaresAndHandler)
+}
+
+func (r *Router) Connect(path string, middlewaresAndHandler ...any) ekaweb.Router {
+	return r.reg(_MethodConnect, path, middlewaresAndHandler)
+}
+
+func (r *Router) Options(path string, middlewaresAndHandler ...any) ekaweb.Router {
+	return r.reg(_MethodOptions, path, middlewaresAndHandler)
+}
+
+func (r *Router) Trace(path string, middlewaresAndHandler ...any) ekaweb.Router {
+	return r.reg(_MethodTrace, path, middlewaresAndHandler)
+}
+
+func (r *Router) Patch(path string, middlewaresAndHandler ...any) ekaweb.Router {
+	return r.reg(_MethodPatch, path, middlewaresAndHandler)
+}
+
+func (r *Router) NotFound(handler any) ekaweb.Router {
+	if asHandler := ekaweb_private.AsHandler(handler); asHandler != nil {
+		r.fallback().notFound = asHandler
+	}
+	return r
+}
+
+func (r *Router) MethodNotAllowed(handler any) ekaweb.Router {
+	if asHandler := ekaweb_private.AsHandler(handler); asHandler != nil {
+		r.fallback().notAllowed = asHandler
+	}
+	return r
+}
+
+////////////////////////////////////////////////////////////////////////////////
+///// Router build section (as a part of Router interface) /////////////////////
+////////////////////////////////////////////////////////////////////////////////
+
+func (r *Router) Build() ekaweb.Handler {
+
+	// Group middlewares may be added after routes are registered,
+	// thus final handlers are built only here.
+
+	r.root.tree.walk(func(endpoint *_Endpoint) {
+		endpoint.final = endpoint.owner.wrap(endpoint.handler)
+	})
+
+	var d = _Dispatcher{root: r.root}
+	for _, fallback := range r.root.fallbacks {
+		if fallback.notFound != nil {
+			fallback.notFound = fallback.owner.wrap(fallback.notFound)
+		}
+		if fallback.notAllowed != nil {
+			fallback.notAllowed = fallback.owner.wrap(fallback.notAllowed)
+		}
+		d.fallbacks = append(d.fallbacks, fallback)
+	}
+
+	sort.SliceStable(d.fallbacks, func(i, j int) bool {
+		return len(d.fallbacks[i].owner.prefix) > len(d.fallbacks[j].owner.prefix)
+	})
+
+	var middlewares = append([]ekaweb.Middleware(nil), r.root.middlewares...)
+	return ekaweb_private.MergeMiddlewares(middlewares, &d)
+}
+
+////////////////////////////////////////////////////////////////////////////////
+///// Dispatching //////////////////////////////////////////////////////////////
+////////////////////////////////////////////////////////////////////////////////
+
+func (d *_Dispatcher) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+
+	var path = r.URL.RawPath
+	if path == "" {
+		path = r.URL.Path
+	}
+
+	if len(path) > 1 && path[len(path)-1] == '/' {
+		switch {
+		case d.root.stripSlash:
+			path = path[:len(path)-1]
+
+		case d.root.redirSlash:
+			// Multiple leading slashes (or backslashes) are collapsed,
+			// otherwise "//evil.com/" is redirected to another host.
+			path = "/" + strings.TrimLeft(path[:len(path)-1], "/\\")
+			if r.URL.RawQuery != "" {
+				path += "?" + r.URL.RawQuery
+			}
+			http.Redirect(w, r, path, http.StatusMovedPermanently)
+			return
+		}
+	}
+
+	var params = d.root.paramsPool.Get().(*_Params)
+	defer d.root.releaseParams(params)
+
+	var method = methodIndex(r.Method)
+	var found, notAllowed = d.root.tree.lookup(method, path, params)
+
+	if found == nil {
+		d.serveFallback(w, r, path, notAllowed)
+		return
+	}
+
+	var ctx = r.Context()
+	ekaweb_private.UkvsInsertOriginalPath(ctx, found.pattern)
+
+	for i, n := 0, params.len(); i < n; i++ {
+		ekaweb_private.UkvsInsert(ctx, params.keys[i], params.values[i])
+	}
+
+	// All paths are treated as invalid ones by the router's core middleware.
+	// Now, when the route is found, the path is valid.
+
+	ekaweb_private.UkvsSetPathNotFoundOrNotAllowed(ctx, false)
+
+	found.endpoints[method].final.ServeHTTP(w, r)
+}
+
+// serveFallback calls the NotFound or MethodNotAllowed handler
+// of the most specific Router, which prefix is matched with the given 'path'.
+func (d *_Dispatcher) serveFallback(
+	w http.ResponseWriter, r *http.Request, path string, notAllowed *_Node) {
+
+	if notAllowed != nil {
+		w.Header().Set(ekaweb.HeaderAllow, notAllowed.allowedMethods())
+	}
+
+	for _, fallback := range d.fallbacks {
+		if !hasPathPrefix(path, fallback.owner.prefix) {
+			continue
+		}
+		switch {
+		case notAllowed != nil && fallback.notAllowed != nil:
+			fallback.notAllowed.ServeHTTP(w, r)
+			return
+
+		case notAllowed == nil && fallback.notFound != nil:
+			fallback.notFound.ServeHTTP(w, r)
+			return
+		}
+	}
+
+	if notAllowed != nil {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	} else {
+		http.NotFound(w, r)
+	}
+}
+
+////////////////////////////////////////////////////////////////////////////////
+///// PRIVATE METHODS //////////////////////////////////////////////////////////
+////////////////////////////////////////////////////////////////////////////////
+
+// reg is a main function of middlewares and handler registration for specific
+// HTTP route. It takes an index of HTTP method and the set of parameters
+// using which a new HTTP route should be created.
+func (r *Router) reg(method int, path string, components []any) ekaweb.Router {
+
+	path = strings.TrimSpace(path)
+	if path == "" || path[0] != '/' {
+		return r
+	}
+
+	var checkError = ekaweb_private.NewCheckErrorMiddleware()
+
+	var middlewares, handler = ekaweb_private.BuildHandlerOut(components, checkError, false)
+	handler = ekaweb_private.MergeMiddlewares(middlewares, handler)
+
+	if handler == nil {
+		return r
+	}
+
+	var endpoint = _Endpoint{owner: r, handler: handler, final: handler}
+	r.root.tree.insert(method, r.prefix+path, &endpoint)
+
+	return r
+}
+
+// wrap returns a Handler, that is given 'handler' wrapped by the middlewares
+// of current Router and all its parents, except root one.
+// Root's middlewares are applied to the whole dispatcher.
+func (r *Router) wrap(handler ekaweb.Handler) ekaweb.Handler {
+
+	for ; r.parent != nil; r = r.parent {
+		var middlewares = append([]ekaweb.Middleware(nil), r.middlewares...)
+		handler = ekaweb_private.MergeMiddlewares(middlewares, handler)
+	}
+
+	return handler
+}
+
+// fallback returns a _Fallback for the current Router, creating it if needed.
+func (r *Router) fallback() *_Fallback {
+
+	for i := range r.root.fallbacks {
+		if r.root.fallbacks[i].owner == r {
+			return &r.root.fallbacks[i]
+		}
+	}
+
+	r.root.fallbacks = append(r.root.fallbacks, _Fallback{owner: r})
+	return &r.root.fallbacks[len(r.root.fallbacks)-1]
+}
+
+// releaseParams returns given _Params back to the pool.
+func (rt *_Root) releaseParams(params *_Params) {
+	params.reset()
+	rt.paramsPool.Put(params)
+}
+
+// hasPathPrefix reports whether given 'path' starts with given 'prefix',
+// and the prefix is a full path segment(s).
+func hasPathPrefix(path, prefix string) bool {
+	return strings.HasPrefix(path, prefix) &&
+		(len(path) == len(prefix) || path[len(prefix)] == '/')
+}
+
+////////////////////////////////////////////////////////////////////////////////
+///// Router constructors //////////////////////////////////////////////////////
+////////////////////////////////////////////////////////////////////////////////
+
+func NewRouter(options ...ekaweb.RouterOption) ekaweb.Router {
+
+	var r = &Router{root: new(_Root)}
+	r.root.paramsPool.New = func() any { return new(_Params) }
+
+	var middlewares = make([]ekaweb.Middleware, 0, 10)
+
+	var doCoreInit = true
+	var customResponseHeaders = http.Header{}
+
+	var ukvsManager *ekaweb_private.UkvsManager
+	var optCodec *ekaweb_private.RouterOptionCodec
+
+	for i, n := 0, len(options); i < n; i++ {
+		if ekaunsafe.UnpackInterface(options[i]).Word == nil {
+			continue
+		}
+
+		switch option := options[i].(type) {
+
+		case *ekaweb_private.RouterOptionCoreInit:
+			doCoreInit = option.Enable
+
+		case *ekaweb_private.RouterOptionCodec:
+			optCodec = option
+
+		case *ekaweb_private.RouterOptionUkvsManager:
+			ukvsManager = option.Manager
+
+		case *ekaweb_private.RouterOptionServerName:
+			if option.ServerName != "" {
+				customResponseHeaders.Set(ekaweb.HeaderServer, option.ServerName)
+			}
+
+		case *ekaweb_private.RouterOptionErrorHandler:
+			if option.Handler != nil {
+				var mErrorHandler = ekaweb_private.NewErrorHandlerMiddleware(option.Handler)
+				middlewares = append(middlewares, mErrorHandler)
+			}
+
+		case *ekaweb_private.RouterOptionTrailingSlash:
+			r.root.stripSlash = option.Strip
+			r.root.redirSlash = option.Redirect && !option.Strip
+		}
+	}
+
+	if doCoreInit {
+		if ukvsManager == nil {
+			if optCodec == nil {
+				type T = ekaweb_private.RouterOptionCodec
+				optCodec = ekaweb.WithCodec(json.NewEncoder, json.NewDecoder).(*T)
+			}
+			var g = ekaweb_private.NewUkvsMapGeneratorSlice()
+			ukvsManager = ekaweb_private.NewUkvsManager(g, *optCodec)
+		}
+		var mCoreInit = ekaweb_private.NewUkvsManagerMiddleware(ukvsManager)
+		var mInvalidatePath = newInvalidatePathMiddleware()
+		// prepend
+		middlewares = append([]ekaweb.Middleware{mCoreInit, mInvalidatePath}, middlewares...)
+	}
+
+	if len(customResponseHeaders) > 0 {
+		var mCustomHeaders = ekaweb_middleware.CustomHeaders(customResponseHeaders)
+		middlewares = append(middlewares, mCustomHeaders)
+	}
+
+	r.root.middlewares = middlewares
+	return r
+}
@@ -0,0 +1,163 @@
+package ekaweb_radix_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/inaneverb/ekaweb/framework/radix/v2"
+	"github.com/inaneverb/ekaweb/v2"
+)
+
+func echoHandler(w http.ResponseWriter, r *http.Request) {
+	_, _ = fmt.Fprintf(w, "%s|%s|%s|%s",
+		ekaweb.RoutePath(r), ekaweb.URLVarGet(r, "id"),
+		ekaweb.URLVarGet(r, "name"), ekaweb.URLVarGet(r, "*"))
+}
+
+func newMarkMiddleware(mark string) ekaweb.Middleware {
+	return ekaweb.MiddlewareFunc(func(next ekaweb.Handler) ekaweb.Handler {
+		return ekaweb.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Add("X-Mark", mark)
+			next.ServeHTTP(w, r)
+		})
+	})
+}
+
+func perform(h ekaweb.Handler, method, path string) *httptest.ResponseRecorder {
+	var w = httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(method, path, nil))
+	return w
+}
+
+func TestRouter(t *testing.T) {
+
+	var r = ekaweb_radix.NewRouter()
+	r.Get("/users", echoHandler)
+	r.Get("/users/me", echoHandler)
+	r.Get("/users/{id:[0-9]+}", echoHandler)
+	r.Get("/users/{name}", echoHandler)
+	r.Post("/users/{id:[0-9]+}/avatar", echoHandler)
+	r.Get("/files/{name}.{id}", echoHandler)
+	r.Get("/static/*", echoHandler)
+
+	var h = r.Build()
+
+	var tests = []struct {
+		method, path string
+		status       int
+		body         string
+	}{
+		{"GET", "/users", 200, "/users|||"},
+		{"GET", "/users/me", 200, "/users/me|||"},
+		{"GET", "/users/42", 200, "/users/{id:[0-9]+}|42||"},
+		{"GET", "/users/alice", 200, "/users/{name}||alice|"},
+		{"POST", "/users/42/avatar", 200, "/users/{id:[0-9]+}/avatar|42||"},
+		{"GET", "/files/report.pdf", 200, "/files/{name}.{id}|pdf|report|"},
+		{"GET", "/static/", 200, "/static/*|||"},
+		{"GET", "/static/css/main.css", 200, "/static/*|||css/main.css"},
+		{"GET", "/unknown", 404, ""},
+		{"GET", "/users/42/avatar", 405, ""},
+		{"DELETE", "/users", 405, ""},
+	}
+
+	for _, tt := range tests {
+		var w = perform(h, tt.method, tt.path)
+		if w.Code != tt.status {
+			t.Errorf("%s %s: status %d, want %d", tt.method, tt.path, w.Code, tt.status)
+		}
+		if tt.status == 200 && w.Body.String() != tt.body {
+			t.Errorf("%s %s: body %q, want %q", tt.method, tt.path, w.Body.String(), tt.body)
+		}
+	}
+
+	if allow := perform(h, "DELETE", "/users").Header().Get(ekaweb.HeaderAllow); allow != "GET" {
+		t.Errorf("Allow header %q, want %q", allow, "GET")
+	}
+}
+
+func TestRouterGroup(t *testing.T) {
+
+	var r = ekaweb_radix.NewRouter()
+	r.Use(newMarkMiddleware("root"))
+
+	var api = r.Group("/api", newMarkMiddleware("api"))
+	api.Get("/users/{id}", echoHandler)
+	api.NotFound(func(w http.ResponseWriter, r *http.Request) {
+		ekaweb.SendString(w, http.StatusNotFound, "api not found")
+	})
+
+	var v2 = api.Group("/v2")
+	v2.Get("/users/{id}", echoHandler)
+	v2.Use(newMarkMiddleware("v2")) // after route registration
+
+	var h = r.Build()
+
+	var w = perform(h, "GET", "/api/users/1")
+	if got := w.Body.String(); got != "/api/users/{id}|1||" {
+		t.Errorf("unexpected body: %q", got)
+	}
+	if got := w.Header().Values("X-Mark"); len(got) != 2 {
+		t.Errorf("unexpected marks: %v", got)
+	}
+
+	w = perform(h, "GET", "/api/v2/users/2")
+	if got := w.Body.String(); got != "/api/v2/users/{id}|2||" {
+		t.Errorf("unexpected body: %q", got)
+	}
+	if got := w.Header().Values("X-Mark"); len(got) != 3 || got[2] != "v2" {
+		t.Errorf("unexpected marks: %v", got)
+	}
+
+	w = perform(h, "GET", "/api/nothing")
+	if w.Code != 404 || w.Body.String() != "api not found" {
+		t.Errorf("unexpected group not found: %d %q", w.Code, w.Body.String())
+	}
+
+	w = perform(h, "GET", "/nothing")
+	if w.Code != 404 || w.Body.String() == "api not found" {
+		t.Errorf("unexpected root not found: %d %q", w.Code, w.Body.String())
+	}
+}
+
+func TestRouterTrailingSlash(t *testing.T) {
+
+	var r = ekaweb_radix.NewRouter(ekaweb.WithTrailingSlash(false, true))
+	r.Get("/users", echoHandler)
+
+	if w := perform(r.Build(), "GET", "/users/"); w.Code != 200 {
+		t.Errorf("strip: status %d, want 200", w.Code)
+	}
+
+	r = ekaweb_radix.NewRouter(ekaweb.WithTrailingSlash(true, false))
+	r.Get("/users", echoHandler)
+
+	var w = perform(r.Build(), "GET", "/users/?a=b")
+	if w.Code != http.StatusMovedPermanently || w.Header().Get("Location") != "/users?a=b" {
+		t.Errorf("redirect: status %d, location %q", w.Code, w.Header().Get("Location"))
+	}
+
+	// No open redirect to another host.
+
+	for _, path := range []string{"//evil.com/", "///evil.com/", "/\\evil.com/"} {
+		w = perform(r.Build(), "GET", path)
+		if w.Code != http.StatusMovedPermanently || w.Header().Get("Location") != "/evil.com" {
+			t.Errorf("redirect %q: status %d, location %q",
+				path, w.Code, w.Header().Get("Location"))
+		}
+	}
+}
+
+func TestRouterConflict(t *testing.T) {
+
+	defer func() {
+		if recover() == nil {
+			t.Error("expected panic on conflicting URL variables")
+		}
+	}()
+
+	ekaweb_radix.NewRouter().
+		Get("/users/{id}", echoHandler).
+		Get("/users/{name}/x", echoHandler)
+}
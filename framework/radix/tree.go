@@ -0,0 +1,426 @@
+package ekaweb_radix
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/inaneverb/ekaweb/v2"
+)
+
+type (
+	// _Node is a node of radix tree, that is used to route incoming HTTP
+	// requests. There are 3 kinds of nodes: static (just a part of path),
+	// param ({param} or {param:regex}) and catch-all (*).
+	_Node struct {
+		kind   _NodeKind
+		prefix string // static part of path; empty for non-static nodes
+
+		paramKey  string         // URL variable's name (non-static nodes)
+		paramTail byte           // char at which param ends; '/' by default
+		paramExpr string         // source of regexp (to compare nodes)
+		paramRe   *regexp.Regexp // compiled and anchored regexp, may be nil
+
+		staticChildren []*_Node // sorted by first byte of their prefixes
+		paramChildren  []*_Node // regexp params first, then plain ones
+		catchAllChild  *_Node
+
+		pattern   string                   // full registered route path
+		endpoints [_MethodCount]*_Endpoint // indexed by methodIndex()
+	}
+
+	// _NodeKind describes what kind of path part _Node represents.
+	_NodeKind uint8
+
+	// _Endpoint is a route's handler, registered for some HTTP method.
+	// It keeps the origin handler (w/o group middlewares) separately,
+	// allowing to rebuild final handler if group middlewares were changed.
+	_Endpoint struct {
+		owner   *Router
+		handler ekaweb.Handler // route's middlewares + handler
+		final   ekaweb.Handler // wrapped by owner group's middlewares
+	}
+
+	// _Params is a set of URL variables, that were extracted
+	// during looking up for the HTTP route in the radix tree.
+	_Params struct {
+		keys   []string
+		values []string
+	}
+)
+
+const (
+	_NodeKindStatic _NodeKind = iota
+	_NodeKindParam
+	_NodeKindCatchAll
+)
+
+const (
+	_MethodGet = iota
+	_MethodHead
+	_MethodPost
+	_MethodPut
+	_MethodDelete
+	_MethodConnect
+	_MethodOptions
+	_MethodTrace
+	_MethodPatch
+	_MethodCount
+)
+
+// gMethods is a set of HTTP methods, that indices are matched
+// with the indices of _Node's endpoints.
+var gMethods = [_MethodCount]string{
+	ekaweb.MethodGet, ekaweb.MethodHead, ekaweb.MethodPost,
+	ekaweb.MethodPut, ekaweb.MethodDelete, ekaweb.MethodConnect,
+	ekaweb.MethodOptions, ekaweb.MethodTrace, ekaweb.MethodPatch,
+}
+
+////////////////////////////////////////////////////////////////////////////////
+///// Radix tree insertion /////////////////////////////////////////////////////
+////////////////////////////////////////////////////////////////////////////////
+
+// insert registers given _Endpoint for the 'method' and 'pattern'.
+// Creates all required nodes, splitting existing ones if necessary.
+// Panics if 'pattern' is malformed or conflicts with already registered one.
+func (n *_Node) insert(method int, pattern string, endpoint *_Endpoint) {
+
+	var search = pattern
+
+	for search != "" {
+		var idx = strings.IndexAny(search, "{*")
+
+		switch {
+		case idx == -1:
+			n = n.insertStatic(search)
+			search = ""
+
+		case idx > 0:
+			n = n.insertStatic(search[:idx])
+			search = search[idx:]
+
+		case search[0] == '*':
+			if len(search) != 1 {
+				const E = "ekaweb_radix: catch-all must be the last in route %q"
+				panic(fmt.Sprintf(E, pattern))
+			}
+			if n.catchAllChild == nil {
+				n.catchAllChild = &_Node{kind: _NodeKindCatchAll, paramKey: "*"}
+			}
+			n = n.catchAllChild
+			search = ""
+
+		default:
+			var end = paramEnd(search)
+			if end == -1 {
+				const E = "ekaweb_radix: unclosed URL variable in route %q"
+				panic(fmt.Sprintf(E, pattern))
+			}
+			var tail = byte('/')
+			if end+1 < len(search) {
+				tail = search[end+1]
+			}
+			n = n.insertParam(pattern, search[1:end], tail)
+			search = search[end+1:]
+		}
+	}
+
+	if n.endpoints[method] != nil {
+		const E = "ekaweb_radix: route %s %q is already registered"
+		panic(fmt.Sprintf(E, gMethods[method], pattern))
+	}
+
+	n.pattern = pattern
+	n.endpoints[method] = endpoint
+}
+
+// insertStatic walks over (or creates) static nodes that represent
+// given 'path' and returns the last one.
+func (n *_Node) insertStatic(path string) *_Node {
+
+	for path != "" {
+		var i = sort.Search(len(n.staticChildren), func(i int) bool {
+			return n.staticChildren[i].prefix[0] >= path[0]
+		})
+
+		if i == len(n.staticChildren) || n.staticChildren[i].prefix[0] != path[0] {
+			var child = &_Node{kind: _NodeKindStatic, prefix: path}
+			n.staticChildren = append(n.staticChildren, nil)
+			copy(n.staticChildren[i+1:], n.staticChildren[i:])
+			n.staticChildren[i] = child
+			return child
+		}
+
+		var child = n.staticChildren[i]
+		var common = longestCommonPrefix(child.prefix, path)
+
+		if common < len(child.prefix) {
+			// Split the child: its common part becomes a new parent node.
+			var parent = &_Node{kind: _NodeKindStatic, prefix: child.prefix[:common]}
+			child.prefix = child.prefix[common:]
+			parent.staticChildren = []*_Node{child}
+			n.staticChildren[i] = parent
+			child = parent
+		}
+
+		n, path = child, path[common:]
+	}
+
+	return n
+}
+
+// insertParam returns a param node for given 'param' (a content of braces)
+// and 'tail', creating it if there's no such one.
+func (n *_Node) insertParam(pattern, param string, tail byte) *_Node {
+
+	var key, expr, _ = strings.Cut(param, ":")
+	if key = strings.TrimSpace(key); key == "" {
+		const E = "ekaweb_radix: empty URL variable name in route %q"
+		panic(fmt.Sprintf(E, pattern))
+	}
+
+	for _, child := range n.paramChildren {
+		if child.paramExpr != expr || child.paramTail != tail {
+			continue
+		}
+		if child.paramKey != key {
+			const E = "ekaweb_radix: URL variable %q conflicts with %q in route %q"
+			panic(fmt.Sprintf(E, key, child.paramKey, pattern))
+		}
+		return child
+	}
+
+	var child = &_Node{
+		kind:      _NodeKindParam,
+		paramKey:  key,
+		paramTail: tail,
+		paramExpr: expr,
+	}
+
+	if expr != "" {
+		child.paramRe = regexp.MustCompile("^(?:" + expr + ")$")
+	}
+
+	// Params with regexp must be checked before plain ones,
+	// otherwise they will never be matched.
+
+	n.paramChildren = append(n.paramChildren, child)
+	sort.SliceStable(n.paramChildren, func(i, j int) bool {
+		return n.paramChildren[i].paramRe != nil && n.paramChildren[j].paramRe == nil
+	})
+
+	return child
+}
+
+////////////////////////////////////////////////////////////////////////////////
+///// Radix tree lookup ////////////////////////////////////////////////////////
+////////////////////////////////////////////////////////////////////////////////
+
+// lookup searches for the node that matches given 'path' and has an endpoint
+// for the given 'method' (an index, or -1 if method is unknown).
+//
+// If there's no such node, but there's a node that matches 'path' and has
+// endpoints for other methods, that node is returned as 'notAllowed'.
+// URL variables of the found node are stored to the given _Params
+// (they're not stored for 'notAllowed' node).
+func (n *_Node) lookup(
+	method int, path string, params *_Params) (found, notAllowed *_Node) {
+
+	var candidate *_Node
+	if found = n.find(method, path, params, &candidate); found == nil {
+		params.reset()
+	}
+
+	return found, candidate
+}
+
+// find is a recursive part of lookup().
+func (n *_Node) find(
+	method int, path string, params *_Params, candidate **_Node) *_Node {
+
+	if path == "" {
+		if n.hasEndpointFor(method, candidate) {
+			return n
+		}
+		if n.catchAllChild != nil &&
+			n.catchAllChild.hasEndpointFor(method, candidate) {
+
+			params.push(n.catchAllChild.paramKey, "")
+			return n.catchAllChild
+		}
+		return nil
+	}
+
+	// Static nodes have the highest priority.
+
+	var i = sort.Search(len(n.staticChildren), func(i int) bool {
+		return n.staticChildren[i].prefix[0] >= path[0]
+	})
+
+	if i < len(n.staticChildren) {
+		var child = n.staticChildren[i]
+		if strings.HasPrefix(path, child.prefix) {
+			var found = child.find(method, path[len(child.prefix):], params, candidate)
+			if found != nil {
+				return found
+			}
+		}
+	}
+
+	// Then URL variables: regexp ones first, plain ones then.
+
+	for _, child := range n.paramChildren {
+		var end = strings.IndexByte(path, child.paramTail)
+		switch {
+		case end == -1 && child.paramTail != '/':
+			continue
+		case end == -1:
+			end = len(path)
+		}
+
+		var value = path[:end]
+		if value == "" || strings.IndexByte(value, '/') != -1 {
+			continue
+		}
+		if child.paramRe != nil && !child.paramRe.MatchString(value) {
+			continue
+		}
+
+		var mark = params.len()
+		params.push(child.paramKey, value)
+
+		if found := child.find(method, path[end:], params, candidate); found != nil {
+			return found
+		}
+
+		params.truncate(mark)
+	}
+
+	// And catch-all as a last resort.
+
+	if n.catchAllChild != nil && n.catchAllChild.hasEndpointFor(method, candidate) {
+		params.push(n.catchAllChild.paramKey, path)
+		return n.catchAllChild
+	}
+
+	return nil
+}
+
+// hasEndpointFor reports whether current node has an endpoint for given
+// 'method' (-1 if it's unknown). If it has not, but has endpoints
+// for other methods, it's saved to the 'candidate' (if it's not set yet).
+func (n *_Node) hasEndpointFor(method int, candidate **_Node) bool {
+
+	if method >= 0 && n.endpoints[method] != nil {
+		return true
+	}
+
+	if *candidate == nil {
+		for i := range n.endpoints {
+			if n.endpoints[i] != nil {
+				*candidate = n
+				break
+			}
+		}
+	}
+
+	return false
+}
+
+// allowedMethods returns a comma separated list of HTTP methods,
+// for which current node has endpoints. Suitable for "Allow" HTTP header.
+func (n *_Node) allowedMethods() string {
+
+	var methods = make([]string, 0, _MethodCount)
+	for i := range n.endpoints {
+		if n.endpoints[i] != nil {
+			methods = append(methods, gMethods[i])
+		}
+	}
+
+	return strings.Join(methods, ", ")
+}
+
+// walk calls given 'cb' for each endpoint that is registered in the tree.
+func (n *_Node) walk(cb func(endpoint *_Endpoint)) {
+
+	for i := range n.endpoints {
+		if n.endpoints[i] != nil {
+			cb(n.endpoints[i])
+		}
+	}
+
+	for _, child := range n.staticChildren {
+		child.walk(cb)
+	}
+	for _, child := range n.paramChildren {
+		child.walk(cb)
+	}
+	if n.catchAllChild != nil {
+		n.catchAllChild.walk(cb)
+	}
+}
+
+////////////////////////////////////////////////////////////////////////////////
+///// URL variables ////////////////////////////////////////////////////////////
+////////////////////////////////////////////////////////////////////////////////
+
+func (p *_Params) push(key, value string) {
+	p.keys = append(p.keys, key)
+	p.values = append(p.values, value)
+}
+
+func (p *_Params) len() int {
+	return len(p.keys)
+}
+
+func (p *_Params) truncate(n int) {
+	p.keys, p.values = p.keys[:n], p.values[:n]
+}
+
+func (p *_Params) reset() {
+	p.truncate(0)
+}
+
+////////////////////////////////////////////////////////////////////////////////
+///// PRIVATE FUNCTIONS ////////////////////////////////////////////////////////
+////////////////////////////////////////////////////////////////////////////////
+
+// methodIndex returns an index of given HTTP 'method' in gMethods,
+// or -1 if such method is not supported.
+func methodIndex(method string) int {
+	for i := range gMethods {
+		if gMethods[i] == method {
+			return i
+		}
+	}
+	return -1
+}
+
+// paramEnd returns an index of closing brace of URL variable,
+// that starts at the beginning of given 's'. Braces that are part
+// of regexp (like {2,3}) are taken into account. Returns -1 if not found.
+func paramEnd(s string) int {
+	var depth = 0
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '{':
+			depth++
+		case '}':
+			if depth--; depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// longestCommonPrefix returns the length of common prefix of 'a' and 'b'.
+func longestCommonPrefix(a, b string) int {
+	var i, n = 0, min(len(a), len(b))
+	for i < n && a[i] == b[i] {
+		i++
+	}
+	return i
+}
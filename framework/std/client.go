@@ -0,0 +1,267 @@
+package ekaweb_std
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/inaneverb/ekacore/ekaunsafe/v4"
+	"github.com/inaneverb/ekaweb/v2"
+	"github.com/inaneverb/ekaweb/v2/private"
+)
+
+type Client struct {
+	origin    *http.Client
+	log       ekaweb.Logger
+	path      string
+	userAgent *string
+}
+
+////////////////////////////////////////////////////////////////////////////////
+////////////////////////////////////////////////////////////////////////////////
+
+func (c *Client) Do(
+	ctx context.Context, method, path string, headers http.Header,
+	req ekaweb.ClientRequest, resp ekaweb.ClientResponse) error {
+
+	// Perform early encoding.
+	// It allows us to skip performing operations if encoding is failed.
+
+	var data []byte
+	var bodyStream io.Reader
+	var bodyLength int64
+	var err error
+
+	if reqStream, ok := req.(ekaweb.ClientRequestStream); ok {
+		if bodyStream, bodyLength, err = reqStream.Stream(); err != nil {
+			return fmt.Errorf("failed to open request stream: %w", err)
+		}
+	} else if req != nil {
+		if data, err = req.Data(); err != nil {
+			return fmt.Errorf("failed to encode request: %w", err)
+		}
+	}
+
+	path = strings.Trim(path, "\\/")
+	if c.path != "" {
+		path = c.path + "/" + path
+	}
+
+	var reqURL *url.URL
+	if reqURL, err = url.Parse(path); err != nil {
+		return fmt.Errorf("failed to parse URL (%s): %w", path, err)
+	}
+
+	// Apply request's data if it was generated.
+	// Apply it as query parameters for GET & DELETE methods;
+	// apply as HTTP body for other methods.
+	// The stream is always applied as HTTP body.
+
+	var body io.Reader
+	var mimeType string
+
+	switch {
+	case bodyStream != nil:
+		mimeType = req.ContentType()
+		body = bodyStream
+
+	case len(data) == 0:
+		// Skip setting request data.
+
+	case method == ekaweb.MethodGet || method == ekaweb.MethodDelete:
+		reqURL.RawQuery = ekaunsafe.BytesToString(data)
+
+	default:
+		mimeType = req.ContentType()
+		body = bytes.NewReader(data)
+	}
+
+	var httpReq *http.Request
+	if httpReq, err = http.NewRequestWithContext(ctx, method, reqURL.String(), body); err != nil {
+		return fmt.Errorf("failed to create HTTP request: %w", err)
+	}
+
+	// The size of the stream is unknown for net/http, unless it's specified.
+	// Zero means unknown as well, so the empty body must be replaced.
+
+	switch {
+	case bodyStream == nil:
+	case bodyLength == 0:
+		httpReq.Body, httpReq.ContentLength = http.NoBody, 0
+	case bodyLength > 0:
+		httpReq.ContentLength = bodyLength
+	}
+
+	for headerKey, headerValue := range headers {
+		for i, n := 0, len(headerValue); i < n; i++ {
+			httpReq.Header.Add(headerKey, headerValue[i])
+		}
+	}
+
+	if mimeType != "" {
+		httpReq.Header.Set(ekaweb.HeaderContentType, mimeType)
+	}
+
+	// Empty User-Agent header means no User-Agent header at all.
+
+	if _, found := httpReq.Header[ekaweb.HeaderUserAgent]; !found && c.userAgent != nil {
+		httpReq.Header[ekaweb.HeaderUserAgent] = []string{*c.userAgent}
+	}
+
+	// Ok, we're ready to perform HTTP request.
+
+	var httpResp *http.Response
+	if httpResp, err = c.origin.Do(httpReq); err != nil {
+		c.debug(method, reqURL, err)
+		return fmt.Errorf("failed to perform HTTP request: %w", err)
+	}
+
+	defer httpResp.Body.Close()
+
+	var statusCode = httpResp.StatusCode
+	var isOK = statusCode >= 200 && statusCode <= 299
+
+	// The stream is passed as is, so trailers are not available yet.
+
+	if respStream, ok := resp.(ekaweb.ClientResponseStream); ok {
+		if respHeaders, ok := resp.(ekaweb.ClientResponseHeaders); ok {
+			respHeaders.FromHeaders(statusCode, httpResp.Header, nil)
+		}
+		return respStream.FromStream(statusCode, httpResp.Header, httpResp.Body)
+	}
+
+	var respBody []byte
+	if respBody, err = io.ReadAll(httpResp.Body); err != nil {
+		c.debug(method, reqURL, err)
+		return fmt.Errorf("failed to read HTTP response: %w", err)
+	}
+
+	// Analyze and decode response.
+	// Trailers are available only after the body is read.
+
+	if respHeaders, ok := resp.(ekaweb.ClientResponseHeaders); ok {
+		respHeaders.FromHeaders(statusCode, httpResp.Header, httpResp.Trailer)
+	}
+
+	switch {
+	case !isOK && resp == nil:
+		const E = "HTTP status code is %d, but response is not declared"
+		err = fmt.Errorf(E, statusCode)
+
+	case resp != nil:
+		err = resp.FromData(statusCode, respBody)
+	}
+
+	return err
+}
+
+////////////////////////////////////////////////////////////////////////////////
+////////////////////////////////////////////////////////////////////////////////
+
+// applyTimeouts applies the read timeout as the timeout of waiting
+// for the response headers and the write timeout as the timeouts
+// of connecting and TLS handshake. Non-positive timeouts are ignored.
+func applyTimeouts(transport *http.Transport, option *ekaweb_private.ClientServerOptionTimeout) {
+
+	if option.ReadTimeout > 0 {
+		transport.ResponseHeaderTimeout = option.ReadTimeout
+	}
+
+	if option.WriteTimeout > 0 {
+		var dialer = net.Dialer{Timeout: option.WriteTimeout, KeepAlive: 30 * time.Second}
+		transport.DialContext = dialer.DialContext
+		transport.TLSHandshakeTimeout = option.WriteTimeout
+	}
+}
+
+// debug logs the error of performing HTTP request, if logger is set.
+func (c *Client) debug(method string, reqURL *url.URL, err error) {
+	if c.log != nil {
+		const D = "Client: Failed to perform HTTP request %s %s: %s."
+		c.log.Debug(D, method, reqURL.Redacted(), err.Error())
+	}
+}
+
+////////////////////////////////////////////////////////////////////////////////
+////////////////////////////////////////////////////////////////////////////////
+
+// NewClient returns a new ekaweb.Client, that is based on net/http.
+// By default, it uses a clone of http.DefaultTransport, supporting HTTP/2.
+// Timeouts (see ekaweb.WithTimeouts()) are applied to the transport,
+// so they don't limit reading of the (streamed) response body:
+// the read timeout is the time of waiting for the response headers
+// after the request is written, the write timeout limits connecting
+// and TLS handshake. They're ignored, if the transport, passed
+// by ekaweb.WithTransport(), is not *http.Transport.
+// Use context.Context's deadline to limit the whole HTTP exchange.
+func NewClient(options ...ekaweb.ClientOption) ekaweb.Client {
+
+	var client Client
+	var interceptors []ekaweb.ClientInterceptor
+	var http2Option *ekaweb_private.ClientServerOptionHTTP2
+	var timeoutOption *ekaweb_private.ClientServerOptionTimeout
+
+	client.origin = new(http.Client)
+	client.origin.Transport = http.DefaultTransport.(*http.Transport).Clone()
+
+	for i, n := 0, len(options); i < n; i++ {
+		if ekaunsafe.UnpackInterface(options[i]).Word == nil {
+			continue
+		}
+
+		switch option := options[i].(type) {
+
+		case *ekaweb_private.ClientOptionHostAddr:
+			var addr = strings.Trim(option.Addr, "/\\ ")
+			if _, err := url.Parse(addr); err == nil {
+				client.path = addr
+			}
+
+		case *ekaweb_private.ClientOptionUserAgent:
+			var userAgent = option.UserAgent
+			client.userAgent = &userAgent
+
+		case *ekaweb_private.ClientServerOptionTimeout:
+			timeoutOption = option
+
+		case *ekaweb_private.ClientServerOptionLogger:
+			if option.Log != nil {
+				client.log = option.Log
+			}
+
+		case *ekaweb_private.ClientServerOptionTransport:
+			if ekaunsafe.UnpackInterface(option.Transport).Word != nil {
+				client.origin.Transport = option.Transport
+			}
+
+		case *ekaweb_private.ClientServerOptionHTTP2:
+			http2Option = option
+
+		case *ekaweb_private.ClientOptionInterceptors:
+			interceptors = append(interceptors, option.Interceptors...)
+		}
+	}
+
+	// HTTP/2 and timeouts are applied to a copy of the transport
+	// (it may be passed by WithTransport()), if it's *http.Transport.
+
+	var transport, _ = client.origin.Transport.(*http.Transport)
+	if (http2Option != nil || timeoutOption != nil) && transport != nil {
+		transport = transport.Clone()
+		if http2Option != nil {
+			applyHTTP2Transport(transport, http2Option)
+		}
+		if timeoutOption != nil {
+			applyTimeouts(transport, timeoutOption)
+		}
+		client.origin.Transport = transport
+	}
+
+	return ekaweb.WrapClient(&client, interceptors...)
+}
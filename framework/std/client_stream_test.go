@@ -0,0 +1,188 @@
+package ekaweb_std_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/inaneverb/ekaweb/framework/std/v2"
+	"github.com/inaneverb/ekaweb/v2"
+)
+
+type (
+	// streamRequest is ekaweb.ClientRequestStream.
+	streamRequest struct {
+		body   io.Reader
+		length int64
+	}
+
+	// streamResponse is ekaweb.ClientResponseStream,
+	// that passes HTTP response to the callback.
+	streamResponse func(statusCode int, header http.Header, body io.Reader) error
+)
+
+func (r *streamRequest) Stream() (io.Reader, int64, error) { return r.body, r.length, nil }
+func (r *streamRequest) Data() ([]byte, error)             { panic("must not be called") }
+func (r *streamRequest) ContentType() string               { return "text/plain" }
+
+func (r streamResponse) FromData(int, []byte) error { panic("must not be called") }
+
+func (r streamResponse) FromStream(statusCode int, header http.Header, body io.Reader) error {
+	return r(statusCode, header, body)
+}
+
+// echoHandler responds with the framing (Content-Length or chunked)
+// and the body of HTTP request.
+var echoHandler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+	var body, _ = io.ReadAll(r.Body)
+	var framing = strconv.FormatInt(r.ContentLength, 10)
+	if len(r.TransferEncoding) > 0 {
+		framing = strings.Join(r.TransferEncoding, ",")
+	}
+	w.Header().Set("X-Framing", framing)
+	w.Header().Set("X-Content-Type", r.Header.Get(ekaweb.HeaderContentType))
+	_, _ = w.Write(body)
+})
+
+func TestClient_RequestStream(t *testing.T) {
+
+	var server = httptest.NewServer(echoHandler)
+	defer server.Close()
+
+	var client = ekaweb_std.NewClient(ekaweb.WithHostAddr(server.URL))
+
+	var tests = []struct {
+		name    string
+		body    string
+		length  int64
+		framing string
+	}{
+		{"Known", "known length", 12, "12"},
+		{"Unknown", "unknown length", -1, "chunked"},
+		{"Zero", "", 0, "0"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			var framing, contentType, body string
+			var resp = streamResponse(func(_ int, header http.Header, r io.Reader) error {
+				var data, err = io.ReadAll(r)
+				framing, contentType, body = header.Get("X-Framing"), header.Get("X-Content-Type"), string(data)
+				return err
+			})
+
+			// net/http detects the length of *strings.Reader itself, so hide it.
+
+			var req = streamRequest{io.MultiReader(strings.NewReader(tt.body)), tt.length}
+			var err = client.Do(context.Background(), ekaweb.MethodPost, "/", nil, &req, resp)
+
+			require.NoError(t, err)
+			require.Equal(t, tt.framing, framing)
+			require.Equal(t, "text/plain", contentType)
+			require.Equal(t, tt.body, body)
+		})
+	}
+}
+
+func TestClient_RequestStream_NotBuffered(t *testing.T) {
+
+	// The server receives the first part of the body,
+	// before the second one is written to the stream.
+
+	var received = make(chan string, 1)
+
+	var server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var first = make([]byte, len("first"))
+		var _, _ = io.ReadFull(r.Body, first)
+		received <- string(first)
+		var rest, _ = io.ReadAll(r.Body)
+		_, _ = w.Write(rest)
+	}))
+	defer server.Close()
+
+	var pr, pw = io.Pipe()
+
+	go func() {
+		_, _ = pw.Write([]byte("first"))
+		select {
+		case <-received:
+			_, _ = pw.Write([]byte("second"))
+			_ = pw.Close()
+		case <-time.After(5 * time.Second):
+			_ = pw.CloseWithError(errors.New("request stream is buffered"))
+		}
+	}()
+
+	var body string
+	var resp = streamResponse(func(_ int, _ http.Header, r io.Reader) error {
+		var data, err = io.ReadAll(r)
+		body = string(data)
+		return err
+	})
+
+	var client = ekaweb_std.NewClient(ekaweb.WithHostAddr(server.URL))
+	var err = client.Do(context.Background(), ekaweb.MethodPost, "/", nil, &streamRequest{pr, -1}, resp)
+
+	require.NoError(t, err)
+	require.Equal(t, "second", body)
+}
+
+func TestClient_ResponseStream(t *testing.T) {
+
+	// The server writes the second part of the body only after
+	// the client has read the first one, so it must be passed alive.
+
+	var clientRead = make(chan struct{})
+
+	var server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Test", "value")
+		w.WriteHeader(http.StatusAccepted)
+		_, _ = w.Write([]byte("first"))
+		w.(http.Flusher).Flush()
+
+		select {
+		case <-clientRead:
+			_, _ = w.Write([]byte("second"))
+		case <-time.After(5 * time.Second):
+			_, _ = w.Write([]byte("timeout"))
+		}
+	}))
+	defer server.Close()
+
+	var statusCode int
+	var header http.Header
+	var first, rest string
+
+	var resp = streamResponse(func(code int, h http.Header, r io.Reader) error {
+		statusCode, header = code, h
+
+		var buf = make([]byte, len("first"))
+		if _, err := io.ReadFull(r, buf); err != nil {
+			return err
+		}
+		first = string(buf)
+		close(clientRead)
+
+		var data, err = io.ReadAll(r)
+		rest = string(data)
+		return err
+	})
+
+	var client = ekaweb_std.NewClient(ekaweb.WithHostAddr(server.URL))
+	var err = client.Do(context.Background(), ekaweb.MethodGet, "/", nil, nil, resp)
+
+	require.NoError(t, err)
+	require.Equal(t, http.StatusAccepted, statusCode)
+	require.Equal(t, "value", header.Get("X-Test"))
+	require.Equal(t, "first", first)
+	require.Equal(t, "second", rest)
+}
@@ -0,0 +1,222 @@
+package ekaweb_std_test
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/inaneverb/ekaweb/framework/std/v2"
+	"github.com/inaneverb/ekaweb/v2"
+)
+
+type (
+	// formRequest is ekaweb.ClientRequest with URL encoded form.
+	formRequest string
+
+	// textResponse is ekaweb.ClientResponse, that keeps the body.
+	textResponse struct {
+		statusCode int
+		body       string
+	}
+
+	// debugLogger is ekaweb.Logger, that records debug messages.
+	// Other levels must not be used.
+	debugLogger struct {
+		ekaweb.Logger
+		messages []string
+	}
+)
+
+func (r formRequest) Data() ([]byte, error) { return []byte(r), nil }
+func (r formRequest) ContentType() string   { return "application/x-www-form-urlencoded" }
+
+func (r *textResponse) FromData(statusCode int, data []byte) error {
+	r.statusCode, r.body = statusCode, string(data)
+	return nil
+}
+
+func (l *debugLogger) Debug(format string, args ...any) {
+	l.messages = append(l.messages, fmt.Sprintf(format, args...))
+}
+
+// dumpHandler responds with the method, URI query, Content-Type,
+// User-Agent and the body of HTTP request.
+var dumpHandler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+	var body, _ = io.ReadAll(r.Body)
+	var ua, hasUA = r.Header["User-Agent"]
+	if !hasUA {
+		ua = []string{"<none>"}
+	}
+	_, _ = fmt.Fprintf(w, "%s ?%s [%s] [%s] %s",
+		r.Method, r.URL.RawQuery, r.Header.Get(ekaweb.HeaderContentType), ua[0], body)
+})
+
+func TestClient_Encoding(t *testing.T) {
+
+	var server = httptest.NewServer(dumpHandler)
+	defer server.Close()
+
+	var client = ekaweb_std.NewClient(
+		ekaweb.WithHostAddr(server.URL), ekaweb.WithUserAgent("test"))
+
+	var tests = []struct {
+		method   string
+		expected string
+	}{
+		{ekaweb.MethodGet, "GET ?a=1&b=2 [] [test] "},
+		{ekaweb.MethodDelete, "DELETE ?a=1&b=2 [] [test] "},
+		{ekaweb.MethodPost, "POST ? [application/x-www-form-urlencoded] [test] a=1&b=2"},
+		{ekaweb.MethodPatch, "PATCH ? [application/x-www-form-urlencoded] [test] a=1&b=2"},
+	}
+
+	for _, tt := range tests {
+		var resp textResponse
+		var err = client.Do(context.Background(), tt.method, "/path", nil, formRequest("a=1&b=2"), &resp)
+		require.NoError(t, err, tt.method)
+		require.Equal(t, tt.expected, resp.body, tt.method)
+	}
+
+	// No request at all.
+
+	var resp textResponse
+	var err = client.Do(context.Background(), ekaweb.MethodPost, "/", nil, nil, &resp)
+	require.NoError(t, err)
+	require.Equal(t, "POST ? [] [test] ", resp.body)
+}
+
+func TestClient_UserAgent(t *testing.T) {
+
+	var server = httptest.NewServer(dumpHandler)
+	defer server.Close()
+
+	var do = func(headers http.Header, options ...ekaweb.ClientOption) string {
+		var resp textResponse
+		options = append(options, ekaweb.WithHostAddr(server.URL))
+		var err = ekaweb_std.NewClient(options...).
+			Do(context.Background(), ekaweb.MethodGet, "/", headers, nil, &resp)
+		require.NoError(t, err)
+		return resp.body
+	}
+
+	require.Contains(t, do(nil), "[Go-http-client/")
+	require.Contains(t, do(nil, ekaweb.WithUserAgent("ekaweb")), "[ekaweb]")
+
+	// Empty User-Agent means no User-Agent header at all.
+
+	require.Contains(t, do(nil, ekaweb.WithUserAgent("")), "[<none>]")
+
+	// The header of the request overrides the option.
+
+	var headers = http.Header{ekaweb.HeaderUserAgent: {"custom"}}
+	require.Contains(t, do(headers, ekaweb.WithUserAgent("ekaweb")), "[custom]")
+}
+
+func TestClient_Interceptors(t *testing.T) {
+
+	var server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ekaweb.SendString(w, http.StatusOK, strings.Join(r.Header.Values("X-Trace"), ","))
+	}))
+	defer server.Close()
+
+	var trace = func(name string) ekaweb.ClientInterceptor {
+		return func(next ekaweb.Client) ekaweb.Client {
+			return ekaweb.ClientFunc(func(
+				ctx context.Context, method, path string, headers http.Header,
+				req ekaweb.ClientRequest, resp ekaweb.ClientResponse) error {
+
+				headers = headers.Clone()
+				if headers == nil {
+					headers = make(http.Header)
+				}
+				headers.Add("X-Trace", name)
+				return next.Do(ctx, method, path, headers, req, resp)
+			})
+		}
+	}
+
+	var client = ekaweb_std.NewClient(ekaweb.WithHostAddr(server.URL),
+		ekaweb.WithInterceptors(trace("outer")), ekaweb.WithInterceptors(trace("inner")))
+
+	var resp textResponse
+	var err = client.Do(context.Background(), ekaweb.MethodGet, "/", nil, nil, &resp)
+
+	require.NoError(t, err)
+	require.Equal(t, "outer,inner", resp.body)
+}
+
+func TestClient_Errors(t *testing.T) {
+
+	var server = httptest.NewServer(http.NotFoundHandler())
+	var log debugLogger
+
+	var addr = strings.Replace(server.URL, "http://", "http://user:secret@", 1)
+	var client = ekaweb_std.NewClient(ekaweb.WithHostAddr(addr), ekaweb.WithLogger(&log))
+
+	// Non 2xx HTTP status code is an error only if there's no response.
+
+	var err = client.Do(context.Background(), ekaweb.MethodGet, "/", nil, nil, nil)
+	require.ErrorContains(t, err, "HTTP status code is 404")
+
+	var resp textResponse
+	require.NoError(t, client.Do(context.Background(), ekaweb.MethodGet, "/", nil, nil, &resp))
+	require.Equal(t, http.StatusNotFound, resp.statusCode)
+	require.Empty(t, log.messages, "HTTP errors are not logged")
+
+	// Failed HTTP request is logged w/o the password.
+
+	server.Close()
+
+	err = client.Do(context.Background(), ekaweb.MethodGet, "/", nil, nil, &resp)
+	require.ErrorContains(t, err, "failed to perform HTTP request")
+	require.Len(t, log.messages, 1)
+	require.Contains(t, log.messages[0], "GET http://user:xxxxx@")
+	require.NotContains(t, log.messages[0], "secret")
+}
+
+func TestClient_Timeouts(t *testing.T) {
+
+	var server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/slow" {
+			time.Sleep(200 * time.Millisecond)
+		}
+
+		// The body is streamed much longer than the timeouts.
+
+		for i := 0; i < 5; i++ {
+			_, _ = io.WriteString(w, "x")
+			w.(http.Flusher).Flush()
+			time.Sleep(20 * time.Millisecond)
+		}
+	}))
+	defer server.Close()
+
+	var client = ekaweb_std.NewClient(ekaweb.WithHostAddr(server.URL),
+		ekaweb.WithTimeouts(50*time.Millisecond, 10*time.Millisecond))
+
+	var resp textResponse
+	var err = client.Do(context.Background(), ekaweb.MethodGet, "/", nil, nil, &resp)
+	require.NoError(t, err)
+	require.Equal(t, "xxxxx", resp.body)
+
+	// The read timeout limits waiting for the response headers.
+
+	err = client.Do(context.Background(), ekaweb.MethodGet, "/slow", nil, nil, &resp)
+	require.ErrorContains(t, err, "timeout awaiting response headers")
+
+	// The transport, passed by WithTransport(), is not modified.
+
+	var transport = new(http.Transport)
+
+	ekaweb_std.NewClient(ekaweb.WithTransport(transport),
+		ekaweb.WithTimeouts(time.Second, time.Second))
+
+	require.Zero(t, transport.ResponseHeaderTimeout)
+	require.Zero(t, transport.TLSHandshakeTimeout)
+}
@@ -4,11 +4,15 @@ go 1.21
 
 require (
 	github.com/inaneverb/ekacore/ekaunsafe/v4 v4.0.0
-	github.com/inaneverb/ekaweb/v2 v2.0.4
+	github.com/inaneverb/ekaweb/v2 v2.1.1
+	github.com/stretchr/testify v1.8.2
 )
 
 require (
 	github.com/cespare/xxhash/v2 v2.2.0 // indirect
+	github.com/davecgh/go-spew v1.1.1 // indirect
 	github.com/inaneverb/ekacore/ekaarr/v4 v4.0.0 // indirect
 	github.com/inaneverb/ekacore/ekaext/v4 v4.0.0 // indirect
+	github.com/pmezard/go-difflib v1.0.0 // indirect
+	gopkg.in/yaml.v3 v3.0.1 // indirect
 )
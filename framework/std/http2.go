@@ -0,0 +1,45 @@
+//go:build go1.24
+
+package ekaweb_std
+
+import (
+	"net/http"
+
+	"github.com/inaneverb/ekaweb/v2/private"
+)
+
+// http2Config returns http.HTTP2Config with the tuning from 'option'.
+func http2Config(option *ekaweb_private.ClientServerOptionHTTP2) *http.HTTP2Config {
+	return &http.HTTP2Config{
+		MaxConcurrentStreams: option.MaxConcurrentStreams,
+		MaxReadFrameSize:     option.MaxReadFrameSize,
+	}
+}
+
+// applyHTTP2Server enables HTTP/2 over TLS and, if requested, h2c
+// (cleartext HTTP/2 with prior knowledge) for 'server', keeping HTTP/1.
+func applyHTTP2Server(server *http.Server, option *ekaweb_private.ClientServerOptionHTTP2) {
+
+	var protocols http.Protocols
+	protocols.SetHTTP1(true)
+	protocols.SetHTTP2(true)
+	protocols.SetUnencryptedHTTP2(option.H2C)
+
+	server.Protocols = &protocols
+	server.HTTP2 = http2Config(option)
+}
+
+// applyHTTP2Transport enables HTTP/2 over TLS for 'transport'.
+// If h2c is requested, HTTP/1 is disabled, since net/http uses h2c
+// with prior knowledge only if HTTP/1 is not allowed.
+func applyHTTP2Transport(transport *http.Transport, option *ekaweb_private.ClientServerOptionHTTP2) {
+
+	var protocols http.Protocols
+	protocols.SetHTTP1(!option.H2C)
+	protocols.SetHTTP2(true)
+	protocols.SetUnencryptedHTTP2(option.H2C)
+
+	transport.Protocols = &protocols
+	transport.HTTP2 = http2Config(option)
+	transport.ForceAttemptHTTP2 = true
+}
@@ -0,0 +1,19 @@
+//go:build !go1.24
+
+package ekaweb_std
+
+import (
+	"net/http"
+
+	"github.com/inaneverb/ekaweb/v2/private"
+)
+
+// applyHTTP2Server does nothing: net/http before Go 1.24 has no HTTP/2
+// tuning and h2c w/o golang.org/x/net, but serves HTTP/2 over TLS anyway.
+func applyHTTP2Server(_ *http.Server, _ *ekaweb_private.ClientServerOptionHTTP2) {}
+
+// applyHTTP2Transport enables HTTP/2 over TLS for 'transport'.
+// Neither HTTP/2 tuning nor h2c is supported by net/http before Go 1.24.
+func applyHTTP2Transport(transport *http.Transport, _ *ekaweb_private.ClientServerOptionHTTP2) {
+	transport.ForceAttemptHTTP2 = true
+}
@@ -0,0 +1,158 @@
+//go:build go1.24
+
+package ekaweb_std_test
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/inaneverb/ekaweb/framework/std/v2"
+	"github.com/inaneverb/ekaweb/v2"
+)
+
+// protoResponse is ekaweb.ClientResponse, that keeps the response body.
+type protoResponse struct {
+	proto string
+}
+
+func (r *protoResponse) FromData(_ int, data []byte) error {
+	r.proto = string(data)
+	return nil
+}
+
+// protoHandler responds with HTTP protocol version of the request.
+var protoHandler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+	ekaweb.SendString(w, http.StatusOK, r.Proto)
+})
+
+// freeAddr returns a free TCP address on the loopback interface.
+func freeAddr(t *testing.T) string {
+	var ln, err = net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer ln.Close()
+	return ln.Addr().String()
+}
+
+// startServer starts a new std Server with 'options' on the free address
+// and returns the address.
+func startServer(t *testing.T, options ...ekaweb.ServerOption) string {
+
+	var addr = freeAddr(t)
+	options = append(options, ekaweb.WithListenAddr(addr), ekaweb.WithHandler(protoHandler))
+
+	var server = ekaweb_std.NewServer(options...)
+	require.NoError(t, server.AsyncStart())
+	t.Cleanup(func() { _ = server.Stop() })
+
+	return addr
+}
+
+// requestProto performs a request using 'client'
+// and returns HTTP protocol version, the server has received.
+func requestProto(t *testing.T, client ekaweb.Client) string {
+	var resp protoResponse
+	var err = client.Do(context.Background(), ekaweb.MethodGet, "/", nil, nil, &resp)
+	require.NoError(t, err)
+	return resp.proto
+}
+
+// writeCert writes a new self-signed certificate for 127.0.0.1
+// and its key to the PEM encoded files in 'dir'.
+func writeCert(t *testing.T, dir string) (certFile, keyFile string) {
+
+	var key, err = ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	var tmpl = x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "127.0.0.1"},
+		IPAddresses:  []net.IP{net.IPv4(127, 0, 0, 1)},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, &tmpl, &tmpl, &key.PublicKey, key)
+	require.NoError(t, err)
+
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+
+	certFile = filepath.Join(dir, "cert.pem")
+	keyFile = filepath.Join(dir, "key.pem")
+
+	var certPEM = pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	var keyPEM = pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+
+	require.NoError(t, os.WriteFile(certFile, certPEM, 0o600))
+	require.NoError(t, os.WriteFile(keyFile, keyPEM, 0o600))
+
+	return certFile, keyFile
+}
+
+func TestServer_H2C(t *testing.T) {
+
+	var addr = startServer(t, ekaweb.WithH2C(100, 1<<20))
+
+	var client = ekaweb_std.NewClient(
+		ekaweb.WithHostAddr("http://"+addr), ekaweb.WithH2C(0, 0))
+
+	require.Equal(t, "HTTP/2.0", requestProto(t, client))
+
+	// HTTP/1 is still served.
+
+	client = ekaweb_std.NewClient(ekaweb.WithHostAddr("http://" + addr))
+	require.Equal(t, "HTTP/1.1", requestProto(t, client))
+}
+
+func TestServer_H2C_Disabled(t *testing.T) {
+
+	var addr = startServer(t)
+
+	var client = ekaweb_std.NewClient(
+		ekaweb.WithHostAddr("http://"+addr), ekaweb.WithH2C(0, 0))
+
+	var err = client.Do(context.Background(), ekaweb.MethodGet, "/", nil, nil, new(protoResponse))
+	require.Error(t, err)
+}
+
+func TestServer_HTTP2(t *testing.T) {
+
+	var certFile, keyFile = writeCert(t, t.TempDir())
+	var addr = startServer(t,
+		ekaweb.WithTLS(certFile, keyFile, 0), ekaweb.WithHTTP2(100, 0))
+
+	var roots, err = ekaweb.LoadCertPool(certFile)
+	require.NoError(t, err)
+
+	// The transport, passed by WithTransport(), is not modified.
+
+	var transport = &http.Transport{TLSClientConfig: &tls.Config{RootCAs: roots}}
+
+	var client = ekaweb_std.NewClient(ekaweb.WithHostAddr("https://"+addr),
+		ekaweb.WithTransport(transport), ekaweb.WithHTTP2(0, 0))
+
+	require.Equal(t, "HTTP/2.0", requestProto(t, client))
+	require.Nil(t, transport.Protocols)
+
+	client = ekaweb_std.NewClient(ekaweb.WithHostAddr("https://"+addr),
+		ekaweb.WithTransport(transport))
+
+	require.Equal(t, "HTTP/1.1", requestProto(t, client))
+}
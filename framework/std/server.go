@@ -2,8 +2,10 @@ package ekaweb_std
 
 import (
 	"context"
+	"errors"
+	"log"
+	"net"
 	"net/http"
-	"strings"
 	"time"
 
 	"github.com/inaneverb/ekacore/ekaunsafe/v4"
@@ -12,21 +14,105 @@ import (
 )
 
 type Server struct {
-	origin *http.Server
+	origin          *http.Server
+	drainer         *ekaweb_private.ServerDrainer
+	done            *ekaweb_private.ServerDone
+	tls             *ekaweb_private.ServerTLS
+	tlsErr          error
+	listeners       ekaweb_private.ServerListeners
+	drainPeriod     time.Duration
+	shutdownTimeout time.Duration
 }
 
 ////////////////////////////////////////////////////////////////////////////////
 ////////////////////////////////////////////////////////////////////////////////
 
+// AsyncStart binds the listeners synchronously, so the errors like
+// "address already in use" are returned, and then serves HTTP requests
+// in the background. The serving error is reported by Wait().
 func (s *Server) AsyncStart() error {
-	go func() { _ = s.origin.ListenAndServe() }()
+
+	if s.tlsErr != nil {
+		return s.tlsErr
+	}
+
+	var listeners, err = s.listeners.Listen("tcp")
+	if err != nil {
+		return err
+	}
+
+	s.tls.Start(s.logTLSError)
+
+	for i, n := 0, len(listeners); i < n; i++ {
+		go s.serve(listeners[i])
+	}
+
 	return nil
 }
 
 func (s *Server) Stop() error {
-	var ctx, cancelFunc = context.WithTimeout(context.TODO(), 5*time.Second)
+	var ctx, cancelFunc = context.WithTimeout(context.Background(), s.shutdownTimeout)
 	defer cancelFunc()
-	return s.origin.Shutdown(ctx)
+	return s.StopContext(ctx)
+}
+
+// StopContext gracefully shuts down the server. Keep-alive connections
+// are closed after their current HTTP requests right from the start
+// of the drain period. If 'ctx' is done before all HTTP requests are served,
+// the remaining connections are closed forcibly.
+func (s *Server) StopContext(ctx context.Context) error {
+
+	s.origin.SetKeepAlivesEnabled(false)
+
+	var err = s.drainer.Drain(ctx, s.drainPeriod)
+	s.drainer.GoAway()
+
+	if errShutdown := s.origin.Shutdown(ctx); errShutdown != nil {
+		_ = s.origin.Close()
+		if err == nil {
+			err = errShutdown
+		}
+	}
+
+	s.tls.Stop()
+	s.done.Finish(nil)
+	return err
+}
+
+func (s *Server) Done() <-chan struct{} {
+	return s.done.Done()
+}
+
+func (s *Server) Wait() error {
+	return s.done.Wait()
+}
+
+// serve serves HTTP(S) requests from 'ln' until the server is stopped.
+// If serving fails, the whole server is closed (including other listeners),
+// so it's not left half-alive after Wait() is returned.
+func (s *Server) serve(ln net.Listener) {
+
+	var err error
+	if s.tls != nil {
+		err = s.origin.ServeTLS(ln, "", "")
+	} else {
+		err = s.origin.Serve(ln)
+	}
+
+	if !errors.Is(err, http.ErrServerClosed) {
+		_ = s.origin.Close()
+		s.tls.Stop()
+		s.done.Finish(err)
+	}
+}
+
+// logTLSError logs the error of reloading TLS certificate.
+func (s *Server) logTLSError(err error) {
+	if s.origin.ErrorLog != nil {
+		s.origin.ErrorLog.Printf("ekaweb: %s", err.Error())
+	} else {
+		log.Printf("ekaweb: %s", err.Error())
+	}
 }
 
 ////////////////////////////////////////////////////////////////////////////////
@@ -36,10 +122,20 @@ func NewServer(options ...ekaweb.ServerOption) ekaweb.Server {
 
 	var server Server
 	server.origin = new(http.Server)
+	server.drainer = ekaweb_private.NewServerDrainer()
+	server.done = ekaweb_private.NewServerDone()
+	server.shutdownTimeout = 5 * time.Second
+
+	server.origin.BaseContext = func(_ net.Listener) context.Context {
+		return server.drainer.WithContext(context.Background())
+	}
 
 	server.origin.IdleTimeout = 10 * time.Second
 	server.origin.SetKeepAlivesEnabled(true)
 
+	var tlsOption *ekaweb_private.ServerOptionTLS
+	var authOption *ekaweb_private.ServerOptionClientAuth
+
 	for i, n := 0, len(options); i < n; i++ {
 		if ekaunsafe.UnpackInterface(options[i]).Word == nil {
 			continue
@@ -54,15 +150,34 @@ func NewServer(options ...ekaweb.ServerOption) ekaweb.Server {
 				server.origin.IdleTimeout = option.Duration
 			}
 
+		case *ekaweb_private.ServerOptionShutdown:
+			server.drainPeriod = max(option.DrainPeriod, 0)
+			if option.Timeout > 0 {
+				server.shutdownTimeout = option.Timeout
+			}
+
+		case *ekaweb_private.ServerOptionTLS:
+			tlsOption = option
+
+		case *ekaweb_private.ServerOptionClientAuth:
+			authOption = option
+
 		case *ekaweb_private.ServerOptionHandler:
 			if ekaunsafe.UnpackInterface(option.Handler).Word != nil {
 				server.origin.Handler = option.Handler
 			}
 
 		case *ekaweb_private.ServerOptionListenAddr:
-			if option.Addr = strings.TrimSpace(option.Addr); option.Addr != "" {
-				server.origin.Addr = option.Addr
-			}
+			server.listeners.AddAddr(option)
+
+		case *ekaweb_private.ServerOptionListener:
+			server.listeners.AddListener(option)
+
+		case *ekaweb_private.ServerOptionSystemd:
+			server.listeners.EnableSystemd()
+
+		case *ekaweb_private.ClientServerOptionHTTP2:
+			applyHTTP2Server(server.origin, option)
 
 		case *ekaweb_private.ClientServerOptionLogger:
 			// UNSUPPORTED
@@ -77,5 +192,17 @@ func NewServer(options ...ekaweb.ServerOption) ekaweb.Server {
 		}
 	}
 
+	server.tls, server.tlsErr = ekaweb_private.NewServerTLS(tlsOption, authOption)
+	if server.tls != nil {
+		server.origin.TLSConfig = server.tls.Config
+	}
+
+	switch {
+	case server.listeners.IsEmpty() && server.tls != nil:
+		server.listeners.AddAddr(&ekaweb_private.ServerOptionListenAddr{Addr: ":https"})
+	case server.listeners.IsEmpty():
+		server.listeners.AddAddr(&ekaweb_private.ServerOptionListenAddr{Addr: ":http"})
+	}
+
 	return &server
 }
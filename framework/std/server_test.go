@@ -0,0 +1,52 @@
+package ekaweb_std_test
+
+import (
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/inaneverb/ekaweb/framework/std/v2"
+	"github.com/inaneverb/ekaweb/v2"
+)
+
+// brokenListener is net.Listener, that fails to accept connections.
+type brokenListener struct {
+	net.Listener
+}
+
+var errBrokenListener = errors.New("broken listener")
+
+func (l brokenListener) Accept() (net.Conn, error) {
+	return nil, errBrokenListener
+}
+
+func TestServer_ServeError(t *testing.T) {
+
+	var ln, err = net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	broken, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer broken.Close()
+
+	var server = ekaweb_std.NewServer(
+		ekaweb.WithListener(ln), ekaweb.WithListener(brokenListener{broken}))
+
+	require.NoError(t, server.AsyncStart())
+
+	select {
+	case <-server.Done():
+	case <-time.After(time.Second):
+		t.Fatal("server must be stopped, if any listener fails")
+	}
+
+	require.ErrorIs(t, server.Wait(), errBrokenListener)
+
+	// Other listeners are closed as well.
+
+	_, err = net.DialTimeout("tcp", ln.Addr().String(), time.Second)
+	require.Error(t, err)
+}
@@ -0,0 +1,37 @@
+package ekaweb
+
+import (
+	"context"
+
+	"github.com/inaneverb/ekaweb/v2/private"
+)
+
+type (
+	// _ClientURLTemplateKey is a key for context.Context
+	// to store URL template of the outgoing HTTP request.
+	_ClientURLTemplateKey struct{}
+)
+
+// WrapClient wraps given Client by interceptors. The first interceptor
+// is the outermost one: it's called first and it sees the final result.
+// Nil interceptors are ignored. It works with any Client implementation.
+func WrapClient(client Client, interceptors ...ClientInterceptor) Client {
+	return ekaweb_private.WrapClient(client, interceptors...)
+}
+
+// ClientWithURLTemplate returns a copy of 'ctx', that holds URL template
+// (like "/users/{id}") of the outgoing HTTP request, that is performed
+// using that context.Context. Interceptors (e.g. the tracing ones)
+// use it instead of the real path, preventing high cardinality.
+func ClientWithURLTemplate(ctx context.Context, template string) context.Context {
+	return context.WithValue(ctx, (*_ClientURLTemplateKey)(nil), template)
+}
+
+// ClientURLTemplate returns URL template, stored by ClientWithURLTemplate(),
+// or 'path' if there's no such template.
+func ClientURLTemplate(ctx context.Context, path string) string {
+	if template, _ := ctx.Value((*_ClientURLTemplateKey)(nil)).(string); template != "" {
+		return template
+	}
+	return path
+}
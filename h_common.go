@@ -91,37 +91,69 @@ func CopyHeaders(to, from http.Header) http.Header {
 	return HeadersMerge(to, from, true)
 }
 
-//func Accept(r *http.Request, offer string) bool {
-//	panic("TODO: Not implemented yet")
-//}
-//
-//func Accepts(r *http.Request, offers ...string) string {
-//	panic("TODO: Not implemented yet")
-//}
-//
-//func AcceptCharset(r *http.Request, offer string) bool {
-//	panic("TODO: Not implemented yet")
-//}
-//
-//func AcceptsCharsets(r *http.Request, offers ...string) string {
-//	panic("TODO: Not implemented yet")
-//}
-//
-//func AcceptEncoding(r *http.Request, offer string) bool {
-//	panic("TODO: Not implemented yet")
-//}
+// Accept reports whether given 'offer' (MIME type) is acceptable
+// by the client, according to the Accept HTTP header of given http.Request.
+// If there's no Accept header, any MIME type is acceptable.
+func Accept(r *http.Request, offer string) bool {
+	return Accepts(r, offer) != ""
+}
+
+// Accepts returns the best offer (MIME type) from given 'offers', according to
+// the Accept HTTP header of given http.Request (RFC 9110, section 12.5.1).
 //
-//func AcceptsEncodings(r *http.Request, offers ...string) string {
-//	panic("TODO: Not implemented yet")
-//}
+// Q-values, wildcards (like "*/*" or "text/*") and media type parameters
+// (like "text/html;level=1") are supported. The most specific media range
+// determines the quality of each offer. If qualities are equal,
+// the offer with more specific match wins, then the first one.
 //
-//func AcceptLanguage(r *http.Request, offer string) bool {
-//	panic("TODO: Not implemented yet")
-//}
+// If there's no Accept header, the first offer is returned.
+// If none of offers is acceptable, an empty string is returned.
+func Accepts(r *http.Request, offers ...string) string {
+	return ekaweb_private.NegotiateMediaType(r.Header.Values(HeaderAccept), offers)
+}
+
+// AcceptCharset reports whether given 'offer' (charset) is acceptable
+// by the client, according to the Accept-Charset HTTP header.
+func AcceptCharset(r *http.Request, offer string) bool {
+	return AcceptsCharsets(r, offer) != ""
+}
+
+// AcceptsCharsets is the same as just Accepts(), but works with charsets
+// and Accept-Charset HTTP header (RFC 9110, section 12.5.2).
+func AcceptsCharsets(r *http.Request, offers ...string) string {
+	return ekaweb_private.NegotiateCharset(r.Header.Values(HeaderAcceptCharset), offers)
+}
+
+// AcceptEncoding reports whether given 'offer' (content coding) is acceptable
+// by the client, according to the Accept-Encoding HTTP header.
+func AcceptEncoding(r *http.Request, offer string) bool {
+	return AcceptsEncodings(r, offer) != ""
+}
+
+// AcceptsEncodings is the same as just Accepts(), but works with content
+// codings and Accept-Encoding HTTP header (RFC 9110, section 12.5.3).
 //
-//func AcceptsLanguages(r *http.Request, offers ...string) string {
-//	panic("TODO: Not implemented yet")
-//}
+// NOTE.
+// The "identity" coding is always acceptable unless it's explicitly excluded
+// by "identity;q=0" or "*;q=0", but any explicitly mentioned coding
+// is preferred over it. If Accept-Encoding header is present, but empty,
+// only "identity" is acceptable.
+func AcceptsEncodings(r *http.Request, offers ...string) string {
+	return ekaweb_private.NegotiateEncoding(r.Header.Values(HeaderAcceptEncoding), offers)
+}
+
+// AcceptLanguage reports whether given 'offer' (language tag) is acceptable
+// by the client, according to the Accept-Language HTTP header.
+func AcceptLanguage(r *http.Request, offer string) bool {
+	return AcceptsLanguages(r, offer) != ""
+}
+
+// AcceptsLanguages is the same as just Accepts(), but works with language tags
+// and Accept-Language HTTP header (RFC 9110, section 12.5.4).
+// Language ranges are matched by prefix, so "en" matches "en-US" offer.
+func AcceptsLanguages(r *http.Request, offers ...string) string {
+	return ekaweb_private.NegotiateLanguage(r.Header.Values(HeaderAcceptLanguage), offers)
+}
 
 ////////////////////////////////////////////////////////////////////////////////
 ///// HTTP Request user key value storage methods //////////////////////////////
@@ -246,17 +278,39 @@ func RoutePath(r *http.Request) string {
 ///// HTTP Response generators /////////////////////////////////////////////////
 ////////////////////////////////////////////////////////////////////////////////
 
-// SendEncoded is the same as just SendEncodedWithMIME(), but uses JSON MIME
+// SendEncoded encodes given 'obj' with codec that is stored inside
+// http.Request's context.Context, finalizing sending HTTP response.
+//
+// If codecs were registered by WithCodecs(), the codec is chosen based on
+// the Accept HTTP header of given http.Request, and its MIME type is sent.
+// If there's no acceptable codec, ErrNotAcceptable is applied
+// (see ErrorApply()) and nothing is sent.
+//
+// Otherwise, it's the same as just SendEncodedWithMIME(), but uses JSON MIME
 // no matter what codec is stored (assuming, that ~90% users uses JSON).
 // Thus, MIMEApplicationJSONCharsetUTF8 constant will be used.
-//
-// WARNING!
-// YOU MAY GET UNEXPECTED BEHAVIOUR IF YOUR STORED CODEC WITH ANY PROBABILITY
-// COULD BE SOMETHING ELSE BUT JSON. SendEncodedWithMIME() is recommended.
 func SendEncoded(
 	w http.ResponseWriter, r *http.Request, statusCode int, obj any) {
 
-	SendEncodedWithMIME(w, r, statusCode, MIMEApplicationJSONCharsetUTF8, obj)
+	var accept = r.Header.Values(HeaderAccept)
+	var mimeType, encoderGetter, err = ekaweb_private.NegotiateEncoder(r.Context(), accept)
+
+	switch {
+	case err != nil:
+		ErrorApply(r, err)
+		return
+
+	case mimeType == "":
+		SendEncodedWithMIME(w, r, statusCode, MIMEApplicationJSONCharsetUTF8, obj)
+		return
+	}
+
+	w.Header().Add(HeaderVary, HeaderAccept)
+	SendRaw(w, statusCode, mimeType, nil)
+
+	if err = encoderGetter(w).Encode(obj); err != nil {
+		ErrorApply(r, err)
+	}
 }
 
 // SendEncodedWithMIME encodes given 'obj' with codec that is stored inside
@@ -282,6 +336,28 @@ func SendEncodedWithMIME(
 	}
 }
 
+// ScanEncoded decodes the body of given http.Request to the 'to'
+// with codec that is stored inside http.Request's context.Context.
+//
+// If codecs were registered by WithCodecs(), the codec is chosen based on
+// the Content-Type HTTP header of given http.Request. If there's no suitable
+// codec, ErrUnsupportedMediaType is applied (see ErrorApply()) and returned.
+// If Content-Type header is absent, the default (first) codec is used.
+//
+// Decoding errors are returned as is, it's up to you whether apply them.
+// An empty body is not an error, 'to' stays untouched in that case.
+func ScanEncoded(r *http.Request, to any) error {
+
+	var contentType = r.Header.Get(HeaderContentType)
+	var err = ekaweb_private.DecodeStreamWithMIME(r.Context(), contentType, r.Body, to)
+
+	if err == ekaweb_private.ErrUnsupportedMediaType {
+		ErrorApply(r, err)
+	}
+
+	return err
+}
+
 // SendJSON is just the legacy named version of SendEncoded().
 // Deprecated: Use SendEncoded() instead.
 func SendJSON(w http.ResponseWriter, r *http.Request, statusCode int, obj any) {
@@ -6,6 +6,18 @@ import (
 	"github.com/inaneverb/ekaweb/v2/private"
 )
 
+var (
+	// ErrNotAcceptable is applied by SendEncoded(), when there's no codec
+	// registered by WithCodecs(), that can encode a response with MIME type,
+	// acceptable by the client. You may want to respond with 406 HTTP status.
+	ErrNotAcceptable = ekaweb_private.ErrNotAcceptable
+
+	// ErrUnsupportedMediaType is applied by ScanEncoded(), when there's
+	// no codec registered by WithCodecs(), that can decode a request body
+	// of its MIME type. You may want to respond with 415 HTTP status.
+	ErrUnsupportedMediaType = ekaweb_private.ErrUnsupportedMediaType
+)
+
 func ErrorGet(r *http.Request) error {
 	return ekaweb_private.UkvsGetUserError(r.Context())
 }
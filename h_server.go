@@ -0,0 +1,69 @@
+package ekaweb
+
+import (
+	"context"
+	"crypto/x509"
+	"net/http"
+
+	"github.com/inaneverb/ekaweb/v2/private"
+)
+
+// OnServerShutdown registers a callback, that is called when the Server,
+// that serves HTTP request with 'ctx', is going away (see Server.StopContext()).
+// It's intended for hijacked connections (e.g. WebSocket), that are not
+// tracked by the Server itself: the callback should close the connection
+// gracefully (e.g. sending close frame with "going away" close code).
+//
+// The returned function unregisters the callback and must be called,
+// when the connection is closed. Returns no-op function, if there's no Server.
+func OnServerShutdown(ctx context.Context, cb func()) (cancel func()) {
+	if d := ekaweb_private.ServerDrainerFromContext(ctx); d != nil && cb != nil {
+		return d.OnShutdown(cb)
+	}
+	return func() {}
+}
+
+// IsServerDraining reports whether the Server, that serves HTTP request
+// with 'ctx', is shutting down gracefully (see Server.StopContext()).
+func IsServerDraining(ctx context.Context) bool {
+	var d = ekaweb_private.ServerDrainerFromContext(ctx)
+	return d != nil && d.IsDraining()
+}
+
+// ReadinessHandler returns a Handler, that responds with 200 status code
+// while the Server is ready and with 503 once it starts draining
+// (see WithShutdown()). Use it as a readiness probe of the load balancer.
+func ReadinessHandler() Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if IsServerDraining(r.Context()) {
+			SendString(w, http.StatusServiceUnavailable, "draining")
+		} else {
+			SendString(w, http.StatusOK, "ready")
+		}
+	})
+}
+
+// ClientCertificate returns the verified client certificate
+// of HTTP request 'r', if mutual TLS is enabled (see WithClientAuth()).
+// Returns nil, if the client has not presented a certificate.
+func ClientCertificate(r *http.Request) *x509.Certificate {
+	if r.TLS == nil || len(r.TLS.VerifiedChains) == 0 ||
+		len(r.TLS.VerifiedChains[0]) == 0 {
+		return nil
+	}
+	return r.TLS.VerifiedChains[0][0]
+}
+
+// NewCertReloader loads the certificate from PEM encoded 'certFile',
+// 'keyFile' and returns CertReloader, that reloads it when the files
+// are changed. Use it with WithTLSConfig() if WithTLS() is not enough
+// (e.g. to serve multiple certificates).
+func NewCertReloader(certFile, keyFile string) (*CertReloader, error) {
+	return ekaweb_private.NewCertReloader(certFile, keyFile)
+}
+
+// LoadCertPool returns x509.CertPool with PEM encoded certificates
+// from 'files' (e.g. CA certificates for WithClientAuth()).
+func LoadCertPool(files ...string) (*x509.CertPool, error) {
+	return ekaweb_private.LoadCertPool(files...)
+}
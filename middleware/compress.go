@@ -0,0 +1,430 @@
+package ekaweb_middleware
+
+import (
+	"bufio"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/inaneverb/ekaweb/v2"
+	"github.com/inaneverb/ekaweb/v2/private"
+)
+
+type (
+	// Compressor is a streaming compressor, that is used by Compress()
+	// middleware to compress HTTP response body. *gzip.Writer, *zlib.Writer,
+	// brotli's *Writer and zstd's *Encoder are compatible with this interface.
+	Compressor interface {
+		io.WriteCloser
+		Flush() error
+		Reset(w io.Writer)
+	}
+
+	// CompressorGetter returns a new Compressor that writes to the given
+	// io.Writer. Compressors are pooled, so it's called only if there's
+	// no free Compressor to reuse.
+	CompressorGetter = func(w io.Writer) Compressor
+
+	// CompressOption is a callback that allows to modify Compress() middleware
+	// under its construction.
+	CompressOption func(c *_Compress)
+
+	_Compress struct {
+		encodings []_CompressEncoding
+		offers    []string
+		level     int
+		minLength int
+		skipMIME  []string
+	}
+
+	_CompressEncoding struct {
+		name   string
+		getter CompressorGetter
+		pool   *sync.Pool
+	}
+
+	// _CompressWriter wraps original http.ResponseWriter, buffering first
+	// bytes of HTTP response body to decide whether to compress it.
+	_CompressWriter struct {
+		orig       http.ResponseWriter
+		r          *http.Request
+		c          *_Compress
+		encoding   *_CompressEncoding
+		compressor Compressor
+		buf        []byte
+		statusCode int
+		decided    bool
+		hijacked   bool
+	}
+)
+
+// CompressEncodingIdentity is "no encoding" content coding.
+const CompressEncodingIdentity = "identity"
+
+// gCompressSkipMIME is a list of MIME types, which are already compressed
+// and there's no reason to compress them again.
+// The entries, ending with "/", are prefixes of MIME types.
+var gCompressSkipMIME = []string{
+	"image/png", "image/jpeg", "image/gif", "image/webp", "image/avif",
+	"video/", "audio/", "font/woff", "font/woff2",
+	"application/zip", "application/gzip", "application/x-gzip",
+	"application/zstd", "application/x-bzip2", "application/x-xz",
+	"application/x-7z-compressed", "application/x-rar-compressed",
+	"application/pdf", "application/wasm",
+}
+
+// Compress returns a new HTTP middleware, that compresses HTTP response body
+// using the content coding, that is the best for Accept-Encoding HTTP header.
+// By default, "gzip" and "deflate" are supported (in that priority).
+// Use WithCompressEncoding() to add others (like "br" or "zstd").
+//
+// The response is not compressed if:
+//   - It's smaller than minimum length (see WithCompressMinLength());
+//   - It's MIME type is already compressed (images, videos, archives, etc);
+//   - It already has Content-Encoding HTTP header;
+//   - It has no body (HEAD request, 1xx, 204, 304 HTTP status codes);
+//   - The connection is hijacked.
+//
+// Flushing (http.Flusher) forces the decision, ignoring minimum length,
+// so streaming responses (SendStream(), SSE) are compressed chunk by chunk.
+// Has no error check before.
+func Compress(options ...CompressOption) ekaweb.Middleware {
+
+	var c = _Compress{
+		level:     gzip.DefaultCompression,
+		minLength: 1024,
+		skipMIME:  gCompressSkipMIME,
+	}
+
+	for _, option := range options {
+		if option != nil {
+			option(&c)
+		}
+	}
+
+	var level = c.level
+	c.addEncoding("gzip", func(w io.Writer) Compressor {
+		var gw, _ = gzip.NewWriterLevel(w, level)
+		return gw
+	}, false)
+	c.addEncoding("deflate", func(w io.Writer) Compressor {
+		var zw, _ = zlib.NewWriterLevel(w, level)
+		return zw
+	}, false)
+
+	for i := range c.encodings {
+		c.offers = append(c.offers, c.encodings[i].name)
+	}
+	c.offers = append(c.offers, CompressEncodingIdentity)
+
+	var m = func(next ekaweb.Handler) ekaweb.Handler {
+		return ekaweb.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			c.serveHTTP(next, w, r)
+		})
+	}
+
+	return ekaweb.MiddlewareFuncNoErrorCheck(m)
+}
+
+// WithCompressEncoding registers a new content coding with given 'name'
+// (like "br" or "zstd") or replaces the existed one (like "gzip").
+// Encodings, registered by this option, have higher priority than built-in,
+// and the earlier it's registered, the higher priority it has.
+func WithCompressEncoding(name string, getter CompressorGetter) CompressOption {
+	return func(c *_Compress) {
+		if name = strings.ToLower(strings.TrimSpace(name)); name != "" &&
+			name != CompressEncodingIdentity && getter != nil {
+
+			c.addEncoding(name, getter, true)
+		}
+	}
+}
+
+// WithCompressLevel sets a compression level for built-in "gzip" and
+// "deflate" encodings. Must be in range [flate.HuffmanOnly..flate.BestCompression].
+// Invalid level is ignored. Default: flate.DefaultCompression.
+func WithCompressLevel(level int) CompressOption {
+	return func(c *_Compress) {
+		if level >= flate.HuffmanOnly && level <= flate.BestCompression {
+			c.level = level
+		}
+	}
+}
+
+// WithCompressMinLength sets a minimum length of HTTP response body
+// to be compressed. Smaller bodies are sent as is. Default: 1024.
+func WithCompressMinLength(minLength int) CompressOption {
+	return func(c *_Compress) {
+		if minLength >= 0 {
+			c.minLength = minLength
+		}
+	}
+}
+
+// WithCompressSkipMIMETypes adds MIME types, that must not be compressed.
+// Types ending with "/" (like "image/") are treated as a prefix.
+func WithCompressSkipMIMETypes(mimeTypes ...string) CompressOption {
+	return func(c *_Compress) {
+		var skipMIME = make([]string, len(c.skipMIME), len(c.skipMIME)+len(mimeTypes))
+		copy(skipMIME, c.skipMIME)
+
+		for _, mimeType := range mimeTypes {
+			if mimeType = strings.ToLower(strings.TrimSpace(mimeType)); mimeType != "" {
+				skipMIME = append(skipMIME, mimeType)
+			}
+		}
+
+		c.skipMIME = skipMIME
+	}
+}
+
+////////////////////////////////////////////////////////////////////////////////
+///// _CompressWriter //////////////////////////////////////////////////////////
+////////////////////////////////////////////////////////////////////////////////
+
+func (cw *_CompressWriter) Header() http.Header {
+	return cw.orig.Header()
+}
+
+func (cw *_CompressWriter) WriteHeader(statusCode int) {
+	switch {
+	case cw.statusCode != 0 || cw.hijacked:
+		return // superfluous call, the same as http.ResponseWriter does
+
+	case statusCode == http.StatusSwitchingProtocols:
+		cw.statusCode, cw.decided = statusCode, true
+		cw.orig.WriteHeader(statusCode)
+
+	case statusCode >= 100 && statusCode < 200:
+		cw.orig.WriteHeader(statusCode) // informational, the final is the next
+
+	default:
+		cw.statusCode = statusCode
+	}
+}
+
+func (cw *_CompressWriter) Write(b []byte) (int, error) {
+
+	if !cw.decided {
+		if cw.statusCode == 0 {
+			cw.statusCode = http.StatusOK
+		}
+		if len(cw.buf)+len(b) < cw.c.minLength {
+			cw.buf = append(cw.buf, b...)
+			return len(b), nil
+		}
+		if err := cw.decide(b, true); err != nil {
+			return 0, err
+		}
+		return len(b), nil
+	}
+
+	if cw.compressor != nil {
+		return cw.compressor.Write(b)
+	}
+	return cw.orig.Write(b)
+}
+
+func (cw *_CompressWriter) Flush() {
+
+	if !cw.decided {
+		if cw.statusCode == 0 {
+			cw.statusCode = http.StatusOK
+		}
+		_ = cw.decide(nil, true)
+	}
+	if cw.compressor != nil {
+		_ = cw.compressor.Flush()
+	}
+	if flusher, ok := cw.orig.(http.Flusher); ok && !cw.hijacked {
+		flusher.Flush()
+	}
+}
+
+func (cw *_CompressWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+
+	var hijacker, ok = cw.orig.(http.Hijacker)
+	if !ok {
+		return nil, nil, http.ErrNotSupported
+	}
+
+	var conn, rw, err = hijacker.Hijack()
+	if err == nil {
+		cw.hijacked, cw.decided = true, true
+	}
+
+	return conn, rw, err
+}
+
+// Unwrap returns the original http.ResponseWriter.
+// It's used by http.ResponseController.
+func (cw *_CompressWriter) Unwrap() http.ResponseWriter {
+	return cw.orig
+}
+
+////////////////////////////////////////////////////////////////////////////////
+///// PRIVATE METHODS //////////////////////////////////////////////////////////
+////////////////////////////////////////////////////////////////////////////////
+
+func (c *_Compress) addEncoding(
+	name string, getter CompressorGetter, override bool) {
+
+	for i := range c.encodings {
+		if c.encodings[i].name == name {
+			if override {
+				c.encodings[i].getter = getter
+			}
+			return
+		}
+	}
+
+	c.encodings = append(c.encodings, _CompressEncoding{name, getter, new(sync.Pool)})
+}
+
+func (c *_Compress) serveHTTP(
+	next ekaweb.Handler, w http.ResponseWriter, r *http.Request) {
+
+	w.Header().Add(ekaweb.HeaderVary, ekaweb.HeaderAcceptEncoding)
+
+	if r.Method == ekaweb.MethodHead || len(r.Header.Values(ekaweb.HeaderAcceptEncoding)) == 0 {
+		next.ServeHTTP(w, r)
+		return
+	}
+
+	var encoding *_CompressEncoding
+	var chosen = ekaweb.AcceptsEncodings(r, c.offers...)
+
+	for i := range c.encodings {
+		if c.encodings[i].name == chosen {
+			encoding = &c.encodings[i]
+			break
+		}
+	}
+
+	if encoding == nil {
+		next.ServeHTTP(w, r)
+		return
+	}
+
+	var cw = _CompressWriter{orig: w, r: r, c: c, encoding: encoding}
+	defer cw.close()
+
+	next.ServeHTTP(&cw, r)
+}
+
+// shouldCompress reports whether HTTP response body must be compressed,
+// based on HTTP status code, headers and the first bytes of the body.
+func (cw *_CompressWriter) shouldCompress(firstBytes []byte) bool {
+
+	switch cw.statusCode {
+	case http.StatusNoContent, http.StatusNotModified:
+		return false
+	}
+
+	var h = cw.orig.Header()
+	if h.Get(ekaweb.HeaderContentEncoding) != "" {
+		return false
+	}
+
+	if ekaweb_private.UkvsIsConnectionHijacked(cw.r.Context()) {
+		cw.hijacked = true
+		return false
+	}
+
+	var contentType = h.Get(ekaweb.HeaderContentType)
+	if contentType == "" {
+		if len(firstBytes) == 0 {
+			return true // stream w/o body yet, we know nothing
+		}
+		contentType = http.DetectContentType(firstBytes)
+		h.Set(ekaweb.HeaderContentType, contentType)
+	}
+
+	contentType, _, _ = strings.Cut(contentType, ";")
+	contentType = strings.ToLower(strings.TrimSpace(contentType))
+
+	for _, skipMIME := range cw.c.skipMIME {
+		if skipMIME == contentType ||
+			strings.HasSuffix(skipMIME, "/") && strings.HasPrefix(contentType, skipMIME) {
+
+			return false
+		}
+	}
+
+	return true
+}
+
+// decide decides whether to compress HTTP response body, writing HTTP headers
+// and buffered data (and 'b' if presented).
+// If 'compress' is false, the body is sent as is anyway.
+func (cw *_CompressWriter) decide(b []byte, compress bool) error {
+
+	cw.decided = true
+
+	var firstBytes = cw.buf
+	if len(firstBytes) == 0 {
+		firstBytes = b
+	}
+
+	if compress = compress && cw.shouldCompress(firstBytes); compress {
+		var h = cw.orig.Header()
+		h.Del(ekaweb.HeaderContentLength)
+		h.Del(ekaweb.HeaderAcceptRanges)
+		h.Set(ekaweb.HeaderContentEncoding, cw.encoding.name)
+
+		if compressor, ok := cw.encoding.pool.Get().(Compressor); ok {
+			compressor.Reset(cw.orig)
+			cw.compressor = compressor
+		} else {
+			cw.compressor = cw.encoding.getter(cw.orig)
+		}
+	}
+
+	if cw.hijacked {
+		return nil
+	}
+
+	cw.orig.WriteHeader(cw.statusCode)
+
+	var w io.Writer = cw.orig
+	if cw.compressor != nil {
+		w = cw.compressor
+	}
+
+	for _, data := range [2][]byte{cw.buf, b} {
+		if len(data) == 0 {
+			continue
+		}
+		if _, err := w.Write(data); err != nil {
+			return err
+		}
+	}
+
+	cw.buf = nil
+	return nil
+}
+
+// close finalizes HTTP response, sending buffered data (if any)
+// and returning used Compressor back to the pool.
+func (cw *_CompressWriter) close() {
+
+	switch {
+	case cw.hijacked:
+		return
+
+	case !cw.decided && cw.statusCode != 0:
+		_ = cw.decide(nil, len(cw.buf) >= cw.c.minLength && len(cw.buf) != 0)
+	}
+
+	if cw.compressor != nil {
+		_ = cw.compressor.Close()
+		cw.compressor.Reset(io.Discard)
+		cw.encoding.pool.Put(cw.compressor)
+		cw.compressor = nil
+	}
+}
@@ -0,0 +1,252 @@
+package ekaweb_middleware_test
+
+import (
+	"bufio"
+	"compress/gzip"
+	"compress/zlib"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/inaneverb/ekaweb/v2"
+	"github.com/inaneverb/ekaweb/v2/middleware"
+	"github.com/inaneverb/ekaweb/v2/private"
+)
+
+var compressBody = strings.Repeat("Hello, World! ", 100)
+
+// bodyHandler returns http.Handler, that responds with 'body'
+// and 'contentType' (if any) using 'statusCode'.
+func bodyHandler(statusCode int, contentType, body string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		if contentType != "" {
+			w.Header().Set(ekaweb.HeaderContentType, contentType)
+		}
+		w.WriteHeader(statusCode)
+		_, _ = io.WriteString(w, body)
+	})
+}
+
+func newCompressRequest(method string, acceptEncoding ...string) *http.Request {
+	var r = httptest.NewRequest(method, "/", nil)
+	for _, value := range acceptEncoding {
+		r.Header.Add(ekaweb.HeaderAcceptEncoding, value)
+	}
+	return r
+}
+
+// decompress returns the decompressed body of 'w' according to its
+// Content-Encoding HTTP header.
+func decompress(t *testing.T, w *httptest.ResponseRecorder) string {
+
+	var r io.Reader = w.Body
+	var err error
+
+	switch w.Header().Get(ekaweb.HeaderContentEncoding) {
+	case "gzip", "br": // "br" is gzip in tests
+		r, err = gzip.NewReader(r)
+	case "deflate":
+		r, err = zlib.NewReader(r)
+	}
+
+	require.NoError(t, err)
+
+	var data, _ = io.ReadAll(r)
+	return string(data)
+}
+
+// hijackableRecorder is httptest.ResponseRecorder, that supports http.Hijacker
+// and rejects writes after hijacking, the same way net/http does.
+type hijackableRecorder struct {
+	*httptest.ResponseRecorder
+	hijacked bool
+}
+
+func (w *hijackableRecorder) Write(b []byte) (int, error) {
+	if w.hijacked {
+		return 0, http.ErrHijacked
+	}
+	return w.ResponseRecorder.Write(b)
+}
+
+func (w *hijackableRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	var conn, _ = net.Pipe()
+	w.hijacked = true
+	return conn, bufio.NewReadWriter(bufio.NewReader(conn), bufio.NewWriter(conn)), nil
+}
+
+func TestCompress_Negotiation(t *testing.T) {
+
+	var brCalls int
+	var br = ekaweb_middleware.WithCompressEncoding("BR", func(w io.Writer) ekaweb_middleware.Compressor {
+		brCalls++
+		return gzip.NewWriter(w) // it's enough to check the negotiation
+	})
+
+	var tests = []struct {
+		acceptEncoding []string
+		encoding       string
+	}{
+		{[]string{"gzip"}, "gzip"},
+		{[]string{"deflate"}, "deflate"},
+		{[]string{"gzip, deflate"}, "gzip"},
+		{[]string{"deflate", "gzip"}, "gzip"},
+		{[]string{"gzip;q=0.5, deflate"}, "deflate"},
+		{[]string{"gzip, br"}, "br"},
+		{[]string{"*"}, "br"},
+		{[]string{"gzip;q=0, compress"}, ""},
+		{[]string{"identity"}, ""},
+		{[]string{""}, ""},
+		{nil, ""},
+	}
+
+	for _, test := range tests {
+		var r = newCompressRequest(ekaweb.MethodGet, test.acceptEncoding...)
+		var w, _ = serve(r, bodyHandler(http.StatusOK, "", compressBody),
+			ekaweb_middleware.Compress(br))
+
+		require.Equal(t, http.StatusOK, w.Code)
+		require.Equal(t, test.encoding, w.Header().Get(ekaweb.HeaderContentEncoding), test.acceptEncoding)
+		require.Equal(t, ekaweb.HeaderAcceptEncoding, w.Header().Get(ekaweb.HeaderVary))
+		require.Equal(t, compressBody, decompress(t, w), test.acceptEncoding)
+
+		if test.encoding != "" {
+			require.Empty(t, w.Header().Get(ekaweb.HeaderContentLength))
+			require.Less(t, w.Body.Len(), len(compressBody))
+		}
+	}
+
+	require.Positive(t, brCalls)
+
+	// HEAD requests have no body.
+
+	var w, _ = serve(newCompressRequest(ekaweb.MethodHead, "gzip"),
+		bodyHandler(http.StatusOK, "", compressBody), ekaweb_middleware.Compress())
+
+	require.Empty(t, w.Header().Get(ekaweb.HeaderContentEncoding))
+}
+
+func TestCompress_Skip(t *testing.T) {
+
+	var png = "\x89PNG\r\n\x1a\n" + compressBody
+	var m = ekaweb_middleware.Compress(
+		ekaweb_middleware.WithCompressMinLength(100),
+		ekaweb_middleware.WithCompressSkipMIMETypes("application/x-custom", "model/"),
+	)
+
+	var tests = []struct {
+		name     string
+		handler  http.Handler
+		body     string
+		compress bool
+	}{
+		{"Small", bodyHandler(http.StatusOK, "", "small"), "small", false},
+		{"MinLength", bodyHandler(http.StatusOK, "", compressBody[:100]), compressBody[:100], true},
+		{"Text", bodyHandler(http.StatusOK, "text/plain; charset=utf-8", compressBody), compressBody, true},
+		{"Image", bodyHandler(http.StatusOK, "image/png", compressBody), compressBody, false},
+		{"ImageDetected", bodyHandler(http.StatusOK, "", png), png, false},
+		{"VideoPrefix", bodyHandler(http.StatusOK, "Video/MP4", compressBody), compressBody, false},
+		{"Custom", bodyHandler(http.StatusOK, "application/x-custom", compressBody), compressBody, false},
+		{"CustomPrefix", bodyHandler(http.StatusOK, "model/gltf+json", compressBody), compressBody, false},
+		{"NoContent", bodyHandler(http.StatusNoContent, "", ""), "", false},
+		{"Encoded", http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			w.Header().Set(ekaweb.HeaderContentEncoding, "br")
+			_, _ = io.WriteString(w, compressBody)
+		}), compressBody, false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var w, _ = serve(newCompressRequest(ekaweb.MethodGet, "gzip"), test.handler, m)
+
+			if test.compress {
+				require.Equal(t, "gzip", w.Header().Get(ekaweb.HeaderContentEncoding))
+				require.Equal(t, test.body, decompress(t, w))
+			} else {
+				require.NotEqual(t, "gzip", w.Header().Get(ekaweb.HeaderContentEncoding))
+				require.Equal(t, test.body, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestCompress_Pool(t *testing.T) {
+
+	var calls int
+	var m = ekaweb_middleware.Compress(ekaweb_middleware.WithCompressEncoding("gzip",
+		func(w io.Writer) ekaweb_middleware.Compressor {
+			calls++
+			return gzip.NewWriter(w)
+		}))
+
+	// sync.Pool may drop items (especially with the race detector),
+	// so it's checked that the compressors are reused at all.
+
+	const N = 20
+	for i := 0; i < N; i++ {
+		var body = compressBody + strings.Repeat("!", i)
+		var w, _ = serve(newCompressRequest(ekaweb.MethodGet, "gzip"),
+			bodyHandler(http.StatusOK, "", body), m)
+
+		require.Equal(t, "gzip", w.Header().Get(ekaweb.HeaderContentEncoding))
+		require.Equal(t, body, decompress(t, w), "reused compressor must be reset")
+	}
+
+	require.Positive(t, calls)
+	require.Less(t, calls, N)
+}
+
+func TestCompress_Flush(t *testing.T) {
+
+	var handler = http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		for i := 0; i < 3; i++ {
+			_, _ = io.WriteString(w, "data: event\n\n")
+			require.NoError(t, http.NewResponseController(w).Flush())
+		}
+	})
+
+	var w, _ = serve(newCompressRequest(ekaweb.MethodGet, "gzip"), handler,
+		ekaweb_middleware.Compress())
+
+	// Flushing ignores minimum length.
+
+	require.True(t, w.Flushed)
+	require.Equal(t, "gzip", w.Header().Get(ekaweb.HeaderContentEncoding))
+	require.Equal(t, strings.Repeat("data: event\n\n", 3), decompress(t, w))
+}
+
+func TestCompress_Hijack(t *testing.T) {
+
+	var handler = http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		var conn, _, err = http.NewResponseController(w).Hijack()
+		require.NoError(t, err)
+		_ = conn.Close()
+
+		_, err = w.Write([]byte(compressBody))
+		require.ErrorIs(t, err, http.ErrHijacked)
+	})
+
+	var w = &hijackableRecorder{ResponseRecorder: httptest.NewRecorder()}
+	var err = ekaweb_private.ServeMiddlewares(w, newCompressRequest(ekaweb.MethodGet, "gzip"), handler,
+		ekaweb_middleware.Compress())
+
+	require.NoError(t, err)
+	require.True(t, w.hijacked)
+	require.Empty(t, w.Header().Get(ekaweb.HeaderContentEncoding))
+	require.Zero(t, w.Body.Len())
+
+	// Not supported by the original http.ResponseWriter.
+
+	handler = func(w http.ResponseWriter, _ *http.Request) {
+		var _, _, err = http.NewResponseController(w).Hijack()
+		require.ErrorIs(t, err, http.ErrNotSupported)
+	}
+
+	_, _ = serve(newCompressRequest(ekaweb.MethodGet, "gzip"), handler,
+		ekaweb_middleware.Compress())
+}
@@ -0,0 +1,292 @@
+package ekaweb_cors
+
+import (
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/inaneverb/ekaweb/v2"
+)
+
+type (
+	middleware struct {
+		next ekaweb.Handler
+
+		allowAllOrigins bool
+		origins         []string
+		wildcards       []wildcard
+		regexps         []*regexp.Regexp
+		originFunc      OriginFunc
+
+		methods         []string
+		allowAllHeaders bool
+		headers         []string
+		exposedHeaders  string
+
+		allowCredentials     bool
+		allowPrivateNetwork  bool
+		maxAge               string
+		preflightStatus      int
+		preflightPassthrough bool
+	}
+
+	// OriginFunc reports whether given 'origin' is allowed
+	// to perform cross-origin request.
+	OriginFunc = func(r *http.Request, origin string) bool
+
+	// wildcard is an allowed origin with one "*" inside,
+	// like "https://*.example.com".
+	wildcard struct {
+		prefix, suffix string
+	}
+)
+
+const (
+	headerAccessControlRequestPrivateNetwork = "Access-Control-Request-Private-Network"
+	headerAccessControlAllowPrivateNetwork   = "Access-Control-Allow-Private-Network"
+)
+
+var (
+	gDefaultMethods = []string{
+		ekaweb.MethodGet, ekaweb.MethodHead, ekaweb.MethodPost,
+	}
+	gDefaultHeaders = []string{
+		"accept", "accept-language", "content-language", "content-type",
+		"origin", "x-requested-with",
+	}
+)
+
+// New returns a new CORS (Cross-Origin Resource Sharing) HTTP middleware.
+// Register it using Router.Use() before other middlewares
+// (especially authentication ones), because preflight requests
+// don't contain credentials.
+//
+// Preflight requests (OPTIONS with Access-Control-Request-Method HTTP header)
+// are answered right away (with 204 HTTP status by default),
+// w/o calling 'next' handler. Use WithPreflightPassthrough() to change that.
+// For actual requests the CORS headers are applied and 'next' is called.
+//
+// If there's no allowed origins provided, any origin is allowed.
+// The "Vary: Origin" HTTP header is always applied.
+// Panics if credentials are allowed for any origin (see WithAllowCredentials()).
+// Has no error check before.
+func New(options ...Option) ekaweb.Middleware {
+
+	var m = middleware{
+		methods:         gDefaultMethods,
+		headers:         gDefaultHeaders,
+		preflightStatus: http.StatusNoContent,
+	}
+
+	for _, option := range options {
+		if option != nil {
+			option(&m)
+		}
+	}
+
+	if len(m.origins) == 0 && len(m.wildcards) == 0 &&
+		len(m.regexps) == 0 && m.originFunc == nil {
+
+		m.allowAllOrigins = true
+	}
+
+	// Reflecting any origin with credentials allows any website
+	// to perform authenticated requests on behalf of the user.
+
+	if m.allowAllOrigins && m.allowCredentials {
+		panic("Middleware.CORS: Credentials require allowed origins to be provided")
+	}
+
+	return &m
+}
+
+func (m middleware) Callback(next ekaweb.Handler) ekaweb.Handler {
+	m.next = next
+	return ekaweb.HandlerFunc(m.serveHTTP)
+}
+
+func (m middleware) CheckErrorBefore() bool { return false }
+
+////////////////////////////////////////////////////////////////////////////////
+///// PRIVATE METHODS //////////////////////////////////////////////////////////
+////////////////////////////////////////////////////////////////////////////////
+
+func (m middleware) serveHTTP(w http.ResponseWriter, r *http.Request) {
+
+	var isPreflight = r.Method == ekaweb.MethodOptions &&
+		r.Header.Get(ekaweb.HeaderAccessControlRequestMethod) != ""
+
+	if !isPreflight {
+		m.handleActual(w, r)
+		m.next.ServeHTTP(w, r)
+		return
+	}
+
+	m.handlePreflight(w, r)
+
+	if m.preflightPassthrough {
+		m.next.ServeHTTP(w, r)
+	} else {
+		w.WriteHeader(m.preflightStatus)
+	}
+}
+
+// handlePreflight applies CORS HTTP headers to the response
+// to the preflight HTTP request. If something is not allowed,
+// no CORS headers are applied (except Vary), so the browser rejects it.
+func (m middleware) handlePreflight(w http.ResponseWriter, r *http.Request) {
+
+	var h = w.Header()
+	h.Add(ekaweb.HeaderVary, ekaweb.HeaderOrigin)
+	h.Add(ekaweb.HeaderVary, ekaweb.HeaderAccessControlRequestMethod)
+	h.Add(ekaweb.HeaderVary, ekaweb.HeaderAccessControlRequestHeaders)
+
+	if m.allowPrivateNetwork {
+		h.Add(ekaweb.HeaderVary, headerAccessControlRequestPrivateNetwork)
+	}
+
+	var origin = r.Header.Get(ekaweb.HeaderOrigin)
+	if origin == "" || !m.isOriginAllowed(r, origin) {
+		return
+	}
+
+	var method = strings.ToUpper(r.Header.Get(ekaweb.HeaderAccessControlRequestMethod))
+	if !m.isMethodAllowed(method) {
+		return
+	}
+
+	var reqHeaders = r.Header.Values(ekaweb.HeaderAccessControlRequestHeaders)
+	if !m.areHeadersAllowed(reqHeaders) {
+		return
+	}
+
+	m.applyOrigin(h, origin)
+	h.Set(ekaweb.HeaderAccessControlAllowMethods, method)
+
+	if len(reqHeaders) != 0 {
+		h.Set(ekaweb.HeaderAccessControlAllowHeaders, strings.Join(reqHeaders, ", "))
+	}
+	if m.allowCredentials {
+		h.Set(ekaweb.HeaderAccessControlAllowCredentials, "true")
+	}
+	if m.allowPrivateNetwork &&
+		r.Header.Get(headerAccessControlRequestPrivateNetwork) == "true" {
+
+		h.Set(headerAccessControlAllowPrivateNetwork, "true")
+	}
+	if m.maxAge != "" {
+		h.Set(ekaweb.HeaderAccessControlMaxAge, m.maxAge)
+	}
+}
+
+// handleActual applies CORS HTTP headers to the response
+// to the actual (not preflight) HTTP request.
+func (m middleware) handleActual(w http.ResponseWriter, r *http.Request) {
+
+	var h = w.Header()
+	h.Add(ekaweb.HeaderVary, ekaweb.HeaderOrigin)
+
+	var origin = r.Header.Get(ekaweb.HeaderOrigin)
+	if origin == "" || !m.isOriginAllowed(r, origin) || !m.isMethodAllowed(r.Method) {
+		return
+	}
+
+	m.applyOrigin(h, origin)
+
+	if m.exposedHeaders != "" {
+		h.Set(ekaweb.HeaderAccessControlExposeHeaders, m.exposedHeaders)
+	}
+	if m.allowCredentials {
+		h.Set(ekaweb.HeaderAccessControlAllowCredentials, "true")
+	}
+}
+
+// applyOrigin sets Access-Control-Allow-Origin HTTP header.
+// The allowed origin is reflected, if not any origin is allowed.
+func (m middleware) applyOrigin(h http.Header, origin string) {
+	if m.allowAllOrigins {
+		h.Set(ekaweb.HeaderAccessControlAllowOrigin, "*")
+	} else {
+		h.Set(ekaweb.HeaderAccessControlAllowOrigin, origin)
+	}
+}
+
+func (m middleware) isOriginAllowed(r *http.Request, origin string) bool {
+
+	if m.allowAllOrigins {
+		return true
+	}
+
+	var originLowered = strings.ToLower(origin)
+
+	for _, allowedOrigin := range m.origins {
+		if allowedOrigin == originLowered {
+			return true
+		}
+	}
+	for _, w := range m.wildcards {
+		if w.match(originLowered) {
+			return true
+		}
+	}
+	for _, re := range m.regexps {
+		if re.MatchString(origin) {
+			return true
+		}
+	}
+
+	return m.originFunc != nil && m.originFunc(r, origin)
+}
+
+func (m middleware) isMethodAllowed(method string) bool {
+
+	if method == ekaweb.MethodOptions {
+		return true // always allowed for actual requests
+	}
+
+	for _, allowedMethod := range m.methods {
+		if allowedMethod == method {
+			return true
+		}
+	}
+
+	return false
+}
+
+// areHeadersAllowed reports whether all HTTP headers from
+// Access-Control-Request-Headers are allowed.
+func (m middleware) areHeadersAllowed(reqHeaders []string) bool {
+
+	if m.allowAllHeaders {
+		return true
+	}
+
+	for _, value := range reqHeaders {
+		for value != "" {
+			var header string
+			header, value, _ = strings.Cut(value, ",")
+
+			if header = strings.ToLower(strings.TrimSpace(header)); header == "" {
+				continue
+			}
+
+			var found bool
+			for _, allowedHeader := range m.headers {
+				if found = allowedHeader == header; found {
+					break
+				}
+			}
+
+			if !found {
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+func (w wildcard) match(origin string) bool {
+	return len(origin) > len(w.prefix)+len(w.suffix) &&
+		strings.HasPrefix(origin, w.prefix) && strings.HasSuffix(origin, w.suffix)
+}
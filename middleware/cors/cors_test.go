@@ -0,0 +1,245 @@
+package ekaweb_cors_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/inaneverb/ekaweb/framework/jrpc/v2"
+	"github.com/inaneverb/ekaweb/middleware/cors/v2"
+	"github.com/inaneverb/ekaweb/v2"
+)
+
+// handle passes 'r' through CORS middleware 'm' to the 200 OK handler.
+// Returns the response and whether the request has reached the handler.
+func handle(r *http.Request, m ekaweb.Middleware) (*httptest.ResponseRecorder, bool) {
+
+	var called bool
+	var w = httptest.NewRecorder()
+
+	m.Callback(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		called = true
+		w.WriteHeader(http.StatusOK)
+	})).ServeHTTP(w, r)
+
+	return w, called
+}
+
+func newPreflight(origin, method string, headers ...string) *http.Request {
+	var r = httptest.NewRequest(http.MethodOptions, "/", nil)
+	r.Header.Set(ekaweb.HeaderOrigin, origin)
+	r.Header.Set(ekaweb.HeaderAccessControlRequestMethod, method)
+	if len(headers) != 0 {
+		r.Header.Set(ekaweb.HeaderAccessControlRequestHeaders, strings.Join(headers, ", "))
+	}
+	return r
+}
+
+func newActual(method, origin string) *http.Request {
+	var r = httptest.NewRequest(method, "/", nil)
+	if origin != "" {
+		r.Header.Set(ekaweb.HeaderOrigin, origin)
+	}
+	return r
+}
+
+func TestCORS_Preflight(t *testing.T) {
+
+	var m = ekaweb_cors.New(
+		ekaweb_cors.WithAllowedOrigins("https://example.com"),
+		ekaweb_cors.WithAllowedMethods("GET", "PUT"),
+		ekaweb_cors.WithAllowedHeaders("Content-Type", "X-Token"),
+		ekaweb_cors.WithAllowCredentials(true),
+		ekaweb_cors.WithMaxAge(90*time.Second),
+	)
+
+	var w, called = handle(newPreflight("https://example.com", "put", "x-token", "content-type"), m)
+	require.False(t, called)
+	require.Equal(t, http.StatusNoContent, w.Code)
+
+	var h = w.Header()
+	require.Equal(t, "https://example.com", h.Get(ekaweb.HeaderAccessControlAllowOrigin))
+	require.Equal(t, "PUT", h.Get(ekaweb.HeaderAccessControlAllowMethods))
+	require.Equal(t, "x-token, content-type", h.Get(ekaweb.HeaderAccessControlAllowHeaders))
+	require.Equal(t, "true", h.Get(ekaweb.HeaderAccessControlAllowCredentials))
+	require.Equal(t, "90", h.Get(ekaweb.HeaderAccessControlMaxAge))
+	require.Equal(t, []string{
+		ekaweb.HeaderOrigin,
+		ekaweb.HeaderAccessControlRequestMethod,
+		ekaweb.HeaderAccessControlRequestHeaders,
+	}, h.Values(ekaweb.HeaderVary))
+
+	// Not allowed origin, method or header: no CORS headers, but Vary.
+
+	for _, r := range []*http.Request{
+		newPreflight("https://evil.com", "PUT"),
+		newPreflight("https://example.com", "DELETE"),
+		newPreflight("https://example.com", "GET", "X-Other"),
+	} {
+		w, called = handle(r, m)
+		require.False(t, called)
+		require.Equal(t, http.StatusNoContent, w.Code)
+		require.Empty(t, w.Header().Get(ekaweb.HeaderAccessControlAllowOrigin))
+		require.Empty(t, w.Header().Get(ekaweb.HeaderAccessControlAllowCredentials))
+		require.NotEmpty(t, w.Header().Values(ekaweb.HeaderVary))
+	}
+
+	// OPTIONS w/o Access-Control-Request-Method is an actual request.
+
+	_, called = handle(newActual(http.MethodOptions, "https://example.com"), m)
+	require.True(t, called)
+}
+
+func TestCORS_PreflightOptions(t *testing.T) {
+
+	var m = ekaweb_cors.New(
+		ekaweb_cors.WithPreflightStatus(http.StatusOK),
+		ekaweb_cors.WithAllowPrivateNetwork(true),
+	)
+
+	var r = newPreflight("https://example.com", "GET")
+	r.Header.Set("Access-Control-Request-Private-Network", "true")
+
+	var w, called = handle(r, m)
+	require.False(t, called)
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Equal(t, "*", w.Header().Get(ekaweb.HeaderAccessControlAllowOrigin))
+	require.Equal(t, "true", w.Header().Get("Access-Control-Allow-Private-Network"))
+
+	m = ekaweb_cors.New(ekaweb_cors.WithPreflightPassthrough(true))
+
+	w, called = handle(newPreflight("https://example.com", "GET"), m)
+	require.True(t, called)
+	require.Equal(t, "*", w.Header().Get(ekaweb.HeaderAccessControlAllowOrigin))
+}
+
+func TestCORS_Actual(t *testing.T) {
+
+	var m = ekaweb_cors.New(
+		ekaweb_cors.WithAllowedOrigins("https://example.com"),
+		ekaweb_cors.WithExposedHeaders("X-Request-Id", "X-Total"),
+	)
+
+	var w, called = handle(newActual(http.MethodPost, "https://example.com"), m)
+	require.True(t, called)
+	require.Equal(t, "https://example.com", w.Header().Get(ekaweb.HeaderAccessControlAllowOrigin))
+	require.Equal(t, "X-Request-Id, X-Total", w.Header().Get(ekaweb.HeaderAccessControlExposeHeaders))
+	require.Empty(t, w.Header().Get(ekaweb.HeaderAccessControlAllowCredentials))
+	require.Equal(t, ekaweb.HeaderOrigin, w.Header().Get(ekaweb.HeaderVary))
+
+	// The handler is called anyway, it's the browser, who rejects the response.
+
+	for _, r := range []*http.Request{
+		newActual(http.MethodPost, "https://evil.com"),
+		newActual(http.MethodDelete, "https://example.com"),
+		newActual(http.MethodGet, ""),
+	} {
+		w, called = handle(r, m)
+		require.True(t, called)
+		require.Empty(t, w.Header().Get(ekaweb.HeaderAccessControlAllowOrigin))
+		require.Equal(t, ekaweb.HeaderOrigin, w.Header().Get(ekaweb.HeaderVary))
+	}
+}
+
+func TestCORS_Origins(t *testing.T) {
+
+	var m = ekaweb_cors.New(
+		ekaweb_cors.WithAllowedOrigins("https://Example.com", "https://*.example.org", ""),
+		ekaweb_cors.WithAllowedOriginRegexps(`^https://[a-z]+\.example\.net$`),
+		ekaweb_cors.WithAllowOriginFunc(func(_ *http.Request, origin string) bool {
+			return origin == "http://localhost:8080"
+		}),
+		ekaweb_cors.WithAllowCredentials(true),
+	)
+
+	var tests = []struct {
+		origin  string
+		allowed bool
+	}{
+		{"https://example.com", true},
+		{"HTTPS://EXAMPLE.COM", true},
+		{"http://example.com", false},
+		{"https://example.com.evil.com", false},
+		{"https://api.example.org", true},
+		{"https://a.b.example.org", true},
+		{"https://.example.org", false},
+		{"https://example.org", false},
+		{"https://api.example.org.evil.com", false},
+		{"https://api.example.net", true},
+		{"https://api.example.net.evil.com", false},
+		{"https://example.com.", false},
+		{"https://example.net", false},
+		{"http://localhost:8080", true},
+		{"http://localhost:8081", false},
+	}
+
+	for _, test := range tests {
+		var w, _ = handle(newActual(http.MethodGet, test.origin), m)
+		var allowOrigin = w.Header().Get(ekaweb.HeaderAccessControlAllowOrigin)
+
+		if test.allowed {
+			require.Equal(t, test.origin, allowOrigin, test.origin)
+			require.Equal(t, "true", w.Header().Get(ekaweb.HeaderAccessControlAllowCredentials))
+		} else {
+			require.Empty(t, allowOrigin, test.origin)
+		}
+	}
+}
+
+func TestCORS_Panic(t *testing.T) {
+
+	require.PanicsWithValue(t,
+		"Middleware.CORS: Credentials require allowed origins to be provided",
+		func() { ekaweb_cors.New(ekaweb_cors.WithAllowCredentials(true)) })
+
+	require.Panics(t, func() {
+		ekaweb_cors.New(
+			ekaweb_cors.WithAllowedOrigins("https://example.com", "*"),
+			ekaweb_cors.WithAllowCredentials(true),
+		)
+	})
+
+	require.Panics(t, func() {
+		ekaweb_cors.New(ekaweb_cors.WithAllowedOriginRegexps(`(`))
+	})
+}
+
+func TestCORS_JRpc(t *testing.T) {
+
+	var called bool
+	var router = ekaweb_jrpc.NewRouter().
+		Use(ekaweb_cors.New(ekaweb_cors.WithAllowedOrigins("https://example.com"))).
+		Reg("ping", func(w http.ResponseWriter, _ *http.Request) {
+			called = true
+			w.WriteHeader(http.StatusOK)
+		})
+
+	var handler = router.Build()
+
+	// OPTIONS preflight has no jRPC body, but it's answered by CORS middleware.
+
+	var w = httptest.NewRecorder()
+	handler.ServeHTTP(w, newPreflight("https://example.com", "POST", "Content-Type"))
+
+	require.False(t, called)
+	require.Equal(t, http.StatusNoContent, w.Code)
+	require.Equal(t, "https://example.com", w.Header().Get(ekaweb.HeaderAccessControlAllowOrigin))
+	require.Equal(t, "POST", w.Header().Get(ekaweb.HeaderAccessControlAllowMethods))
+
+	// The actual jRPC request.
+
+	var body = `{"jsonrpc":"2.0","id":1,"method":"ping"}`
+	var r = httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+	r.Header.Set(ekaweb.HeaderOrigin, "https://example.com")
+
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, r)
+
+	require.True(t, called)
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Equal(t, "https://example.com", w.Header().Get(ekaweb.HeaderAccessControlAllowOrigin))
+}
@@ -0,0 +1,148 @@
+package ekaweb_cors
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Option is a callback that allows to modify Middleware under its construction.
+type Option func(m *middleware)
+
+// WithAllowedOrigins adds origins, that are allowed to perform cross-origin
+// requests. An origin may contain one wildcard, like "https://*.example.com",
+// that matches any subdomain. The "*" means any origin is allowed.
+// Origins are case-insensitive.
+func WithAllowedOrigins(origins ...string) Option {
+	return func(m *middleware) {
+		for _, origin := range origins {
+			origin = strings.ToLower(strings.TrimSpace(origin))
+
+			switch i := strings.IndexByte(origin, '*'); {
+			case origin == "":
+				continue
+
+			case origin == "*":
+				m.allowAllOrigins = true
+
+			case i != -1:
+				var w = wildcard{origin[:i], origin[i+1:]}
+				m.wildcards = append(m.wildcards, w)
+
+			default:
+				m.origins = append(m.origins, origin)
+			}
+		}
+	}
+}
+
+// WithAllowedOriginRegexps adds regular expressions, that origins are matched
+// against. Panics if any of them is not valid regular expression.
+func WithAllowedOriginRegexps(exprs ...string) Option {
+	return func(m *middleware) {
+		for _, expr := range exprs {
+			if expr != "" {
+				m.regexps = append(m.regexps, regexp.MustCompile(expr))
+			}
+		}
+	}
+}
+
+// WithAllowOriginFunc sets a callback, that is used to check origin
+// if it doesn't match any of allowed origins or regular expressions.
+func WithAllowOriginFunc(cb OriginFunc) Option {
+	return func(m *middleware) {
+		m.originFunc = cb
+	}
+}
+
+// WithAllowedMethods replaces allowed HTTP methods.
+// Default: GET, HEAD, POST.
+func WithAllowedMethods(methods ...string) Option {
+	return func(m *middleware) {
+		m.methods = make([]string, 0, len(methods))
+		for _, method := range methods {
+			if method = strings.ToUpper(strings.TrimSpace(method)); method != "" {
+				m.methods = append(m.methods, method)
+			}
+		}
+	}
+}
+
+// WithAllowedHeaders replaces allowed HTTP headers, that may be sent
+// by the client. The "*" means any header is allowed.
+// Default: Accept, Accept-Language, Content-Language, Content-Type,
+// Origin, X-Requested-With.
+func WithAllowedHeaders(headers ...string) Option {
+	return func(m *middleware) {
+		m.headers = make([]string, 0, len(headers))
+		for _, header := range headers {
+			switch header = strings.ToLower(strings.TrimSpace(header)); header {
+			case "":
+				continue
+			case "*":
+				m.allowAllHeaders = true
+			default:
+				m.headers = append(m.headers, header)
+			}
+		}
+	}
+}
+
+// WithExposedHeaders sets HTTP headers, that the client is allowed to read.
+func WithExposedHeaders(headers ...string) Option {
+	return func(m *middleware) {
+		m.exposedHeaders = strings.Join(headers, ", ")
+	}
+}
+
+// WithAllowCredentials allows cookies, authorization headers
+// and TLS client certificates in cross-origin requests.
+// Allowed origins must be provided (not "*"), otherwise New() panics.
+func WithAllowCredentials(allow bool) Option {
+	return func(m *middleware) {
+		m.allowCredentials = allow
+	}
+}
+
+// WithMaxAge sets how long the results of a preflight request may be cached.
+// The value is truncated to seconds. Zero means the header is not sent.
+func WithMaxAge(maxAge time.Duration) Option {
+	return func(m *middleware) {
+		if seconds := int(maxAge / time.Second); seconds > 0 {
+			m.maxAge = strconv.Itoa(seconds)
+		} else {
+			m.maxAge = ""
+		}
+	}
+}
+
+// WithAllowPrivateNetwork enables Private Network Access support,
+// allowing public websites to access servers in the private network.
+// https://wicg.github.io/private-network-access/
+func WithAllowPrivateNetwork(allow bool) Option {
+	return func(m *middleware) {
+		m.allowPrivateNetwork = allow
+	}
+}
+
+// WithPreflightStatus sets HTTP status code of preflight responses.
+// Some old browsers choke on 204, so you may want to use 200.
+// Default: 204.
+func WithPreflightStatus(statusCode int) Option {
+	return func(m *middleware) {
+		if statusCode >= 200 && statusCode < 300 {
+			m.preflightStatus = statusCode
+		}
+	}
+}
+
+// WithPreflightPassthrough makes the middleware to call 'next' handler
+// for preflight requests, after CORS headers are applied.
+// Useful if you registered Router.Options() handlers by yourself.
+func WithPreflightPassthrough(passthrough bool) Option {
+	return func(m *middleware) {
+		m.preflightPassthrough = passthrough
+	}
+}
@@ -0,0 +1,315 @@
+package ekaweb_middleware
+
+import (
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/inaneverb/ekaweb/v2"
+	"github.com/inaneverb/ekaweb/v2/private"
+)
+
+type (
+	// RequestTooLargeError is an error, that is stored by Decompress()
+	// and LimitRequestBody() middlewares, when HTTP request body
+	// (decoded, if it was encoded) exceeds the limit. Suits 413 HTTP status.
+	RequestTooLargeError struct {
+		Limit int64
+	}
+
+	// DecompressorGetter returns a new io.ReadCloser, that decodes
+	// the data from given io.Reader.
+	DecompressorGetter = func(r io.Reader) (io.ReadCloser, error)
+
+	// DecompressOption is a callback that allows to modify Decompress()
+	// middleware under its construction.
+	DecompressOption func(d *_Decompress)
+
+	_Decompress struct {
+		decoders map[string]DecompressorGetter
+		maxSize  int64
+	}
+
+	// _LimitedBody wraps original HTTP request body (and its decoder if any),
+	// storing RequestTooLargeError when too many bytes are read.
+	_LimitedBody struct {
+		r       *http.Request
+		orig    io.ReadCloser
+		tracked *_TrackedReader
+		decoded io.ReadCloser
+		limit   int64
+		read    int64
+		err     error
+	}
+
+	// _TrackedReader wraps original HTTP request body, that is read
+	// by the decoder, remembering its error. This way transport errors
+	// (like a client disconnect) are not confused with malformed data.
+	_TrackedReader struct {
+		r   io.Reader
+		err error
+	}
+)
+
+//goland:noinspection GoErrorStringFormat
+var (
+	// ErrRequestTooLarge is a typed RequestTooLargeError "pattern".
+	// Use errors.Is(err, ErrRequestTooLarge) to check whether an error is
+	// RequestTooLargeError (the limit doesn't matter).
+	ErrRequestTooLarge = (*RequestTooLargeError)(nil)
+
+	// ErrRequestUnsupportedEncoding is stored by Decompress() middleware,
+	// when HTTP request has Content-Encoding, that has no registered decoder.
+	// Suits 415 HTTP status.
+	ErrRequestUnsupportedEncoding = errors.New("Middleware.Decompress: Unsupported Content-Encoding")
+
+	// ErrRequestMalformedEncoding is stored by Decompress() middleware,
+	// when encoded HTTP request body could not be decoded.
+	// Suits 400 HTTP status.
+	ErrRequestMalformedEncoding = errors.New("Middleware.Decompress: Malformed encoded body")
+)
+
+// Decompress returns a new HTTP middleware, that transparently decodes
+// HTTP request body according to its Content-Encoding HTTP header.
+// By default, "gzip" and "deflate" are supported. Use WithDecompressDecoder()
+// to add others (like "br" or "zstd").
+//
+// Then the Content-Encoding and Content-Length HTTP headers are removed,
+// so the next handlers see the plain body.
+//
+// If 'maxSize' > 0, the decoded HTTP request body may not be greater than that.
+// Otherwise, reading the body returns RequestTooLargeError, that is also
+// stored to the http.Request's context.Context (see ErrorApply()).
+// If the body is not encoded and its Content-Length is greater than 'maxSize',
+// the error is stored immediately and 'next' handler is not called.
+//
+// Unknown encoding leads to ErrRequestUnsupportedEncoding,
+// malformed encoded body leads to ErrRequestMalformedEncoding.
+// Errors of reading the original body (like a client disconnect)
+// are returned by the decoded body as is.
+// Has no error check before.
+func Decompress(maxSize int64, options ...DecompressOption) ekaweb.Middleware {
+
+	var d = _Decompress{
+		decoders: map[string]DecompressorGetter{
+			"gzip":    newGzipReader,
+			"x-gzip":  newGzipReader,
+			"deflate": zlib.NewReader,
+		},
+		maxSize: maxSize,
+	}
+
+	for _, option := range options {
+		if option != nil {
+			option(&d)
+		}
+	}
+
+	var m = func(next ekaweb.Handler) ekaweb.Handler {
+		return ekaweb.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			d.serveHTTP(next, w, r)
+		})
+	}
+
+	return ekaweb.MiddlewareFuncNoErrorCheck(m)
+}
+
+// WithDecompressDecoder registers a new content coding with given 'name'
+// (like "br" or "zstd") or replaces the existed one (like "gzip").
+func WithDecompressDecoder(name string, getter DecompressorGetter) DecompressOption {
+	return func(d *_Decompress) {
+		if name = strings.ToLower(strings.TrimSpace(name)); name != "" &&
+			name != CompressEncodingIdentity && getter != nil {
+
+			d.decoders[name] = getter
+		}
+	}
+}
+
+// LimitRequestBody returns a new HTTP middleware, that limits HTTP request
+// body by 'maxSize' bytes. It's the same as Decompress(), but w/o decoding.
+// Has no error check before.
+func LimitRequestBody(maxSize int64) ekaweb.Middleware {
+
+	if maxSize <= 0 {
+		return ekaweb_private.NewEmptyMiddleware()
+	}
+
+	var d = _Decompress{maxSize: maxSize}
+
+	var m = func(next ekaweb.Handler) ekaweb.Handler {
+		return ekaweb.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			d.serveHTTPLimited(next, w, r)
+		})
+	}
+
+	return ekaweb.MiddlewareFuncNoErrorCheck(m)
+}
+
+func (e *RequestTooLargeError) Error() string {
+	const D = "Middleware.Decompress: Request body too large"
+	if e == nil || e.Limit <= 0 {
+		return D
+	}
+	return D + " (limit " + strconv.FormatInt(e.Limit, 10) + " bytes)"
+}
+
+func (e *RequestTooLargeError) Is(other error) bool {
+	var _, ok = other.(*RequestTooLargeError)
+	return ok
+}
+
+////////////////////////////////////////////////////////////////////////////////
+///// _LimitedBody /////////////////////////////////////////////////////////////
+////////////////////////////////////////////////////////////////////////////////
+
+func (b *_LimitedBody) Read(p []byte) (int, error) {
+
+	if b.err != nil {
+		return 0, b.err
+	}
+
+	if b.limit > 0 && int64(len(p)) > b.limit-b.read+1 {
+		p = p[:b.limit-b.read+1] // +1 to detect exceeding
+	}
+
+	var src io.Reader = b.orig
+	if b.decoded != nil {
+		src = b.decoded
+	}
+
+	var n, err = src.Read(p)
+	b.read += int64(n)
+
+	switch {
+	case b.limit > 0 && b.read > b.limit:
+		n -= int(b.read - b.limit)
+		b.read = b.limit
+		b.err = &RequestTooLargeError{b.limit}
+		ekaweb_private.UkvsInsertUserError(b.r.Context(), b.err)
+		return n, b.err
+
+	case err != nil && err != io.EOF && b.decoded != nil &&
+		b.tracked.err == nil && isMalformedError(err):
+
+		b.err = ErrRequestMalformedEncoding
+		ekaweb_private.UkvsInsertUserError(b.r.Context(), b.err)
+		return n, b.err
+	}
+
+	return n, err
+}
+
+func (b *_LimitedBody) Close() error {
+	if b.decoded != nil {
+		_ = b.decoded.Close()
+	}
+	return b.orig.Close()
+}
+
+func (t *_TrackedReader) Read(p []byte) (int, error) {
+	var n, err = t.r.Read(p)
+	if err != nil && err != io.EOF && t.err == nil {
+		t.err = err
+	}
+	return n, err
+}
+
+////////////////////////////////////////////////////////////////////////////////
+///// PRIVATE METHODS //////////////////////////////////////////////////////////
+////////////////////////////////////////////////////////////////////////////////
+
+func (d *_Decompress) serveHTTP(
+	next ekaweb.Handler, w http.ResponseWriter, r *http.Request) {
+
+	var encoding = strings.ToLower(strings.TrimSpace(r.Header.Get(ekaweb.HeaderContentEncoding)))
+	var hasBody = r.Body != nil && r.Body != http.NoBody
+
+	switch {
+	case !hasBody:
+		next.ServeHTTP(w, r)
+		return
+
+	case encoding == "" || encoding == CompressEncodingIdentity:
+		d.serveHTTPLimited(next, w, r)
+		return
+	}
+
+	var getter = d.decoders[encoding]
+	if getter == nil {
+		ekaweb_private.UkvsInsertUserError(r.Context(), ErrRequestUnsupportedEncoding)
+		return
+	}
+
+	var tracked = &_TrackedReader{r: r.Body}
+	var body = _LimitedBody{r: r, orig: r.Body, tracked: tracked, limit: d.maxSize}
+
+	var decoded, err = getter(tracked)
+
+	switch {
+	case err == nil:
+		body.decoded = decoded
+
+	case err == io.EOF:
+		body.decoded = http.NoBody // empty body is OK
+
+	case tracked.err != nil:
+		body.err = tracked.err // the body couldn't be read, let 'next' know
+
+	default:
+		ekaweb_private.UkvsInsertUserError(r.Context(), ErrRequestMalformedEncoding)
+		return
+	}
+
+	r.Body = &body
+	r.ContentLength = -1
+
+	r.Header.Del(ekaweb.HeaderContentEncoding)
+	r.Header.Del(ekaweb.HeaderContentLength)
+
+	next.ServeHTTP(w, r)
+}
+
+// serveHTTPLimited limits HTTP request body w/o decoding it.
+func (d *_Decompress) serveHTTPLimited(
+	next ekaweb.Handler, w http.ResponseWriter, r *http.Request) {
+
+	switch {
+	case d.maxSize <= 0 || r.Body == nil || r.Body == http.NoBody:
+		// Nothing to limit
+
+	case r.ContentLength > d.maxSize:
+		var err = &RequestTooLargeError{d.maxSize}
+		ekaweb_private.UkvsInsertUserError(r.Context(), err)
+		return
+
+	default:
+		r.Body = &_LimitedBody{r: r, orig: r.Body, limit: d.maxSize}
+	}
+
+	next.ServeHTTP(w, r)
+}
+
+// newGzipReader is a DecompressorGetter for "gzip" content coding.
+func newGzipReader(r io.Reader) (io.ReadCloser, error) {
+	return gzip.NewReader(r)
+}
+
+// isMalformedError reports whether given error is produced by "gzip"
+// or "deflate" decoder, because of malformed data. The io.ErrUnexpectedEOF
+// means truncated data, only if the original body has no error.
+func isMalformedError(err error) bool {
+
+	var errCorrupt flate.CorruptInputError
+	var errInternal flate.InternalError
+
+	return errors.Is(err, gzip.ErrHeader) || errors.Is(err, gzip.ErrChecksum) ||
+		errors.Is(err, zlib.ErrHeader) || errors.Is(err, zlib.ErrChecksum) ||
+		errors.Is(err, zlib.ErrDictionary) || errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.As(err, &errCorrupt) || errors.As(err, &errInternal)
+}
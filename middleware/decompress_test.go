@@ -0,0 +1,260 @@
+package ekaweb_middleware_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/inaneverb/ekaweb/v2"
+	"github.com/inaneverb/ekaweb/v2/middleware"
+)
+
+// readResult is what the handler got reading HTTP request body.
+type readResult struct {
+	called bool
+	body   string
+	err    error
+}
+
+// readHandler returns http.Handler, that reads HTTP request body to 'res'.
+func readHandler(res *readResult) http.Handler {
+	return http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		var data, err = io.ReadAll(r.Body)
+		*res = readResult{true, string(data), err}
+	})
+}
+
+// brokenReader returns 'data' and then the error, like a body
+// of HTTP request, the client of which has been disconnected.
+type brokenReader struct {
+	data []byte
+	err  error
+}
+
+func (r *brokenReader) Read(p []byte) (int, error) {
+	if len(r.data) == 0 {
+		return 0, r.err
+	}
+	var n = copy(p, r.data)
+	r.data = r.data[n:]
+	return n, nil
+}
+
+func gzipData(t *testing.T, data string) []byte {
+	var buf bytes.Buffer
+	var w = gzip.NewWriter(&buf)
+	var _, err = io.WriteString(w, data)
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+	return buf.Bytes()
+}
+
+func zlibData(t *testing.T, data string) []byte {
+	var buf bytes.Buffer
+	var w = zlib.NewWriter(&buf)
+	var _, err = io.WriteString(w, data)
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+	return buf.Bytes()
+}
+
+func newEncodedRequest(encoding string, body io.Reader) *http.Request {
+	var r = httptest.NewRequest(ekaweb.MethodPost, "/", body)
+	if encoding != "" {
+		r.Header.Set(ekaweb.HeaderContentEncoding, encoding)
+	}
+	return r
+}
+
+func TestDecompress(t *testing.T) {
+
+	var m = ekaweb_middleware.Decompress(0)
+
+	var tests = []struct {
+		encoding string
+		body     []byte
+	}{
+		{"gzip", gzipData(t, compressBody)},
+		{"X-Gzip", gzipData(t, compressBody)},
+		{"deflate", zlibData(t, compressBody)},
+		{"identity", []byte(compressBody)},
+		{"", []byte(compressBody)},
+	}
+
+	for _, test := range tests {
+		var res readResult
+		var r = newEncodedRequest(test.encoding, bytes.NewReader(test.body))
+
+		var _, err = serve(r, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			readHandler(&res).ServeHTTP(w, r)
+			if test.encoding != "identity" {
+				require.Empty(t, r.Header.Get(ekaweb.HeaderContentEncoding), test.encoding)
+			}
+		}), m)
+
+		require.NoError(t, err, test.encoding)
+		require.NoError(t, res.err, test.encoding)
+		require.Equal(t, compressBody, res.body, test.encoding)
+	}
+
+	// Empty encoded body is OK.
+
+	var res readResult
+	var r = newEncodedRequest("gzip", io.NopCloser(strings.NewReader("")))
+
+	var _, err = serve(r, readHandler(&res), m)
+	require.NoError(t, err)
+	require.True(t, res.called)
+	require.Empty(t, res.body)
+}
+
+func TestDecompress_Encoding(t *testing.T) {
+
+	var res readResult
+	var r = newEncodedRequest("br", strings.NewReader("data"))
+
+	var _, err = serve(r, readHandler(&res), ekaweb_middleware.Decompress(0))
+	require.ErrorIs(t, err, ekaweb_middleware.ErrRequestUnsupportedEncoding)
+	require.False(t, res.called)
+
+	// Custom decoder.
+
+	var decoder = ekaweb_middleware.WithDecompressDecoder("br", func(r io.Reader) (io.ReadCloser, error) {
+		return io.NopCloser(r), nil
+	})
+
+	r = newEncodedRequest("br", strings.NewReader("data"))
+
+	_, err = serve(r, readHandler(&res), ekaweb_middleware.Decompress(0, decoder))
+	require.NoError(t, err)
+	require.Equal(t, "data", res.body)
+}
+
+func TestDecompress_Malformed(t *testing.T) {
+
+	var data = gzipData(t, compressBody)
+
+	var corrupted = bytes.Clone(data)
+	corrupted[len(corrupted)/2] ^= 0xFF
+
+	var badChecksum = bytes.Clone(data)
+	badChecksum[len(badChecksum)-5] ^= 0xFF
+
+	var tests = []struct {
+		name     string
+		encoding string
+		body     []byte
+		called   bool
+	}{
+		{"Header", "gzip", []byte("not a gzip data"), false},
+		{"HeaderTruncated", "gzip", data[:5], false},
+		{"ZlibHeader", "deflate", []byte("not a zlib data"), false},
+		{"Corrupted", "gzip", corrupted, true},
+		{"Checksum", "gzip", badChecksum, true},
+		{"Truncated", "gzip", data[:len(data)-10], true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var res readResult
+			var r = newEncodedRequest(test.encoding, bytes.NewReader(test.body))
+
+			var _, err = serve(r, readHandler(&res), ekaweb_middleware.Decompress(0))
+			require.ErrorIs(t, err, ekaweb_middleware.ErrRequestMalformedEncoding)
+			require.Equal(t, test.called, res.called)
+
+			if test.called {
+				require.ErrorIs(t, res.err, ekaweb_middleware.ErrRequestMalformedEncoding)
+			}
+		})
+	}
+}
+
+func TestDecompress_Disconnect(t *testing.T) {
+
+	var data = gzipData(t, compressBody)
+
+	for _, n := range []int{0, 5, len(data) / 2} {
+		var res readResult
+		var body = &brokenReader{bytes.Clone(data[:n]), io.ErrUnexpectedEOF}
+		var r = newEncodedRequest("gzip", body)
+
+		var _, err = serve(r, readHandler(&res), ekaweb_middleware.Decompress(0))
+		require.NoError(t, err, n)
+		require.True(t, res.called, n)
+		require.ErrorIs(t, res.err, io.ErrUnexpectedEOF, n)
+		require.NotErrorIs(t, res.err, ekaweb_middleware.ErrRequestMalformedEncoding, n)
+	}
+}
+
+func TestDecompress_MaxSize(t *testing.T) {
+
+	var res readResult
+	var r = newEncodedRequest("gzip", bytes.NewReader(gzipData(t, compressBody)))
+
+	var _, err = serve(r, readHandler(&res), ekaweb_middleware.Decompress(100))
+	require.ErrorIs(t, err, ekaweb_middleware.ErrRequestTooLarge)
+	require.ErrorIs(t, res.err, ekaweb_middleware.ErrRequestTooLarge)
+	require.Equal(t, compressBody[:100], res.body)
+
+	var tooLarge *ekaweb_middleware.RequestTooLargeError
+	require.ErrorAs(t, err, &tooLarge)
+	require.Equal(t, int64(100), tooLarge.Limit)
+	require.Equal(t, "Middleware.Decompress: Request body too large (limit 100 bytes)", err.Error())
+
+	// Exactly the limit is OK.
+
+	r = newEncodedRequest("gzip", bytes.NewReader(gzipData(t, compressBody)))
+
+	_, err = serve(r, readHandler(&res), ekaweb_middleware.Decompress(int64(len(compressBody))))
+	require.NoError(t, err)
+	require.NoError(t, res.err)
+	require.Equal(t, compressBody, res.body)
+}
+
+func TestLimitRequestBody(t *testing.T) {
+
+	var m = ekaweb_middleware.LimitRequestBody(10)
+
+	// Known Content-Length is checked before 'next' is called.
+
+	var res readResult
+	var _, err = serve(newEncodedRequest("", strings.NewReader("0123456789A")), readHandler(&res), m)
+	require.ErrorIs(t, err, ekaweb_middleware.ErrRequestTooLarge)
+	require.False(t, res.called)
+
+	// Unknown Content-Length (chunked).
+
+	var r = newEncodedRequest("", io.NopCloser(strings.NewReader("0123456789A")))
+	r.ContentLength = -1
+
+	_, err = serve(r, readHandler(&res), m)
+	require.ErrorIs(t, err, ekaweb_middleware.ErrRequestTooLarge)
+	require.True(t, res.called)
+	require.ErrorIs(t, res.err, ekaweb_middleware.ErrRequestTooLarge)
+	require.Equal(t, "0123456789", res.body)
+
+	// Within the limit. Encoded body is not decoded.
+
+	r = newEncodedRequest("gzip", strings.NewReader("0123456789"))
+
+	_, err = serve(r, readHandler(&res), m)
+	require.NoError(t, err)
+	require.Equal(t, "0123456789", res.body)
+	require.Equal(t, "gzip", r.Header.Get(ekaweb.HeaderContentEncoding))
+
+	// No limit.
+
+	_, err = serve(newEncodedRequest("", strings.NewReader(compressBody)),
+		readHandler(&res), ekaweb_middleware.LimitRequestBody(0))
+
+	require.NoError(t, err)
+	require.Equal(t, compressBody, res.body)
+}
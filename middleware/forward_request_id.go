@@ -1,11 +1,15 @@
 package ekaweb_middleware
 
 import (
+	"context"
 	"net/http"
 
 	"github.com/inaneverb/ekaweb/v2"
 )
 
+// _RequestIDKey is a key for context.Context to store request ID.
+type _RequestIDKey struct{}
+
 // ForwardRequestID returns a new HTTP middleware, that will copy header
 // "X-Request-ID" from http.Request to the http.Response.
 //
@@ -15,6 +19,10 @@ import (
 // If 'fallback' is nil and no "X-Request-ID" header found in http.Request,
 // then it will do nothing about copying headers.
 //
+// The request ID is also stored in the http.Request's context.Context,
+// so it can be obtained by RequestIDFromContext() and forwarded
+// to the outgoing HTTP requests (see ekaweb_client.InjectRequestID()).
+//
 // Calls 'next' handler in any case.
 func ForwardRequestID(fallback func(r *http.Request) string) ekaweb.Middleware {
 
@@ -28,6 +36,7 @@ func ForwardRequestID(fallback func(r *http.Request) string) ekaweb.Middleware {
 
 			if reqID != "" {
 				w.Header().Set(ekaweb.HeaderXRequestID, reqID)
+				r = r.WithContext(context.WithValue(r.Context(), (*_RequestIDKey)(nil), reqID))
 			}
 
 			next.ServeHTTP(w, r)
@@ -36,3 +45,10 @@ func ForwardRequestID(fallback func(r *http.Request) string) ekaweb.Middleware {
 
 	return ekaweb.MiddlewareFuncNoErrorCheck(m)
 }
+
+// RequestIDFromContext returns request ID, that is stored
+// by ForwardRequestID() middleware, or an empty string if there's no one.
+func RequestIDFromContext(ctx context.Context) string {
+	var reqID, _ = ctx.Value((*_RequestIDKey)(nil)).(string)
+	return reqID
+}
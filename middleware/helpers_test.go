@@ -0,0 +1,18 @@
+package ekaweb_middleware_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/inaneverb/ekaweb/v2"
+	"github.com/inaneverb/ekaweb/v2/private"
+)
+
+// serve is ekaweb_private.ServeMiddlewares() to the new response recorder.
+func serve(
+	r *http.Request, handler http.Handler,
+	middlewares ...ekaweb.Middleware) (*httptest.ResponseRecorder, error) {
+
+	var w = httptest.NewRecorder()
+	return w, ekaweb_private.ServeMiddlewares(w, r, handler, middlewares...)
+}
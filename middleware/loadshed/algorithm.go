@@ -0,0 +1,106 @@
+package ekaweb_loadshed
+
+import (
+	"math"
+	"time"
+)
+
+type (
+	// Algorithm is an adaptive concurrency limit algorithm.
+	// Update is called after each HTTP handler execution with the current
+	// limit, the number of in-flight requests (including the finished one)
+	// and observed latency, returning a new limit.
+	// It's called under the Limiter's lock, so it doesn't have to be
+	// thread-safe.
+	Algorithm interface {
+		Update(limit, inFlight int, latency time.Duration) int
+	}
+
+	// _AIMD is an additive-increase/multiplicative-decrease Algorithm.
+	_AIMD struct {
+		threshold time.Duration
+		backoff   float64
+	}
+
+	// _Gradient is an Algorithm, that compares short-term (the last) latency
+	// with long-term (exponentially smoothed) one.
+	_Gradient struct {
+		smoothing float64
+		longRTT   float64 // in nanoseconds
+		limit     float64 // the precise value of the last returned limit
+	}
+)
+
+// NewAIMD returns an Algorithm, that increases the limit by 1 while latency
+// is below 'threshold', and multiplies it by 'backoff' (0 < backoff < 1)
+// if latency is greater. Invalid 'backoff' is replaced by 0.9.
+//
+// The limit is not increased if less than half of it is used.
+func NewAIMD(threshold time.Duration, backoff float64) Algorithm {
+	if backoff <= 0 || backoff >= 1 {
+		backoff = 0.9
+	}
+	return &_AIMD{threshold, backoff}
+}
+
+// NewGradient returns an Algorithm, that changes the limit proportionally
+// to the ratio of long-term latency to the last one. So, when latency grows,
+// the limit decreases, and vice versa. The 'smoothing' (0 < smoothing <= 1)
+// defines how fast the limit reacts. Invalid 'smoothing' is replaced by 0.2.
+//
+// The limit is not increased if less than half of it is used.
+func NewGradient(smoothing float64) Algorithm {
+	if smoothing <= 0 || smoothing > 1 {
+		smoothing = 0.2
+	}
+	return &_Gradient{smoothing: smoothing}
+}
+
+func (a *_AIMD) Update(limit, inFlight int, latency time.Duration) int {
+	switch {
+	case latency > a.threshold:
+		return int(float64(limit) * a.backoff)
+	case inFlight*2 >= limit:
+		return limit + 1
+	default:
+		return limit
+	}
+}
+
+func (a *_Gradient) Update(limit, inFlight int, latency time.Duration) int {
+
+	var rtt = float64(latency)
+	if rtt <= 0 {
+		return limit
+	}
+
+	if a.longRTT == 0 || a.limit == 0 {
+		a.longRTT, a.limit = rtt, float64(limit)
+		return limit
+	}
+
+	// Long-term latency is smoothed much slower than the limit,
+	// so it represents the latency under the "normal" load.
+
+	a.longRTT = a.longRTT*0.95 + rtt*0.05
+
+	var gradient = max(0.5, min(1.0, a.longRTT/rtt))
+	if gradient >= 1 && inFlight*2 < limit {
+		return limit // app-limited, no reason to grow
+	}
+
+	// The returned limit may be clamped by the Limiter (or changed
+	// by someone else), so start from the actual one in that case.
+	// Otherwise, the internal estimate grows without bound and never
+	// falls below the maximum limit.
+
+	if int(a.limit) != limit {
+		a.limit = float64(limit)
+	}
+
+	var queueSize = math.Sqrt(a.limit)
+	var newLimit = a.limit*gradient + queueSize
+
+	a.limit = a.limit*(1-a.smoothing) + newLimit*a.smoothing
+	return int(a.limit)
+}
@@ -0,0 +1,83 @@
+package ekaweb_loadshed_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/inaneverb/ekaweb/middleware/loadshed/v2"
+)
+
+func TestAIMD(t *testing.T) {
+
+	var a = ekaweb_loadshed.NewAIMD(100*time.Millisecond, 0.5)
+
+	require.Equal(t, 11, a.Update(10, 5, 10*time.Millisecond))
+	require.Equal(t, 10, a.Update(10, 4, 10*time.Millisecond), "app-limited")
+	require.Equal(t, 5, a.Update(10, 10, 200*time.Millisecond))
+	require.Equal(t, 5, a.Update(10, 1, 200*time.Millisecond))
+
+	a = ekaweb_loadshed.NewAIMD(100*time.Millisecond, 1)
+	require.Equal(t, 9, a.Update(10, 10, time.Second), "default backoff is 0.9")
+}
+
+func TestGradient(t *testing.T) {
+
+	var a = ekaweb_loadshed.NewGradient(1)
+
+	// The first observation is a baseline.
+
+	require.Equal(t, 16, a.Update(16, 16, 10*time.Millisecond))
+	require.Equal(t, 16, a.Update(16, 0, 0), "no latency")
+
+	// The same latency: the limit grows by the queue size (sqrt of the limit).
+
+	require.Equal(t, 20, a.Update(16, 16, 10*time.Millisecond))
+
+	// App-limited: the limit is not grown.
+
+	require.Equal(t, 20, a.Update(20, 2, 10*time.Millisecond))
+
+	// Latency grows: the limit decreases, but no more than twice at once.
+
+	var limit = a.Update(20, 20, time.Second)
+	require.Less(t, limit, 20)
+	require.GreaterOrEqual(t, limit, 10)
+
+	// Smoothing.
+
+	a = ekaweb_loadshed.NewGradient(0.5)
+	require.Equal(t, 16, a.Update(16, 16, 10*time.Millisecond))
+	require.Equal(t, 18, a.Update(16, 16, 10*time.Millisecond))
+}
+
+func TestGradient_Clamped(t *testing.T) {
+
+	const MaxLimit = 20
+
+	var a = ekaweb_loadshed.NewGradient(0.2)
+	var limit = 16
+
+	var update = func(latency time.Duration) {
+		limit = min(MaxLimit, a.Update(limit, limit, latency))
+	}
+
+	// The limit is clamped by the maximum for a long time.
+
+	for i := 0; i < 1000; i++ {
+		update(10 * time.Millisecond)
+	}
+	require.Equal(t, MaxLimit, limit)
+
+	// Latency grows: the limit falls right away, below the maximum.
+
+	update(100 * time.Millisecond)
+	require.Less(t, limit, MaxLimit)
+
+	var prev = limit
+	for i := 0; i < 5; i++ {
+		update(100 * time.Millisecond)
+	}
+	require.Less(t, limit, prev)
+}
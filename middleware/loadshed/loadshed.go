@@ -0,0 +1,203 @@
+package ekaweb_loadshed
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/inaneverb/ekaweb/v2"
+	"github.com/inaneverb/ekaweb/v2/private"
+)
+
+type (
+	// Limiter is an HTTP middleware, that caps the number of concurrently
+	// executing HTTP handlers. Requests over the limit wait in the queue
+	// and are shed if the queue is full or they wait too long.
+	//
+	// Each Limiter has its own limit, so register different Limiter
+	// for each route group you want to limit independently.
+	// The counters are exposed by the Limiter's methods (for metrics).
+	Limiter struct {
+		mu       sync.Mutex
+		limit    int
+		inFlight int
+		waiters  []chan struct{}
+		shed     uint64
+
+		queueSize    int
+		queueTimeout time.Duration
+		retryAfter   time.Duration
+
+		algorithm          Algorithm
+		minLimit, maxLimit int
+	}
+
+	// OverloadedError is an error, that is stored to the http.Request's
+	// context.Context, when the HTTP request is shed. Suits 503 HTTP status.
+	OverloadedError struct {
+		RetryAfter time.Duration
+	}
+)
+
+// ErrOverloaded is a typed OverloadedError "pattern".
+// Use errors.Is(err, ErrOverloaded) to check whether an error is OverloadedError.
+var ErrOverloaded = (*OverloadedError)(nil)
+
+// New returns a new Limiter, that allows 100 concurrently executing
+// HTTP handlers by default w/o a queue (see WithLimit(), WithQueue()).
+//
+// If the HTTP request is shed, OverloadedError is stored to the
+// http.Request's context.Context (see ErrorApply()), Retry-After HTTP header
+// is applied and 'next' handler is not called.
+func New(options ...Option) *Limiter {
+
+	var l = Limiter{limit: 100, retryAfter: time.Second}
+
+	for _, option := range options {
+		if option != nil {
+			option(&l)
+		}
+	}
+
+	if l.algorithm != nil {
+		l.limit = max(l.minLimit, min(l.maxLimit, l.limit))
+	}
+
+	return &l
+}
+
+func (l *Limiter) Callback(next ekaweb.Handler) ekaweb.Handler {
+	return ekaweb.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+
+		if !l.acquire(r.Context()) {
+			var retryAfter = (l.retryAfter + time.Second - 1) / time.Second
+			w.Header().Set(ekaweb.HeaderRetryAfter, strconv.Itoa(int(retryAfter)))
+
+			var err = &OverloadedError{l.retryAfter}
+			ekaweb_private.UkvsInsertUserError(r.Context(), err)
+			return
+		}
+
+		var start = time.Now()
+		defer func() { l.release(time.Since(start)) }()
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (l *Limiter) CheckErrorBefore() bool { return false }
+
+// Limit returns the current concurrency limit.
+func (l *Limiter) Limit() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.limit
+}
+
+// InFlight returns the number of currently executing HTTP handlers.
+func (l *Limiter) InFlight() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.inFlight
+}
+
+// Queued returns the number of HTTP requests waiting in the queue.
+func (l *Limiter) Queued() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return len(l.waiters)
+}
+
+// Shed returns the total number of shed HTTP requests.
+func (l *Limiter) Shed() uint64 {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.shed
+}
+
+func (e *OverloadedError) Error() string {
+	return "Middleware.LoadShed: Service overloaded"
+}
+
+func (e *OverloadedError) Is(other error) bool {
+	var _, ok = other.(*OverloadedError)
+	return ok
+}
+
+////////////////////////////////////////////////////////////////////////////////
+///// PRIVATE METHODS //////////////////////////////////////////////////////////
+////////////////////////////////////////////////////////////////////////////////
+
+// acquire tries to take a slot for the HTTP handler execution,
+// waiting in the queue if it's allowed. Reports whether a slot is taken.
+func (l *Limiter) acquire(ctx context.Context) bool {
+
+	l.mu.Lock()
+
+	switch {
+	case l.inFlight < l.limit:
+		l.inFlight++
+		l.mu.Unlock()
+		return true
+
+	case len(l.waiters) >= l.queueSize:
+		l.shed++
+		l.mu.Unlock()
+		return false
+	}
+
+	var ch = make(chan struct{})
+	l.waiters = append(l.waiters, ch)
+	l.mu.Unlock()
+
+	var timer <-chan time.Time
+	if l.queueTimeout > 0 {
+		var t = time.NewTimer(l.queueTimeout)
+		defer t.Stop()
+		timer = t.C
+	}
+
+	select {
+	case <-ch:
+		return true
+	case <-timer:
+	case <-ctx.Done():
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	for i := range l.waiters {
+		if l.waiters[i] == ch {
+			l.waiters = append(l.waiters[:i], l.waiters[i+1:]...)
+			l.shed++
+			return false
+		}
+	}
+
+	return true // the slot has been granted concurrently
+}
+
+// release frees the slot, updating the limit (if it's adaptive)
+// and waking up the queued HTTP requests.
+func (l *Limiter) release(latency time.Duration) {
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.algorithm != nil {
+		var limit = l.algorithm.Update(l.limit, l.inFlight, latency)
+		l.limit = max(l.minLimit, min(l.maxLimit, limit))
+	}
+
+	l.inFlight--
+
+	for l.inFlight < l.limit && len(l.waiters) > 0 {
+		l.inFlight++
+		close(l.waiters[0])
+		l.waiters[0] = nil
+		l.waiters = l.waiters[1:]
+	}
+}
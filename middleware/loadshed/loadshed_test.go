@@ -0,0 +1,225 @@
+package ekaweb_loadshed_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/inaneverb/ekaweb/middleware/loadshed/v2"
+	"github.com/inaneverb/ekaweb/v2"
+	"github.com/inaneverb/ekaweb/v2/private"
+)
+
+// serve performs HTTP request with 'ctx' by 'handler', limited by 'l'.
+// Returns the response and the stored error.
+func serve(
+	ctx context.Context, l *ekaweb_loadshed.Limiter,
+	handler http.Handler) (*httptest.ResponseRecorder, error) {
+
+	var w = httptest.NewRecorder()
+	var r = httptest.NewRequest(http.MethodGet, "/", nil).WithContext(ctx)
+
+	return w, ekaweb_private.ServeMiddlewares(w, r, handler, l)
+}
+
+// blockingHandler returns http.Handler, that blocks until 'unblock'
+// is closed or receives a value.
+func blockingHandler(unblock <-chan struct{}) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		<-unblock
+		w.WriteHeader(http.StatusOK)
+	})
+}
+
+// serveAsync is the same as serve(), but in background.
+// The returned channel receives the stored error.
+func serveAsync(l *ekaweb_loadshed.Limiter, handler http.Handler) <-chan error {
+	var ch = make(chan error, 1)
+	go func() {
+		var _, err = serve(context.Background(), l, handler)
+		ch <- err
+	}()
+	return ch
+}
+
+// waitFor waits until 'cond' becomes true.
+func waitFor(t *testing.T, cond func() bool) {
+	require.Eventually(t, cond, 5*time.Second, time.Millisecond)
+}
+
+// fixedAlgorithm is an ekaweb_loadshed.Algorithm, that always returns
+// the same limit, recording calls.
+type fixedAlgorithm struct {
+	limit int
+	calls []int // in-flight requests
+}
+
+func (a *fixedAlgorithm) Update(_, inFlight int, _ time.Duration) int {
+	a.calls = append(a.calls, inFlight)
+	return a.limit
+}
+
+func TestLimiter_QueueOverflow(t *testing.T) {
+
+	var l = ekaweb_loadshed.New(
+		ekaweb_loadshed.WithLimit(1),
+		ekaweb_loadshed.WithQueue(1, 0),
+		ekaweb_loadshed.WithRetryAfter(1500*time.Millisecond),
+	)
+
+	var unblock = make(chan struct{})
+	var handler = blockingHandler(unblock)
+
+	var first = serveAsync(l, handler)
+	waitFor(t, func() bool { return l.InFlight() == 1 })
+
+	var second = serveAsync(l, handler)
+	waitFor(t, func() bool { return l.Queued() == 1 })
+
+	// Both of the slot and the queue are taken.
+
+	var w, err = serve(context.Background(), l, handler)
+	require.ErrorIs(t, err, ekaweb_loadshed.ErrOverloaded)
+	require.Equal(t, "2", w.Header().Get(ekaweb.HeaderRetryAfter))
+	require.Equal(t, uint64(1), l.Shed())
+
+	var overloaded *ekaweb_loadshed.OverloadedError
+	require.ErrorAs(t, err, &overloaded)
+	require.Equal(t, 1500*time.Millisecond, overloaded.RetryAfter)
+
+	// The queued request takes the slot, when it's freed.
+
+	unblock <- struct{}{}
+	require.NoError(t, <-first)
+
+	waitFor(t, func() bool { return l.Queued() == 0 })
+	require.Equal(t, 1, l.InFlight())
+
+	unblock <- struct{}{}
+	require.NoError(t, <-second)
+
+	require.Equal(t, 0, l.InFlight())
+	require.Equal(t, uint64(1), l.Shed())
+}
+
+func TestLimiter_NoQueue(t *testing.T) {
+
+	var l = ekaweb_loadshed.New(ekaweb_loadshed.WithLimit(1))
+
+	var unblock = make(chan struct{})
+	var first = serveAsync(l, blockingHandler(unblock))
+	waitFor(t, func() bool { return l.InFlight() == 1 })
+
+	var w, err = serve(context.Background(), l, blockingHandler(unblock))
+	require.ErrorIs(t, err, ekaweb_loadshed.ErrOverloaded)
+	require.Equal(t, "1", w.Header().Get(ekaweb.HeaderRetryAfter))
+
+	close(unblock)
+	require.NoError(t, <-first)
+
+	_, err = serve(context.Background(), l, blockingHandler(unblock))
+	require.NoError(t, err)
+}
+
+func TestLimiter_QueueTimeout(t *testing.T) {
+
+	var l = ekaweb_loadshed.New(
+		ekaweb_loadshed.WithLimit(1),
+		ekaweb_loadshed.WithQueue(1, 10*time.Millisecond),
+	)
+
+	var unblock = make(chan struct{})
+	var first = serveAsync(l, blockingHandler(unblock))
+	waitFor(t, func() bool { return l.InFlight() == 1 })
+
+	var called bool
+	var handler = http.HandlerFunc(func(http.ResponseWriter, *http.Request) {
+		called = true
+	})
+
+	var _, err = serve(context.Background(), l, handler)
+	require.ErrorIs(t, err, ekaweb_loadshed.ErrOverloaded)
+	require.False(t, called)
+	require.Equal(t, 0, l.Queued())
+	require.Equal(t, uint64(1), l.Shed())
+
+	// Canceled HTTP request leaves the queue too.
+
+	l = ekaweb_loadshed.New(ekaweb_loadshed.WithLimit(1), ekaweb_loadshed.WithQueue(1, 0))
+
+	var second = serveAsync(l, blockingHandler(unblock))
+	waitFor(t, func() bool { return l.InFlight() == 1 })
+
+	var ctx, cancel = context.WithCancel(context.Background())
+	var canceled = make(chan error, 1)
+
+	go func() {
+		var _, err = serve(ctx, l, handler)
+		canceled <- err
+	}()
+
+	waitFor(t, func() bool { return l.Queued() == 1 })
+	cancel()
+
+	require.ErrorIs(t, <-canceled, ekaweb_loadshed.ErrOverloaded)
+	require.False(t, called)
+	require.Equal(t, 0, l.Queued())
+
+	close(unblock)
+	require.NoError(t, <-first)
+	require.NoError(t, <-second)
+}
+
+func TestLimiter_Adaptive(t *testing.T) {
+
+	// The initial limit is kept in range.
+
+	var algorithm = &fixedAlgorithm{limit: 100}
+	var l = ekaweb_loadshed.New(
+		ekaweb_loadshed.WithLimit(10),
+		ekaweb_loadshed.WithAdaptive(algorithm, 1, 3),
+		ekaweb_loadshed.WithQueue(5, 0),
+	)
+
+	require.Equal(t, 3, l.Limit())
+
+	algorithm.limit = 1
+
+	var unblock = make(chan struct{})
+	var handler = blockingHandler(unblock)
+
+	var requests []<-chan error
+	for i := 0; i < 5; i++ {
+		requests = append(requests, serveAsync(l, handler))
+	}
+
+	waitFor(t, func() bool { return l.InFlight() == 3 && l.Queued() == 2 })
+
+	// The limit is decreased, so the queued requests must wait.
+
+	unblock <- struct{}{}
+	waitFor(t, func() bool { return l.InFlight() == 2 })
+	require.Equal(t, 1, l.Limit())
+	require.Equal(t, 2, l.Queued())
+
+	// The limit is increased (but not above the max),
+	// so the queued requests are woken up at once.
+
+	algorithm.limit = 100
+	unblock <- struct{}{}
+	waitFor(t, func() bool { return l.Queued() == 0 })
+	require.Equal(t, 3, l.Limit())
+	require.Equal(t, 3, l.InFlight())
+
+	close(unblock)
+	for _, ch := range requests {
+		require.NoError(t, <-ch)
+	}
+
+	require.Equal(t, 0, l.InFlight())
+	require.Equal(t, []int{3, 2, 3, 2, 1}, algorithm.calls)
+}
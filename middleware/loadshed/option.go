@@ -0,0 +1,49 @@
+package ekaweb_loadshed
+
+import (
+	"time"
+)
+
+// Option is a callback that allows to modify Limiter under its construction.
+type Option func(l *Limiter)
+
+// WithLimit sets the max number of concurrently executing HTTP handlers.
+// If the limit is adaptive, it's an initial limit. Default: 100.
+func WithLimit(limit int) Option {
+	return func(l *Limiter) {
+		if limit > 0 {
+			l.limit = limit
+		}
+	}
+}
+
+// WithQueue allows up to 'size' HTTP requests to wait for a free slot
+// no longer than 'timeout' (0 means until the request is canceled).
+// By default, there's no queue and the HTTP request is shed immediately.
+func WithQueue(size int, timeout time.Duration) Option {
+	return func(l *Limiter) {
+		if size >= 0 && timeout >= 0 {
+			l.queueSize, l.queueTimeout = size, timeout
+		}
+	}
+}
+
+// WithAdaptive makes the limit adaptive, using given Algorithm (see NewAIMD(),
+// NewGradient()). The limit is always kept in range [minLimit..maxLimit].
+func WithAdaptive(algorithm Algorithm, minLimit, maxLimit int) Option {
+	return func(l *Limiter) {
+		if algorithm != nil && minLimit > 0 && maxLimit >= minLimit {
+			l.algorithm, l.minLimit, l.maxLimit = algorithm, minLimit, maxLimit
+		}
+	}
+}
+
+// WithRetryAfter sets a value of Retry-After HTTP header,
+// that is applied to the shed HTTP requests. Default: 1s.
+func WithRetryAfter(retryAfter time.Duration) Option {
+	return func(l *Limiter) {
+		if retryAfter > 0 {
+			l.retryAfter = retryAfter
+		}
+	}
+}
@@ -0,0 +1,175 @@
+package ekaweb_metrics
+
+import (
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/inaneverb/ekaweb/v2"
+	"github.com/inaneverb/ekaweb/v2/private"
+)
+
+type (
+	middleware struct {
+		next ekaweb.Handler
+
+		registry       *Registry
+		namespace      string
+		buckets        []float64
+		sizeBuckets    []float64
+		errorStatusGet func(err error) int
+
+		requests     *CounterVec
+		duration     *HistogramVec
+		inFlight     *GaugeVec
+		requestSize  *HistogramVec
+		responseSize *HistogramVec
+	}
+
+	// _ProxyBody wraps original HTTP request body,
+	// recording the number of read bytes.
+	_ProxyBody struct {
+		orig io.ReadCloser
+		size int64
+	}
+)
+
+// RouteUnmatched is a "route" label value for HTTP requests,
+// that have no registered route (404, 405).
+// It prevents high cardinality of metrics, caused by random paths.
+const RouteUnmatched = "<unmatched>"
+
+// New returns a new HTTP middleware, that records metrics of HTTP requests:
+//   - <namespace>_requests_total (counter);
+//   - <namespace>_request_duration_seconds (histogram);
+//   - <namespace>_requests_in_flight (gauge);
+//   - <namespace>_request_size_bytes (histogram);
+//   - <namespace>_response_size_bytes (histogram).
+//
+// Metrics are labelled by HTTP method, route (ekaweb.RoutePath()),
+// HTTP status code and error presence (ekaweb.ErrorGet()). The in-flight gauge
+// is labelled by HTTP method only, because route is unknown before routing.
+// Use Registry.Handler() or Handler() to expose them.
+//
+// Metrics are registered in the Registry immediately, so this constructor
+// panics if it's called twice with the same Registry and namespace.
+// Create it once and use the same middleware for all routers.
+// Has no error check before.
+func New(options ...Option) ekaweb.Middleware {
+
+	var m = middleware{
+		registry:    DefaultRegistry,
+		namespace:   "http_server",
+		buckets:     DefBuckets,
+		sizeBuckets: DefSizeBuckets,
+	}
+
+	for _, option := range options {
+		if option != nil {
+			option(&m)
+		}
+	}
+
+	var ns = m.namespace + "_"
+	var labels = []string{"method", "route", "status", "error"}
+
+	m.requests = NewCounterVec(ns+"requests_total",
+		"Total number of HTTP requests.", labels...)
+	m.duration = NewHistogramVec(ns+"request_duration_seconds",
+		"Duration of HTTP requests in seconds.", m.buckets, labels...)
+	m.inFlight = NewGaugeVec(ns+"requests_in_flight",
+		"Number of HTTP requests being processed.", "method")
+	m.requestSize = NewHistogramVec(ns+"request_size_bytes",
+		"Size of HTTP request bodies in bytes.", m.sizeBuckets, "method", "route")
+	m.responseSize = NewHistogramVec(ns+"response_size_bytes",
+		"Size of HTTP response bodies in bytes.", m.sizeBuckets, labels...)
+
+	m.registry.MustRegister(m.requests, m.duration, m.inFlight, m.requestSize, m.responseSize)
+	return &m
+}
+
+func (m middleware) Callback(next ekaweb.Handler) ekaweb.Handler {
+	m.next = next
+	return ekaweb.HandlerFunc(m.serveHTTP)
+}
+
+func (m middleware) CheckErrorBefore() bool { return false }
+
+////////////////////////////////////////////////////////////////////////////////
+///// _ProxyBody ///////////////////////////////////////////////////////////////
+////////////////////////////////////////////////////////////////////////////////
+
+func (b *_ProxyBody) Read(p []byte) (int, error) {
+	var n, err = b.orig.Read(p)
+	b.size += int64(n)
+	return n, err
+}
+
+func (b *_ProxyBody) Close() error {
+	return b.orig.Close()
+}
+
+////////////////////////////////////////////////////////////////////////////////
+///// PRIVATE METHODS //////////////////////////////////////////////////////////
+////////////////////////////////////////////////////////////////////////////////
+
+func (m middleware) serveHTTP(w http.ResponseWriter, r *http.Request) {
+
+	var method = normalizeMethod(r.Method)
+	var inFlight = m.inFlight.With(method)
+
+	inFlight.Inc()
+	defer inFlight.Dec()
+
+	var body *_ProxyBody
+	if r.Body != nil && r.Body != http.NoBody {
+		body = &_ProxyBody{orig: r.Body}
+		r.Body = body
+	}
+
+	var resp = ekaweb_private.NewStatusRecorder(w)
+	var start = time.Now()
+
+	m.next.ServeHTTP(resp, r)
+
+	var elapsed = time.Since(start)
+	var ctx = r.Context()
+
+	var route = RouteUnmatched
+	if !ekaweb_private.UkvsIsPathNotFoundOrNotAllowed(ctx) {
+		route = ekaweb.RoutePath(r)
+	}
+
+	var hasError = "false"
+	if ekaweb.ErrorGet(r) != nil {
+		hasError = "true"
+	}
+
+	var status = strconv.Itoa(resp.StatusCode(ctx, m.errorStatusGet))
+
+	m.requests.With(method, route, status, hasError).Inc()
+	m.duration.With(method, route, status, hasError).Observe(elapsed.Seconds())
+	m.responseSize.With(method, route, status, hasError).Observe(float64(resp.Size()))
+
+	var requestSize = max(r.ContentLength, 0)
+	if body != nil {
+		requestSize = max(requestSize, body.size)
+	}
+	m.requestSize.With(method, route).Observe(float64(requestSize))
+}
+
+// normalizeMethod returns HTTP method as is, if it's a standard one,
+// or "OTHER" otherwise (preventing high cardinality of metrics).
+func normalizeMethod(method string) string {
+	switch method {
+	case ekaweb.MethodGet, ekaweb.MethodHead, ekaweb.MethodPost,
+		ekaweb.MethodPut, ekaweb.MethodPatch, ekaweb.MethodDelete,
+		ekaweb.MethodConnect, ekaweb.MethodOptions, ekaweb.MethodTrace:
+
+		return method
+
+	default:
+		return "OTHER"
+	}
+}
@@ -0,0 +1,81 @@
+package ekaweb_metrics_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/inaneverb/ekaweb/middleware/metrics/v2"
+	"github.com/inaneverb/ekaweb/v2"
+	"github.com/inaneverb/ekaweb/v2/private"
+)
+
+// samples returns the lines of exposition of 'registry',
+// that start with 'prefix'.
+func samples(t *testing.T, registry *ekaweb_metrics.Registry, prefix string) []string {
+	var out []string
+	for _, line := range strings.Split(scrape(t, registry), "\n") {
+		if strings.HasPrefix(line, prefix) {
+			out = append(out, line)
+		}
+	}
+	return out
+}
+
+func TestMetrics(t *testing.T) {
+
+	var registry = ekaweb_metrics.NewRegistry()
+	var m = ekaweb_metrics.New(
+		ekaweb_metrics.WithRegistry(registry),
+		ekaweb_metrics.WithNamespace("app"),
+		ekaweb_metrics.WithSizeBuckets(10),
+		ekaweb_metrics.WithErrorStatus(func(error) int { return http.StatusUnprocessableEntity }),
+	)
+
+	var r = httptest.NewRequest(http.MethodPost, "/users", strings.NewReader("12345"))
+	var err = ekaweb_private.ServeMiddlewares(httptest.NewRecorder(), r,
+		http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			w.WriteHeader(http.StatusCreated)
+			_, _ = w.Write([]byte("created"))
+		}), m)
+
+	require.NoError(t, err)
+
+	// The error is stored, but the response is up to the error handler.
+
+	r = httptest.NewRequest("PROPFIND", "/users", nil)
+	err = ekaweb_private.ServeMiddlewares(httptest.NewRecorder(), r,
+		http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+			ekaweb.ErrorApply(r, errors.New("invalid"))
+		}), m)
+
+	require.EqualError(t, err, "invalid")
+
+	require.Equal(t, []string{
+		`app_requests_total{method="OTHER",route="/users",status="422",error="true"} 1`,
+		`app_requests_total{method="POST",route="/users",status="201",error="false"} 1`,
+	}, samples(t, registry, "app_requests_total"))
+
+	require.Equal(t, []string{
+		`app_response_size_bytes_bucket{method="OTHER",route="/users",status="422",error="true",le="10"} 1`,
+		`app_response_size_bytes_bucket{method="OTHER",route="/users",status="422",error="true",le="+Inf"} 1`,
+		`app_response_size_bytes_sum{method="OTHER",route="/users",status="422",error="true"} 0`,
+		`app_response_size_bytes_count{method="OTHER",route="/users",status="422",error="true"} 1`,
+		`app_response_size_bytes_bucket{method="POST",route="/users",status="201",error="false",le="10"} 1`,
+		`app_response_size_bytes_bucket{method="POST",route="/users",status="201",error="false",le="+Inf"} 1`,
+		`app_response_size_bytes_sum{method="POST",route="/users",status="201",error="false"} 7`,
+		`app_response_size_bytes_count{method="POST",route="/users",status="201",error="false"} 1`,
+	}, samples(t, registry, "app_response_size_bytes"))
+
+	require.Contains(t, samples(t, registry, "app_request_size_bytes_sum"),
+		`app_request_size_bytes_sum{method="POST",route="/users"} 5`)
+
+	require.Equal(t, []string{
+		`app_requests_in_flight{method="OTHER"} 0`,
+		`app_requests_in_flight{method="POST"} 0`,
+	}, samples(t, registry, "app_requests_in_flight"))
+}
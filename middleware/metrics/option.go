@@ -0,0 +1,55 @@
+package ekaweb_metrics
+
+// Option is a callback that allows to modify Middleware under its construction.
+type Option func(m *middleware)
+
+// WithRegistry sets a Registry, metrics are registered in.
+// Default: DefaultRegistry.
+func WithRegistry(registry *Registry) Option {
+	return func(m *middleware) {
+		if registry != nil {
+			m.registry = registry
+		}
+	}
+}
+
+// WithNamespace sets a prefix of metrics names. Default: "http_server".
+func WithNamespace(namespace string) Option {
+	return func(m *middleware) {
+		if isValidName(namespace) {
+			m.namespace = namespace
+		}
+	}
+}
+
+// WithDurationBuckets sets upper bounds (in seconds) of buckets
+// of the request duration histogram. Default: DefBuckets.
+func WithDurationBuckets(buckets ...float64) Option {
+	return func(m *middleware) {
+		if len(buckets) > 0 {
+			m.buckets = buckets
+		}
+	}
+}
+
+// WithSizeBuckets sets upper bounds (in bytes) of buckets
+// of the request and response size histograms. Default: DefSizeBuckets.
+func WithSizeBuckets(buckets ...float64) Option {
+	return func(m *middleware) {
+		if len(buckets) > 0 {
+			m.sizeBuckets = buckets
+		}
+	}
+}
+
+// WithErrorStatus sets a callback, that maps the error of HTTP request,
+// which has no response written yet, to the "status" label value.
+// Use the same mapping as your error handler does (like ekaweb_respondent),
+// so the metrics match the real responses. Default: 500 for any error.
+func WithErrorStatus(cb func(err error) int) Option {
+	return func(m *middleware) {
+		if cb != nil {
+			m.errorStatusGet = cb
+		}
+	}
+}
@@ -0,0 +1,189 @@
+package ekaweb_metrics
+
+import (
+	"bufio"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/inaneverb/ekaweb/v2"
+)
+
+type (
+	// Registry is a set of metrics, that are exposed together
+	// in the Prometheus text exposition format (see Registry.Handler()).
+	Registry struct {
+		mu         sync.RWMutex
+		collectors []Collector
+		names      map[string]struct{}
+	}
+
+	// Collector is a metric, that may be registered in the Registry.
+	// *CounterVec, *GaugeVec and *HistogramVec are Collectors.
+	Collector interface {
+		metricName() string
+		writeTo(w *bufio.Writer)
+	}
+)
+
+// ContentType is a MIME type of the Prometheus text exposition format.
+const ContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// DefaultRegistry is a Registry, that is used by default.
+var DefaultRegistry = NewRegistry()
+
+// NewRegistry returns a new empty Registry.
+func NewRegistry() *Registry {
+	return &Registry{names: make(map[string]struct{})}
+}
+
+// Handler returns an HTTP handler, that serves metrics of the DefaultRegistry.
+func Handler() ekaweb.Handler {
+	return DefaultRegistry.Handler()
+}
+
+// MustRegister registers given collectors.
+// Panics if the metric with the same name is already registered.
+func (r *Registry) MustRegister(collectors ...Collector) {
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, c := range collectors {
+		var name = c.metricName()
+		if _, found := r.names[name]; found {
+			panic("Middleware.Metrics: Metric already registered: " + name)
+		}
+		r.names[name] = struct{}{}
+		r.collectors = append(r.collectors, c)
+	}
+}
+
+// Handler returns an HTTP handler, that serves all registered metrics
+// in the Prometheus text exposition format.
+func (r *Registry) Handler() ekaweb.Handler {
+	return ekaweb.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+
+		r.mu.RLock()
+		var collectors = append([]Collector(nil), r.collectors...)
+		r.mu.RUnlock()
+
+		w.Header().Set(ekaweb.HeaderContentType, ContentType)
+		w.WriteHeader(http.StatusOK)
+
+		var bw = bufio.NewWriter(w)
+		for _, c := range collectors {
+			c.writeTo(bw)
+		}
+		_ = bw.Flush()
+	})
+}
+
+////////////////////////////////////////////////////////////////////////////////
+///// PRIVATE METHODS //////////////////////////////////////////////////////////
+////////////////////////////////////////////////////////////////////////////////
+
+func (v *vec[T]) metricName() string {
+	return v.name
+}
+
+func (v *CounterVec) writeTo(w *bufio.Writer) {
+	writeHeader(w, v.name, v.help, "counter")
+	for _, s := range v.snapshot() {
+		writeSample(w, v.name, v.labels, s.labelValues, "", "", s.metric.Value())
+	}
+}
+
+func (v *GaugeVec) writeTo(w *bufio.Writer) {
+	writeHeader(w, v.name, v.help, "gauge")
+	for _, s := range v.snapshot() {
+		writeSample(w, v.name, v.labels, s.labelValues, "", "", s.metric.Value())
+	}
+}
+
+func (v *HistogramVec) writeTo(w *bufio.Writer) {
+	writeHeader(w, v.name, v.help, "histogram")
+
+	for _, s := range v.snapshot() {
+		var h = s.metric
+		var cumulative uint64
+
+		// Read count first: buckets may be slightly ahead, but never behind.
+		var count = h.count.Load()
+
+		for i, upperBound := range h.upperBounds {
+			cumulative += h.buckets[i].Load()
+			var le = formatFloat(upperBound)
+			writeSample(w, v.name+"_bucket", v.labels, s.labelValues, "le", le, float64(min(cumulative, count)))
+		}
+
+		writeSample(w, v.name+"_bucket", v.labels, s.labelValues, "le", "+Inf", float64(count))
+		writeSample(w, v.name+"_sum", v.labels, s.labelValues, "", "", math.Float64frombits(h.sum.Load()))
+		writeSample(w, v.name+"_count", v.labels, s.labelValues, "", "", float64(count))
+	}
+}
+
+func writeHeader(w *bufio.Writer, name, help, typ string) {
+	if help != "" {
+		w.WriteString("# HELP " + name + " ")
+		w.WriteString(escape(help, false))
+		w.WriteByte('\n')
+	}
+	w.WriteString("# TYPE " + name + " " + typ + "\n")
+}
+
+func writeSample(
+	w *bufio.Writer, name string, labels, labelValues []string,
+	extraLabel, extraValue string, value float64) {
+
+	w.WriteString(name)
+
+	if len(labels) > 0 || extraLabel != "" {
+		w.WriteByte('{')
+		for i := range labels {
+			if i > 0 {
+				w.WriteByte(',')
+			}
+			w.WriteString(labels[i] + `="` + escape(labelValues[i], true) + `"`)
+		}
+		if extraLabel != "" {
+			if len(labels) > 0 {
+				w.WriteByte(',')
+			}
+			w.WriteString(extraLabel + `="` + extraValue + `"`)
+		}
+		w.WriteByte('}')
+	}
+
+	w.WriteByte(' ')
+	w.WriteString(formatFloat(value))
+	w.WriteByte('\n')
+}
+
+// escape escapes backslashes and line feeds (and double quotes for
+// label values) according to the Prometheus text exposition format.
+func escape(s string, isLabelValue bool) string {
+	if !strings.ContainsAny(s, "\\\n\"") {
+		return s
+	}
+	var r = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+	if isLabelValue {
+		r = strings.NewReplacer(`\`, `\\`, "\n", `\n`, `"`, `\"`)
+	}
+	return r.Replace(s)
+}
+
+func formatFloat(v float64) string {
+	switch {
+	case math.IsInf(v, +1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	case math.IsNaN(v):
+		return "NaN"
+	default:
+		return strconv.FormatFloat(v, 'g', -1, 64)
+	}
+}
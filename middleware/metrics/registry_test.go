@@ -0,0 +1,105 @@
+package ekaweb_metrics_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/inaneverb/ekaweb/middleware/metrics/v2"
+	"github.com/inaneverb/ekaweb/v2"
+)
+
+// scrape returns the exposition of all metrics of 'registry'.
+func scrape(t *testing.T, registry *ekaweb_metrics.Registry) string {
+
+	var w = httptest.NewRecorder()
+	registry.Handler().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Equal(t, ekaweb_metrics.ContentType, w.Header().Get(ekaweb.HeaderContentType))
+
+	return w.Body.String()
+}
+
+func TestRegistry(t *testing.T) {
+
+	var registry = ekaweb_metrics.NewRegistry()
+
+	var counter = ekaweb_metrics.NewCounterVec("requests_total",
+		"Total \\ requests.\nSecond \"line\".", "path")
+	var gauge = ekaweb_metrics.NewGaugeVec("in_flight", "")
+	var histogram = ekaweb_metrics.NewHistogramVec("duration_seconds",
+		"Duration.", []float64{1, 0.1, 0.5}, "method")
+
+	registry.MustRegister(counter, gauge, histogram)
+
+	counter.With("/a\"b\\c\n").Inc()
+	counter.With("/").Add(2.5)
+	counter.With("/").Add(-1) // ignored
+
+	gauge.With().Set(3)
+	gauge.With().Dec()
+
+	for _, v := range []float64{0.25, 0.5, 2} {
+		histogram.With("GET").Observe(v)
+	}
+	histogram.With("POST").Observe(0.01)
+
+	const expected = `# HELP requests_total Total \\ requests.\nSecond "line".
+# TYPE requests_total counter
+requests_total{path="/"} 2.5
+requests_total{path="/a\"b\\c\n"} 1
+# TYPE in_flight gauge
+in_flight 2
+# HELP duration_seconds Duration.
+# TYPE duration_seconds histogram
+duration_seconds_bucket{method="GET",le="0.1"} 0
+duration_seconds_bucket{method="GET",le="0.5"} 2
+duration_seconds_bucket{method="GET",le="1"} 2
+duration_seconds_bucket{method="GET",le="+Inf"} 3
+duration_seconds_sum{method="GET"} 2.75
+duration_seconds_count{method="GET"} 3
+duration_seconds_bucket{method="POST",le="0.1"} 1
+duration_seconds_bucket{method="POST",le="0.5"} 1
+duration_seconds_bucket{method="POST",le="1"} 1
+duration_seconds_bucket{method="POST",le="+Inf"} 1
+duration_seconds_sum{method="POST"} 0.01
+duration_seconds_count{method="POST"} 1
+`
+
+	require.Equal(t, expected, scrape(t, registry))
+	require.Equal(t, 2.5, counter.With("/").Value())
+	require.Equal(t, uint64(3), histogram.With("GET").Count())
+}
+
+func TestRegistry_NoLabels(t *testing.T) {
+
+	var registry = ekaweb_metrics.NewRegistry()
+	var histogram = ekaweb_metrics.NewHistogramVec("size_bytes", "", []float64{10})
+
+	registry.MustRegister(histogram)
+	histogram.With().Observe(100)
+
+	const expected = `# TYPE size_bytes histogram
+size_bytes_bucket{le="10"} 0
+size_bytes_bucket{le="+Inf"} 1
+size_bytes_sum 100
+size_bytes_count 1
+`
+
+	require.Equal(t, expected, scrape(t, registry))
+}
+
+func TestRegistry_Panic(t *testing.T) {
+
+	var registry = ekaweb_metrics.NewRegistry()
+	registry.MustRegister(ekaweb_metrics.NewCounterVec("total", ""))
+
+	require.Panics(t, func() { registry.MustRegister(ekaweb_metrics.NewGaugeVec("total", "")) })
+	require.Panics(t, func() { ekaweb_metrics.NewCounterVec("1total", "") })
+	require.Panics(t, func() { ekaweb_metrics.NewCounterVec("total-count", "") })
+	require.Panics(t, func() { ekaweb_metrics.NewHistogramVec("h", "", nil, "le") })
+	require.Panics(t, func() { ekaweb_metrics.NewCounterVec("total", "", "a").With() })
+}
@@ -0,0 +1,264 @@
+package ekaweb_metrics
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+type (
+	// Counter is a monotonically increasing metric.
+	Counter struct {
+		bits atomic.Uint64 // float64 bits
+	}
+
+	// Gauge is a metric that can go up and down.
+	Gauge struct {
+		bits atomic.Uint64 // float64 bits
+	}
+
+	// Histogram samples observations and counts them in buckets.
+	Histogram struct {
+		upperBounds []float64
+		buckets     []atomic.Uint64 // non-cumulative
+		count       atomic.Uint64
+		sum         atomic.Uint64 // float64 bits
+	}
+
+	// CounterVec is a set of Counters with the same name and label names,
+	// but different label values.
+	CounterVec struct{ vec[Counter] }
+
+	// GaugeVec is a set of Gauges with the same name and label names,
+	// but different label values.
+	GaugeVec struct{ vec[Gauge] }
+
+	// HistogramVec is a set of Histograms with the same name, label names
+	// and buckets, but different label values.
+	HistogramVec struct {
+		vec[Histogram]
+		upperBounds []float64
+	}
+
+	// vec is a generic storage of metrics, keyed by label values.
+	vec[T any] struct {
+		name, help string
+		labels     []string
+		mu         sync.RWMutex
+		series     map[string]*series[T]
+		newMetric  func() *T
+	}
+
+	series[T any] struct {
+		labelValues []string
+		metric      *T
+	}
+)
+
+var (
+	// DefBuckets are default buckets for durations (in seconds).
+	DefBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+
+	// DefSizeBuckets are default buckets for sizes (in bytes).
+	DefSizeBuckets = []float64{64, 256, 1 << 10, 4 << 10, 16 << 10, 64 << 10, 256 << 10, 1 << 20, 4 << 20}
+)
+
+// NewCounterVec returns a new CounterVec. Register it using Registry.MustRegister().
+func NewCounterVec(name, help string, labels ...string) *CounterVec {
+	var v CounterVec
+	v.init(name, help, labels, func() *Counter { return new(Counter) })
+	return &v
+}
+
+// NewGaugeVec returns a new GaugeVec. Register it using Registry.MustRegister().
+func NewGaugeVec(name, help string, labels ...string) *GaugeVec {
+	var v GaugeVec
+	v.init(name, help, labels, func() *Gauge { return new(Gauge) })
+	return &v
+}
+
+// NewHistogramVec returns a new HistogramVec with given upper bounds of buckets
+// (DefBuckets if empty). Register it using Registry.MustRegister().
+func NewHistogramVec(
+	name, help string, buckets []float64, labels ...string) *HistogramVec {
+
+	if len(buckets) == 0 {
+		buckets = DefBuckets
+	}
+
+	var upperBounds = append([]float64(nil), buckets...)
+	sort.Float64s(upperBounds)
+
+	var v = HistogramVec{upperBounds: upperBounds}
+	v.init(name, help, labels, func() *Histogram {
+		var h = Histogram{upperBounds: upperBounds}
+		h.buckets = make([]atomic.Uint64, len(upperBounds))
+		return &h
+	})
+
+	return &v
+}
+
+// With returns a Counter for given label values (in the same order
+// as label names). Panics if the number of values is wrong.
+func (v *CounterVec) With(labelValues ...string) *Counter {
+	return v.with(labelValues)
+}
+
+// With returns a Gauge for given label values (in the same order
+// as label names). Panics if the number of values is wrong.
+func (v *GaugeVec) With(labelValues ...string) *Gauge {
+	return v.with(labelValues)
+}
+
+// With returns a Histogram for given label values (in the same order
+// as label names). Panics if the number of values is wrong.
+func (v *HistogramVec) With(labelValues ...string) *Histogram {
+	return v.with(labelValues)
+}
+
+// Inc increments the Counter by 1.
+func (c *Counter) Inc() { c.Add(1) }
+
+// Add adds given value to the Counter. Negative values are ignored.
+func (c *Counter) Add(v float64) {
+	if v > 0 {
+		addFloat(&c.bits, v)
+	}
+}
+
+// Value returns the current value of the Counter.
+func (c *Counter) Value() float64 {
+	return math.Float64frombits(c.bits.Load())
+}
+
+// Inc increments the Gauge by 1.
+func (g *Gauge) Inc() { addFloat(&g.bits, 1) }
+
+// Dec decrements the Gauge by 1.
+func (g *Gauge) Dec() { addFloat(&g.bits, -1) }
+
+// Add adds given value to the Gauge.
+func (g *Gauge) Add(v float64) { addFloat(&g.bits, v) }
+
+// Set sets the Gauge to given value.
+func (g *Gauge) Set(v float64) { g.bits.Store(math.Float64bits(v)) }
+
+// Value returns the current value of the Gauge.
+func (g *Gauge) Value() float64 {
+	return math.Float64frombits(g.bits.Load())
+}
+
+// Observe adds a single observation to the Histogram.
+func (h *Histogram) Observe(v float64) {
+	var i = sort.SearchFloat64s(h.upperBounds, v)
+	if i < len(h.buckets) {
+		h.buckets[i].Add(1)
+	}
+	addFloat(&h.sum, v)
+	h.count.Add(1)
+}
+
+// Count returns the number of observations of the Histogram.
+func (h *Histogram) Count() uint64 {
+	return h.count.Load()
+}
+
+////////////////////////////////////////////////////////////////////////////////
+///// PRIVATE METHODS //////////////////////////////////////////////////////////
+////////////////////////////////////////////////////////////////////////////////
+
+func (v *vec[T]) init(name, help string, labels []string, newMetric func() *T) {
+	if !isValidName(name) {
+		panic("Middleware.Metrics: Invalid metric name: " + name)
+	}
+	for _, label := range labels {
+		if !isValidName(label) || label == "le" {
+			panic("Middleware.Metrics: Invalid label name: " + label)
+		}
+	}
+	v.name, v.help, v.labels = name, help, labels
+	v.series = make(map[string]*series[T])
+	v.newMetric = newMetric
+}
+
+func (v *vec[T]) with(labelValues []string) *T {
+
+	if len(labelValues) != len(v.labels) {
+		panic("Middleware.Metrics: Wrong number of label values for " + v.name)
+	}
+
+	var key = strings.Join(labelValues, "\xff")
+
+	v.mu.RLock()
+	var s = v.series[key]
+	v.mu.RUnlock()
+
+	if s != nil {
+		return s.metric
+	}
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	if s = v.series[key]; s == nil {
+		var values = append([]string(nil), labelValues...)
+		s = &series[T]{values, v.newMetric()}
+		v.series[key] = s
+	}
+
+	return s.metric
+}
+
+// snapshot returns all series sorted by label values.
+func (v *vec[T]) snapshot() []*series[T] {
+
+	v.mu.RLock()
+	var out = make([]*series[T], 0, len(v.series))
+	for _, s := range v.series {
+		out = append(out, s)
+	}
+	v.mu.RUnlock()
+
+	sort.Slice(out, func(i, j int) bool {
+		var a, b = out[i].labelValues, out[j].labelValues
+		for k := range a {
+			if a[k] != b[k] {
+				return a[k] < b[k]
+			}
+		}
+		return false
+	})
+
+	return out
+}
+
+// addFloat atomically adds 'v' to the float64 stored as bits.
+func addFloat(bits *atomic.Uint64, v float64) {
+	for {
+		var old = bits.Load()
+		var updated = math.Float64bits(math.Float64frombits(old) + v)
+		if bits.CompareAndSwap(old, updated) {
+			return
+		}
+	}
+}
+
+// isValidName reports whether given string is a valid metric or label name.
+func isValidName(name string) bool {
+	if name == "" {
+		return false
+	}
+	for i := 0; i < len(name); i++ {
+		var c = name[i]
+		var ok = c == '_' || c == ':' ||
+			(c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') ||
+			(c >= '0' && c <= '9' && i > 0)
+		if !ok {
+			return false
+		}
+	}
+	return true
+}
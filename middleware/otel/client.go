@@ -0,0 +1,101 @@
+package ekaweb_otel
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/inaneverb/ekaweb/v2"
+	"github.com/inaneverb/ekaweb/v2/private"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/semconv/v1.17.0"
+	"go.opentelemetry.io/otel/semconv/v1.17.0/httpconv"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type (
+	// _ClientInterceptor records a client span for each outgoing HTTP request.
+	_ClientInterceptor struct {
+		next       ekaweb.Client
+		tracer     trace.Tracer
+		propagator propagation.TextMapPropagator
+	}
+)
+
+// AttributeURLTemplate is an attribute key of the URL template
+// of the outgoing HTTP request (see ekaweb.ClientWithURLTemplate()).
+const AttributeURLTemplate = attribute.Key("url.template")
+
+// NewClientInterceptor returns a client interceptor (see ekaweb.WrapClient()),
+// that starts a client span for each ekaweb.Client.Do() call
+// and injects its context (and baggage) into HTTP request headers.
+//
+// The span is named "<method> <template>", where template is a URL template
+// from ekaweb.ClientWithURLTemplate() or the requested path.
+// It records HTTP method, URL template, HTTP status code and the error.
+//
+// Only WithTracerProvider() and WithPropagator() options are taken into account.
+func NewClientInterceptor(opts ...Option) ekaweb.ClientInterceptor {
+	var m middleware
+
+	for i, n := 0, len(opts); i < n; i++ {
+		if opts[i] != nil {
+			opts[i](&m)
+		}
+	}
+
+	if m.tracerProvider == nil {
+		m.tracerProvider = otel.GetTracerProvider()
+	}
+	if m.propagator == nil {
+		m.propagator = propagation.NewCompositeTextMapPropagator(
+			propagation.TraceContext{}, propagation.Baggage{})
+	}
+
+	var tracer = m.tracerProvider.Tracer(ScopeName)
+
+	return func(next ekaweb.Client) ekaweb.Client {
+		return &_ClientInterceptor{next, tracer, m.propagator}
+	}
+}
+
+func (c *_ClientInterceptor) Do(
+	ctx context.Context, method, path string, headers http.Header,
+	req ekaweb.ClientRequest, resp ekaweb.ClientResponse) error {
+
+	var template = ekaweb.ClientURLTemplate(ctx, path)
+
+	var ctxSpan, span = c.tracer.Start(ctx, method+" "+template,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(semconv.HTTPMethod(method), AttributeURLTemplate.String(template)))
+
+	defer span.End()
+
+	if headers == nil {
+		headers = make(http.Header, 2)
+	} else {
+		headers = headers.Clone()
+	}
+
+	c.propagator.Inject(ctxSpan, propagation.HeaderCarrier(headers))
+
+	var proxyResp = ekaweb_private.NewClientResponseRecorder(resp, nil)
+	var err = c.next.Do(ctxSpan, method, path, headers, req, proxyResp.Wrap())
+
+	var statusCode = proxyResp.StatusCode()
+	if statusCode != 0 {
+		span.SetAttributes(semconv.HTTPStatusCode(statusCode))
+	}
+
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	} else if code, desc := httpconv.ClientStatus(statusCode); code == codes.Error {
+		span.SetStatus(code, desc)
+	}
+
+	return err
+}
@@ -3,16 +3,26 @@ module github.com/inaneverb/ekaweb/middleware/otel/v2
 go 1.21.0
 
 require (
-	github.com/inaneverb/ekaweb/v2 v2.0.5
+	github.com/inaneverb/ekaweb/v2 v2.1.1
+	github.com/stretchr/testify v1.8.4
 	go.opentelemetry.io/otel v1.17.0
+	go.opentelemetry.io/otel/metric v1.17.0
+	go.opentelemetry.io/otel/sdk v1.17.0
+	go.opentelemetry.io/otel/sdk/metric v0.40.0
 	go.opentelemetry.io/otel/trace v1.17.0
 )
 
 require (
 	github.com/cespare/xxhash/v2 v2.2.0 // indirect
+	github.com/davecgh/go-spew v1.1.1 // indirect
+	github.com/go-logr/logr v1.2.4 // indirect
+	github.com/go-logr/stdr v1.2.2 // indirect
 	github.com/inaneverb/ekacore/ekaarr/v4 v4.0.0 // indirect
 	github.com/inaneverb/ekacore/ekaext/v4 v4.0.0 // indirect
 	github.com/inaneverb/ekacore/ekaunsafe/v4 v4.0.0 // indirect
+	github.com/pmezard/go-difflib v1.0.0 // indirect
+	golang.org/x/sys v0.11.0 // indirect
+	gopkg.in/yaml.v3 v3.0.1 // indirect
 )
 
 retract (
@@ -1,5 +1,12 @@
 package ekaweb_otel
 
+import (
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
 // Option is a callback that allows to modify Middleware under its construction.
 type Option func(m *middleware)
 
@@ -51,3 +58,59 @@ func WithInvalidAttributeCallback(cb func(s string)) Option {
 		m.cbAttributeInvalid = cb
 	}
 }
+
+// WithTracerProvider makes middleware start the server span itself,
+// using the tracer from given trace.TracerProvider
+// (otel.GetTracerProvider() if nil), instead of decorating the one
+// from http.Request's context.Context. The parent span context and baggage
+// are extracted from HTTP request headers (see WithPropagator()).
+//
+// The span is named "<method> <route>" using ekaweb.RoutePath()
+// after the request is processed, so the middleware may be registered
+// before the routing. The span status is set from ekaweb.ErrorGet()
+// or from 5xx HTTP status code.
+func WithTracerProvider(tp trace.TracerProvider) Option {
+	return func(m *middleware) {
+		m.ownSpan, m.tracerProvider = true, tp
+	}
+}
+
+// WithPropagator sets a propagation.TextMapPropagator, that is used
+// to extract the parent span context and baggage from HTTP request headers.
+// Makes sense only with WithTracerProvider().
+// Default: W3C Trace Context and W3C Baggage.
+func WithPropagator(p propagation.TextMapPropagator) Option {
+	return func(m *middleware) {
+		if p != nil {
+			m.propagator = p
+		}
+	}
+}
+
+// WithMeterProvider makes middleware emit HTTP server metrics
+// (semantic conventions v1.17.0), using the meter from given
+// metric.MeterProvider (otel.GetMeterProvider() if nil):
+//   - http.server.duration (histogram, milliseconds);
+//   - http.server.active_requests (up-down counter);
+//   - http.server.request.size (histogram, bytes);
+//   - http.server.response.size (histogram, bytes).
+func WithMeterProvider(mp metric.MeterProvider) Option {
+	return func(m *middleware) {
+		if mp == nil {
+			mp = otel.GetMeterProvider()
+		}
+		m.meterProvider = mp
+	}
+}
+
+// WithErrorStatus sets a callback, that returns HTTP status code, which is
+// recorded to the span and metrics, when HTTP handler stores an error
+// w/o writing the response (so the error handler responds later).
+// Default: 500. Makes sense only with WithTracerProvider() or WithMeterProvider().
+func WithErrorStatus(cb func(err error) int) Option {
+	return func(m *middleware) {
+		if cb != nil {
+			m.errorStatusGet = cb
+		}
+	}
+}
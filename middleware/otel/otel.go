@@ -7,8 +7,11 @@ import (
 
 	"github.com/inaneverb/ekaweb/v2"
 
+	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/propagation"
 	"go.opentelemetry.io/otel/semconv/v1.17.0"
 	"go.opentelemetry.io/otel/trace"
 )
@@ -25,9 +28,27 @@ type middleware struct {
 	addResponseBody    bool
 	recheckMethodPath  bool
 	cbAttributeInvalid func(s string)
+
+	ownSpan        bool
+	tracerProvider trace.TracerProvider
+	meterProvider  metric.MeterProvider
+	propagator     propagation.TextMapPropagator
+	errorStatusGet func(err error) int
+
+	tracer      trace.Tracer
+	instruments *_Instruments
 }
 
+// ScopeName is the name of OpenTelemetry instrumentation scope,
+// the tracer and the meter are created with.
+const ScopeName = "github.com/inaneverb/ekaweb/middleware/otel/v2"
+
 // New creates a new OpenTelemetry middleware.
+//
+// By default, it only decorates the span from http.Request's context.Context,
+// (started by some outer instrumentation, like otelhttp) and does nothing
+// if there's no such span. Use WithTracerProvider() to make it start
+// the server span itself and WithMeterProvider() to emit HTTP server metrics.
 func New(opts ...Option) ekaweb.Middleware {
 	var m middleware
 
@@ -37,6 +58,21 @@ func New(opts ...Option) ekaweb.Middleware {
 		}
 	}
 
+	if m.ownSpan {
+		if m.tracerProvider == nil {
+			m.tracerProvider = otel.GetTracerProvider()
+		}
+		if m.propagator == nil {
+			m.propagator = propagation.NewCompositeTextMapPropagator(
+				propagation.TraceContext{}, propagation.Baggage{})
+		}
+		m.tracer = m.tracerProvider.Tracer(ScopeName)
+	}
+
+	if m.meterProvider != nil {
+		m.instruments = newInstruments(m.meterProvider.Meter(ScopeName))
+	}
+
 	return &m
 }
 
@@ -47,7 +83,7 @@ func (m *middleware) Callback(next ekaweb.Handler) ekaweb.Handler {
 		AttributeKeyBody    = "Body"
 	)
 
-	return ekaweb.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+	var h = ekaweb.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 
 		var span = trace.SpanFromContext(r.Context())
 		if !span.IsRecording() {
@@ -170,6 +206,14 @@ func (m *middleware) Callback(next ekaweb.Handler) ekaweb.Handler {
 			span.SetStatus(codes.Error, err.Error())
 		}
 	})
+
+	if !m.ownSpan && m.instruments == nil {
+		return h
+	}
+
+	return ekaweb.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		m.serveHTTP(h, w, r)
+	})
 }
 
 func (m *middleware) CheckErrorBefore() bool { return false }
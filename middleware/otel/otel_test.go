@@ -0,0 +1,201 @@
+package ekaweb_otel_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/baggage"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/inaneverb/ekaweb/middleware/otel/v2"
+	"github.com/inaneverb/ekaweb/middleware/otel/v2/oteltest"
+	"github.com/inaneverb/ekaweb/v2"
+	"github.com/inaneverb/ekaweb/v2/private"
+)
+
+func newRecorder(t *testing.T) *ekaweb_oteltest.Recorder {
+	var rec = ekaweb_oteltest.NewRecorder()
+	t.Cleanup(func() { _ = rec.Shutdown(context.Background()) })
+	return rec
+}
+
+func createdHandler(w http.ResponseWriter, _ *http.Request) {
+	w.WriteHeader(http.StatusCreated)
+	_, _ = w.Write([]byte("created"))
+}
+
+// attr returns the value of the attribute with given 'key' from 'attrs'.
+func attr(attrs []attribute.KeyValue, key attribute.Key) attribute.Value {
+	for _, kv := range attrs {
+		if kv.Key == key {
+			return kv.Value
+		}
+	}
+	return attribute.Value{}
+}
+
+func singleSpan(t *testing.T, rec *ekaweb_oteltest.Recorder) tracetest.SpanStub {
+	var spans = rec.Spans()
+	require.Len(t, spans, 1)
+	return spans[0]
+}
+
+func TestServerSpan(t *testing.T) {
+
+	var rec = newRecorder(t)
+	var m = ekaweb_otel.New(ekaweb_otel.WithTracerProvider(rec.TracerProvider()))
+
+	ekaweb_private.ServeMiddlewares(httptest.NewRecorder(),
+		httptest.NewRequest(http.MethodPost, "/users?x=1", nil),
+		http.HandlerFunc(createdHandler), m)
+
+	var span = singleSpan(t, rec)
+	require.Equal(t, "POST /users", span.Name)
+	require.Equal(t, trace.SpanKindServer, span.SpanKind)
+	require.False(t, span.Parent.IsValid())
+	require.Equal(t, codes.Unset, span.Status.Code)
+	require.Equal(t, int64(http.StatusCreated), attr(span.Attributes, "http.status_code").AsInt64())
+	require.Equal(t, "/users", attr(span.Attributes, "http.route").AsString())
+	require.Equal(t, ekaweb_otel.ScopeName, span.InstrumentationLibrary.Name)
+}
+
+func TestServerSpan_Error(t *testing.T) {
+
+	var rec = newRecorder(t)
+	var m = ekaweb_otel.New(
+		ekaweb_otel.WithTracerProvider(rec.TracerProvider()),
+		ekaweb_otel.WithErrorStatus(func(error) int { return http.StatusBadGateway }),
+	)
+
+	var err = ekaweb_private.ServeMiddlewares(httptest.NewRecorder(),
+		httptest.NewRequest(http.MethodGet, "/", nil),
+		http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+			ekaweb.ErrorApply(r, errors.New("upstream failed"))
+		}), m)
+	require.EqualError(t, err, "upstream failed")
+
+	var span = singleSpan(t, rec)
+	require.Equal(t, codes.Error, span.Status.Code)
+	require.Equal(t, "upstream failed", span.Status.Description)
+	require.Equal(t, int64(http.StatusBadGateway), attr(span.Attributes, "http.status_code").AsInt64())
+	require.Len(t, span.Events, 1)
+	require.Equal(t, "exception", span.Events[0].Name)
+
+	// 5xx HTTP status code w/o error.
+
+	rec.Reset()
+	ekaweb_private.ServeMiddlewares(httptest.NewRecorder(),
+		httptest.NewRequest(http.MethodGet, "/", nil),
+		http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}), m)
+
+	require.Equal(t, codes.Error, singleSpan(t, rec).Status.Code)
+}
+
+func TestServerSpan_Propagation(t *testing.T) {
+
+	var rec = newRecorder(t)
+	var m = ekaweb_otel.New(ekaweb_otel.WithTracerProvider(rec.TracerProvider()))
+
+	// The parent span is started by the client side.
+
+	var ctx, parent = rec.TracerProvider().Tracer("client").Start(context.Background(), "client")
+	var member, _ = baggage.NewMember("user", "alice")
+	var bag, _ = baggage.New(member)
+	ctx = baggage.ContextWithBaggage(ctx, bag)
+
+	var r = httptest.NewRequest(http.MethodGet, "/", nil)
+	ekaweb_oteltest.Propagator().Inject(ctx, propagation.HeaderCarrier(r.Header))
+	parent.End()
+	rec.Reset()
+
+	var user string
+	ekaweb_private.ServeMiddlewares(httptest.NewRecorder(), r,
+		http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+			user = baggage.FromContext(r.Context()).Member("user").Value()
+		}), m)
+
+	var span = singleSpan(t, rec)
+	require.Equal(t, parent.SpanContext().TraceID(), span.SpanContext.TraceID())
+	require.Equal(t, parent.SpanContext().SpanID(), span.Parent.SpanID())
+	require.True(t, span.Parent.IsRemote())
+	require.Equal(t, "alice", user)
+}
+
+func TestDecorateSpan(t *testing.T) {
+
+	var rec = newRecorder(t)
+	var m = ekaweb_otel.New()
+
+	// Not recording span: nothing to decorate.
+
+	var called bool
+	ekaweb_private.ServeMiddlewares(httptest.NewRecorder(),
+		httptest.NewRequest(http.MethodGet, "/", nil),
+		http.HandlerFunc(func(http.ResponseWriter, *http.Request) { called = true }), m)
+
+	require.True(t, called)
+	require.Empty(t, rec.Spans())
+
+	// The span, started by an outer instrumentation.
+
+	var ctx, span = rec.TracerProvider().Tracer("outer").Start(context.Background(), "outer")
+	var r = httptest.NewRequest(http.MethodGet, "/items", nil).WithContext(ctx)
+
+	ekaweb_private.ServeMiddlewares(httptest.NewRecorder(), r, http.HandlerFunc(createdHandler), m)
+	span.End()
+
+	var stub = singleSpan(t, rec)
+	require.Equal(t, "GET /items", stub.Name)
+	require.Equal(t, "/items", attr(stub.Attributes, "http.route").AsString())
+}
+
+func TestMetrics(t *testing.T) {
+
+	var rec = newRecorder(t)
+	var m = ekaweb_otel.New(ekaweb_otel.WithMeterProvider(rec.MeterProvider()))
+
+	for i := 0; i < 2; i++ {
+		ekaweb_private.ServeMiddlewares(httptest.NewRecorder(),
+			httptest.NewRequest(http.MethodPost, "/users", nil),
+			http.HandlerFunc(createdHandler), m)
+	}
+
+	var ctx = context.Background()
+
+	var duration, ok = rec.Metric(ctx, "http.server.duration")
+	require.True(t, ok)
+	require.Equal(t, "ms", duration.Unit)
+
+	var points = duration.Data.(metricdata.Histogram[float64]).DataPoints
+	require.Len(t, points, 1)
+	require.Equal(t, uint64(2), points[0].Count)
+
+	var status, _ = points[0].Attributes.Value("http.status_code")
+	var route, _ = points[0].Attributes.Value("http.route")
+	require.Equal(t, int64(http.StatusCreated), status.AsInt64())
+	require.Equal(t, "/users", route.AsString())
+
+	responseSize, ok := rec.Metric(ctx, "http.server.response.size")
+	require.True(t, ok)
+	require.Equal(t, int64(2*len("created")),
+		responseSize.Data.(metricdata.Histogram[int64]).DataPoints[0].Sum)
+
+	active, ok := rec.Metric(ctx, "http.server.active_requests")
+	require.True(t, ok)
+	require.Equal(t, int64(0), active.Data.(metricdata.Sum[int64]).DataPoints[0].Value)
+
+	// No spans are started w/o WithTracerProvider().
+
+	require.Empty(t, rec.Spans())
+}
@@ -0,0 +1,114 @@
+// Package ekaweb_oteltest provides an in-memory OpenTelemetry exporter,
+// allowing to check spans and metrics, that are recorded by ekaweb_otel
+// middleware (or by any other instrumentation), in tests.
+package ekaweb_oteltest
+
+import (
+	"context"
+	"errors"
+
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/propagation"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Recorder is an in-memory OpenTelemetry exporter.
+// It provides trace.TracerProvider and metric.MeterProvider,
+// that should be passed to ekaweb_otel.WithTracerProvider()
+// and ekaweb_otel.WithMeterProvider().
+//
+// Spans are exported synchronously, when they are ended,
+// metrics are collected on demand. Both are kept in memory.
+type Recorder struct {
+	spans  *tracetest.InMemoryExporter
+	reader *sdkmetric.ManualReader
+	tp     *sdktrace.TracerProvider
+	mp     *sdkmetric.MeterProvider
+}
+
+// NewRecorder returns a new empty Recorder.
+// Use Recorder.Shutdown() when it's not needed anymore.
+func NewRecorder() *Recorder {
+
+	var spans = tracetest.NewInMemoryExporter()
+	var reader = sdkmetric.NewManualReader()
+
+	return &Recorder{
+		spans:  spans,
+		reader: reader,
+		tp:     sdktrace.NewTracerProvider(sdktrace.WithSyncer(spans)),
+		mp:     sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader)),
+	}
+}
+
+// Propagator returns W3C Trace Context and W3C Baggage propagator,
+// that may be used to inject the parent span context into HTTP request headers.
+func Propagator() propagation.TextMapPropagator {
+	return propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{}, propagation.Baggage{})
+}
+
+// TracerProvider returns trace.TracerProvider,
+// spans of which are recorded by the Recorder.
+func (r *Recorder) TracerProvider() trace.TracerProvider {
+	return r.tp
+}
+
+// MeterProvider returns metric.MeterProvider,
+// metrics of which are recorded by the Recorder.
+func (r *Recorder) MeterProvider() metric.MeterProvider {
+	return r.mp
+}
+
+// Spans returns all ended spans in the order they are ended.
+func (r *Recorder) Spans() tracetest.SpanStubs {
+	return r.spans.GetSpans()
+}
+
+// Metrics collects and returns all recorded metrics.
+func (r *Recorder) Metrics(ctx context.Context) ([]metricdata.Metrics, error) {
+
+	var rm metricdata.ResourceMetrics
+	if err := r.reader.Collect(ctx, &rm); err != nil {
+		return nil, err
+	}
+
+	var out []metricdata.Metrics
+	for _, sm := range rm.ScopeMetrics {
+		out = append(out, sm.Metrics...)
+	}
+
+	return out, nil
+}
+
+// Metric collects all recorded metrics and returns the one with given name.
+// Returns false, if there's no such metric.
+func (r *Recorder) Metric(ctx context.Context, name string) (metricdata.Metrics, bool) {
+
+	var metrics, err = r.Metrics(ctx)
+	if err != nil {
+		return metricdata.Metrics{}, false
+	}
+
+	for _, m := range metrics {
+		if m.Name == name {
+			return m, true
+		}
+	}
+
+	return metricdata.Metrics{}, false
+}
+
+// Reset drops all ended spans. Metrics are cumulative and they are not reset.
+func (r *Recorder) Reset() {
+	r.spans.Reset()
+}
+
+// Shutdown shuts down the providers of the Recorder.
+func (r *Recorder) Shutdown(ctx context.Context) error {
+	return errors.Join(r.tp.Shutdown(ctx), r.mp.Shutdown(ctx))
+}
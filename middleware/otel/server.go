@@ -0,0 +1,149 @@
+package ekaweb_otel
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/inaneverb/ekaweb/v2"
+	"github.com/inaneverb/ekaweb/v2/private"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/semconv/v1.17.0"
+	"go.opentelemetry.io/otel/semconv/v1.17.0/httpconv"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// _Instruments is a set of OpenTelemetry HTTP server metrics.
+type _Instruments struct {
+	duration       metric.Float64Histogram
+	activeRequests metric.Int64UpDownCounter
+	requestSize    metric.Int64Histogram
+	responseSize   metric.Int64Histogram
+}
+
+////////////////////////////////////////////////////////////////////////////////
+///// PRIVATE METHODS //////////////////////////////////////////////////////////
+////////////////////////////////////////////////////////////////////////////////
+
+// serveHTTP starts the server span (if required), calls 'next'
+// (the decorating handler), and then finishes the span, recording metrics.
+func (m *middleware) serveHTTP(
+	next ekaweb.Handler, w http.ResponseWriter, r *http.Request) {
+
+	var start = time.Now()
+	var ctx = r.Context()
+	var span trace.Span
+
+	if m.ownSpan {
+		ctx = m.propagator.Extract(ctx, propagation.HeaderCarrier(r.Header))
+		ctx, span = m.tracer.Start(ctx, r.Method,
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(httpconv.ServerRequest("", r)...))
+
+		defer span.End()
+		r = r.WithContext(ctx)
+	}
+
+	var attrs = make([]attribute.KeyValue, 0, 4)
+	attrs = append(attrs, semconv.HTTPMethod(r.Method))
+
+	if r.TLS != nil {
+		attrs = append(attrs, semconv.HTTPSchemeHTTPS)
+	} else {
+		attrs = append(attrs, semconv.HTTPSchemeHTTP)
+	}
+
+	if m.instruments != nil {
+		var opt = metric.WithAttributes(attrs...)
+		m.instruments.activeRequests.Add(ctx, 1, opt)
+		defer m.instruments.activeRequests.Add(ctx, -1, opt)
+	}
+
+	var resp = ekaweb_private.NewStatusRecorder(w)
+
+	// ################################################################## //
+	next.ServeHTTP(resp, r)
+	// ################################################################## //
+
+	var elapsed = time.Since(start)
+
+	var route string
+	if !ekaweb_private.UkvsIsPathNotFoundOrNotAllowed(ctx) {
+		route = ekaweb.RoutePath(r)
+	}
+
+	var err = ekaweb.ErrorGet(r)
+	var statusCode = resp.StatusCode(ctx, m.errorStatusGet)
+
+	if route != "" {
+		attrs = append(attrs, semconv.HTTPRoute(route))
+	}
+	attrs = append(attrs, semconv.HTTPStatusCode(statusCode))
+
+	if m.ownSpan {
+		// The decorating handler may name the span before the routing.
+		// Rename it, using the matched route (if any).
+
+		if route != "" {
+			span.SetName(r.Method + " " + route)
+			span.SetAttributes(semconv.HTTPRoute(route))
+		} else {
+			span.SetName(r.Method)
+		}
+		span.SetAttributes(semconv.HTTPStatusCode(statusCode))
+
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		} else if code, desc := httpconv.ServerStatus(statusCode); code == codes.Error {
+			span.SetStatus(code, desc)
+		}
+	}
+
+	if m.instruments != nil {
+		var opt = metric.WithAttributes(attrs...)
+		var ms = float64(elapsed) / float64(time.Millisecond)
+
+		m.instruments.duration.Record(ctx, ms, opt)
+		m.instruments.requestSize.Record(ctx, max(r.ContentLength, 0), opt)
+		m.instruments.responseSize.Record(ctx, resp.Size(), opt)
+	}
+}
+
+// newInstruments creates HTTP server metrics, using given metric.Meter.
+// Panics if any of them cannot be created.
+func newInstruments(meter metric.Meter) *_Instruments {
+
+	var i _Instruments
+	var err, errs error
+
+	i.duration, err = meter.Float64Histogram("http.server.duration",
+		metric.WithUnit("ms"),
+		metric.WithDescription("Measures the duration of inbound HTTP requests."))
+	errs = errors.Join(errs, err)
+
+	i.activeRequests, err = meter.Int64UpDownCounter("http.server.active_requests",
+		metric.WithUnit("{request}"),
+		metric.WithDescription("Measures the number of concurrent HTTP requests that are currently in-flight."))
+	errs = errors.Join(errs, err)
+
+	i.requestSize, err = meter.Int64Histogram("http.server.request.size",
+		metric.WithUnit("By"),
+		metric.WithDescription("Measures the size of HTTP request messages (compressed)."))
+	errs = errors.Join(errs, err)
+
+	i.responseSize, err = meter.Int64Histogram("http.server.response.size",
+		metric.WithUnit("By"),
+		metric.WithDescription("Measures the size of HTTP response messages (compressed)."))
+	errs = errors.Join(errs, err)
+
+	if errs != nil {
+		panic("Middleware.OTel: Failed to create metrics: " + errs.Error())
+	}
+
+	return &i
+}
@@ -0,0 +1,29 @@
+module github.com/inaneverb/ekaweb/middleware/ratelimit/v2
+
+go 1.21
+
+require (
+	github.com/inaneverb/ekaweb/middleware/jwks/v2 v2.0.0
+	github.com/inaneverb/ekaweb/v2 v2.1.1
+	github.com/lestrrat-go/jwx v1.2.25
+	github.com/stretchr/testify v1.8.2
+)
+
+require (
+	github.com/cespare/xxhash/v2 v2.2.0 // indirect
+	github.com/davecgh/go-spew v1.1.1 // indirect
+	github.com/decred/dcrd/dcrec/secp256k1/v4 v4.0.0-20210816181553-5444fa50b93d // indirect
+	github.com/goccy/go-json v0.10.2 // indirect
+	github.com/inaneverb/ekacore/ekaarr/v4 v4.0.0 // indirect
+	github.com/inaneverb/ekacore/ekaext/v4 v4.0.0 // indirect
+	github.com/inaneverb/ekacore/ekaunsafe/v4 v4.0.0 // indirect
+	github.com/lestrrat-go/backoff/v2 v2.0.8 // indirect
+	github.com/lestrrat-go/blackmagic v1.0.0 // indirect
+	github.com/lestrrat-go/httpcc v1.0.1 // indirect
+	github.com/lestrrat-go/iter v1.0.1 // indirect
+	github.com/lestrrat-go/option v1.0.0 // indirect
+	github.com/pkg/errors v0.9.1 // indirect
+	github.com/pmezard/go-difflib v1.0.0 // indirect
+	golang.org/x/crypto v0.0.0-20220427172511-eb4f295cb31f // indirect
+	gopkg.in/yaml.v3 v3.0.1 // indirect
+)
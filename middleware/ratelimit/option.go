@@ -0,0 +1,21 @@
+package ekaweb_ratelimit
+
+// Option is a callback that allows to modify Middleware under its construction.
+type Option func(m *middleware)
+
+// WithHeaders enables or disables applying RateLimit-* and Retry-After
+// HTTP headers to the HTTP response. Enabled by default.
+func WithHeaders(enable bool) Option {
+	return func(m *middleware) {
+		m.headers = enable
+	}
+}
+
+// WithFailOpen defines what to do if Store returns an error.
+// If 'failOpen' is true (default), the HTTP request is allowed.
+// Otherwise, the Store's error is stored to the http.Request's context.Context.
+func WithFailOpen(failOpen bool) Option {
+	return func(m *middleware) {
+		m.failOpen = failOpen
+	}
+}
@@ -0,0 +1,208 @@
+package ekaweb_ratelimit
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/inaneverb/ekaweb/middleware/jwks/v2"
+	"github.com/inaneverb/ekaweb/v2"
+	"github.com/inaneverb/ekaweb/v2/private"
+)
+
+type (
+	middleware struct {
+		next     ekaweb.Handler
+		store    Store
+		keyFunc  KeyFunc
+		headers  bool
+		failOpen bool
+	}
+
+	// Store is a storage of rate limiting counters. It decides whether
+	// the next request with given key is allowed, counting it.
+	// Use NewTokenBucketStore() or NewSlidingWindowStore() for in-memory
+	// storages, or implement your own for external backends (like Redis).
+	Store interface {
+		Take(ctx context.Context, key string) (Result, error)
+	}
+
+	// Result is a result of Store.Take().
+	Result struct {
+		Allowed    bool
+		Limit      int           // max requests in the period (window)
+		Remaining  int           // requests left in the current period
+		Reset      time.Duration // when the quota is restored
+		RetryAfter time.Duration // when the next request may be allowed
+	}
+
+	// KeyFunc returns a key, the HTTP request is limited by.
+	// An empty key means the HTTP request is not limited at all.
+	KeyFunc = func(r *http.Request) string
+
+	// LimitError is an error, that is stored to the http.Request's
+	// context.Context, when the rate limit is exceeded. Suits 429 HTTP status.
+	LimitError struct {
+		Limit      int
+		RetryAfter time.Duration
+	}
+)
+
+// HTTP headers, that are applied to the HTTP response.
+// https://datatracker.ietf.org/doc/draft-ietf-httpapi-ratelimit-headers/
+const (
+	HeaderRateLimitLimit     = "RateLimit-Limit"
+	HeaderRateLimitRemaining = "RateLimit-Remaining"
+	HeaderRateLimitReset     = "RateLimit-Reset"
+)
+
+// ErrLimitExceeded is a typed LimitError "pattern".
+// Use errors.Is(err, ErrLimitExceeded) to check whether an error is LimitError.
+var ErrLimitExceeded = (*LimitError)(nil)
+
+// New returns a new rate limiting HTTP middleware, that limits HTTP requests
+// by the key, that is returned by 'keyFunc', using given 'store'.
+// Panics if any of 'store' or 'keyFunc' is nil.
+//
+// If the limit is exceeded, LimitError is stored to the http.Request's
+// context.Context (see ErrorApply()) and 'next' handler is not called.
+// RateLimit-* HTTP headers are applied to each response
+// and Retry-After when the limit is exceeded (see WithHeaders()).
+//
+// If 'store' returns an error, the HTTP request is allowed
+// (see WithFailOpen()). Has no error check before.
+func New(store Store, keyFunc KeyFunc, options ...Option) ekaweb.Middleware {
+
+	if store == nil || keyFunc == nil {
+		panic("Middleware.RateLimit: Store and KeyFunc must not be nil")
+	}
+
+	var m = middleware{nil, store, keyFunc, true, true}
+
+	for _, option := range options {
+		if option != nil {
+			option(&m)
+		}
+	}
+
+	return &m
+}
+
+// KeyByIP is a KeyFunc, that limits HTTP requests by client's IP
+// (w/o port, so all connections of the client share the same limit).
+// Use it with the ekaweb_realip middleware if you're behind a proxy.
+func KeyByIP(r *http.Request) string {
+	if host, _, err := net.SplitHostPort(r.RemoteAddr); err == nil {
+		return host
+	}
+	return r.RemoteAddr // e.g. IP w/o port, saved by ekaweb_realip
+}
+
+// KeyByJWTSubject is a KeyFunc, that limits HTTP requests by the subject
+// ("sub" claim) of JWT, that is verified by the ekaweb_jwks middleware.
+// HTTP requests w/o token or subject are not limited, so register
+// the rate limiter after ekaweb_jwks or combine it with another one.
+func KeyByJWTSubject(r *http.Request) string {
+	if token := ekaweb_jwks.GetTokenByContext(r.Context()); token != nil {
+		return token.Subject()
+	}
+	return ""
+}
+
+// KeyByRoute is a KeyFunc, that limits HTTP requests by HTTP method and path.
+func KeyByRoute(r *http.Request) string {
+	return r.Method + " " + ekaweb.RoutePath(r)
+}
+
+// KeyCombine returns a KeyFunc, that joins keys of all given 'keyFuncs'.
+// If any of them returns an empty key, the HTTP request is not limited.
+func KeyCombine(keyFuncs ...KeyFunc) KeyFunc {
+	return func(r *http.Request) string {
+		var key string
+		for i, keyFunc := range keyFuncs {
+			var part = keyFunc(r)
+			if part == "" {
+				return ""
+			}
+			if i > 0 {
+				key += "|"
+			}
+			key += part
+		}
+		return key
+	}
+}
+
+func (m middleware) Callback(next ekaweb.Handler) ekaweb.Handler {
+	m.next = next
+	return ekaweb.HandlerFunc(m.serveHTTP)
+}
+
+func (m middleware) CheckErrorBefore() bool { return false }
+
+func (e *LimitError) Error() string {
+	const D = "Middleware.RateLimit: Too many requests"
+	if e == nil || e.RetryAfter <= 0 {
+		return D
+	}
+	return D + " (retry after " + e.RetryAfter.String() + ")"
+}
+
+func (e *LimitError) Is(other error) bool {
+	var _, ok = other.(*LimitError)
+	return ok
+}
+
+////////////////////////////////////////////////////////////////////////////////
+///// PRIVATE METHODS //////////////////////////////////////////////////////////
+////////////////////////////////////////////////////////////////////////////////
+
+func (m middleware) serveHTTP(w http.ResponseWriter, r *http.Request) {
+
+	var key = m.keyFunc(r)
+	if key == "" {
+		m.next.ServeHTTP(w, r)
+		return
+	}
+
+	var res, err = m.store.Take(r.Context(), key)
+	if err != nil {
+		if m.failOpen {
+			m.next.ServeHTTP(w, r)
+		} else {
+			ekaweb_private.UkvsInsertUserError(r.Context(), err)
+		}
+		return
+	}
+
+	if m.headers {
+		var h = w.Header()
+		h.Set(HeaderRateLimitLimit, strconv.Itoa(res.Limit))
+		h.Set(HeaderRateLimitRemaining, strconv.Itoa(res.Remaining))
+		h.Set(HeaderRateLimitReset, formatSeconds(res.Reset))
+
+		if !res.Allowed {
+			h.Set(ekaweb.HeaderRetryAfter, formatSeconds(res.RetryAfter))
+		}
+	}
+
+	if !res.Allowed {
+		var err = &LimitError{res.Limit, res.RetryAfter}
+		ekaweb_private.UkvsInsertUserError(r.Context(), err)
+		return
+	}
+
+	m.next.ServeHTTP(w, r)
+}
+
+// formatSeconds returns given time.Duration as the number of seconds,
+// rounded up (HTTP headers require integer seconds).
+func formatSeconds(d time.Duration) string {
+	var seconds = int64((d + time.Second - 1) / time.Second)
+	if seconds < 0 {
+		seconds = 0
+	}
+	return strconv.FormatInt(seconds, 10)
+}
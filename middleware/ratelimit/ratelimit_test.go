@@ -0,0 +1,117 @@
+package ekaweb_ratelimit_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/lestrrat-go/jwx/jwt"
+	"github.com/stretchr/testify/require"
+
+	"github.com/inaneverb/ekaweb/middleware/jwks/v2"
+	"github.com/inaneverb/ekaweb/middleware/ratelimit/v2"
+	"github.com/inaneverb/ekaweb/v2"
+	"github.com/inaneverb/ekaweb/v2/private"
+)
+
+var okHandler = http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+	w.WriteHeader(http.StatusOK)
+})
+
+// request performs HTTP request from 'remoteAddr', limited by 'm'.
+// Returns the response and the stored error.
+func request(m ekaweb.Middleware, remoteAddr string) (*httptest.ResponseRecorder, error) {
+
+	var w = httptest.NewRecorder()
+	return w, ekaweb_private.ServeMiddlewares(w, newRequest(remoteAddr), okHandler, m)
+}
+
+func newRequest(remoteAddr string) *http.Request {
+	var r = httptest.NewRequest(http.MethodGet, "/", nil)
+	r.RemoteAddr = remoteAddr
+	return r
+}
+
+func TestRateLimit(t *testing.T) {
+
+	var store = ekaweb_ratelimit.NewTokenBucketStore(1, time.Minute, 0)
+	var m = ekaweb_ratelimit.New(store, ekaweb_ratelimit.KeyByIP)
+
+	var w, err = request(m, "192.0.2.1:1000")
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Equal(t, "1", w.Header().Get(ekaweb_ratelimit.HeaderRateLimitLimit))
+	require.Equal(t, "0", w.Header().Get(ekaweb_ratelimit.HeaderRateLimitRemaining))
+	require.Equal(t, "60", w.Header().Get(ekaweb_ratelimit.HeaderRateLimitReset))
+	require.Empty(t, w.Header().Get(ekaweb.HeaderRetryAfter))
+
+	// Another connection of the same client shares the limit.
+
+	w, err = request(m, "192.0.2.1:2000")
+	require.ErrorIs(t, err, ekaweb_ratelimit.ErrLimitExceeded)
+	require.Equal(t, "60", w.Header().Get(ekaweb.HeaderRetryAfter))
+
+	var limitErr *ekaweb_ratelimit.LimitError
+	require.True(t, errors.As(err, &limitErr))
+	require.Equal(t, 1, limitErr.Limit)
+
+	// Another client doesn't.
+
+	_, err = request(m, "192.0.2.2:1000")
+	require.NoError(t, err)
+}
+
+func TestKeyByIP(t *testing.T) {
+	require.Equal(t, "192.0.2.1", ekaweb_ratelimit.KeyByIP(newRequest("192.0.2.1:1000")))
+	require.Equal(t, "2001:db8::1", ekaweb_ratelimit.KeyByIP(newRequest("[2001:db8::1]:1000")))
+	require.Equal(t, "2001:db8::1", ekaweb_ratelimit.KeyByIP(newRequest("2001:db8::1")))
+	require.Equal(t, "192.0.2.1", ekaweb_ratelimit.KeyByIP(newRequest("192.0.2.1")))
+}
+
+func TestKeyByJWTSubject(t *testing.T) {
+
+	var store = ekaweb_ratelimit.NewTokenBucketStore(1, time.Minute, 0)
+	var m = ekaweb_ratelimit.New(store, ekaweb_ratelimit.KeyByJWTSubject)
+
+	var withSubject = func(sub string) ekaweb.Middleware {
+		return ekaweb.MiddlewareFunc(func(next ekaweb.Handler) ekaweb.Handler {
+			return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				var token = jwt.New()
+				require.NoError(t, token.Set(jwt.SubjectKey, sub))
+				ekaweb_jwks.SetTokenByContext(r.Context(), token)
+				m.Callback(next).ServeHTTP(w, r)
+			})
+		})
+	}
+
+	// Requests w/o token are not limited.
+
+	for i := 0; i < 2; i++ {
+		var _, err = request(m, "192.0.2.1:1000")
+		require.NoError(t, err)
+	}
+
+	var _, err = request(withSubject("alice"), "192.0.2.1:1000")
+	require.NoError(t, err)
+
+	_, err = request(withSubject("alice"), "192.0.2.2:1000")
+	require.ErrorIs(t, err, ekaweb_ratelimit.ErrLimitExceeded)
+
+	_, err = request(withSubject("bob"), "192.0.2.1:1000")
+	require.NoError(t, err)
+}
+
+func TestKeyCombine(t *testing.T) {
+
+	var keyFunc = ekaweb_ratelimit.KeyCombine(
+		ekaweb_ratelimit.KeyByIP, func(r *http.Request) string { return r.Method })
+
+	require.Equal(t, "192.0.2.1|GET", keyFunc(newRequest("192.0.2.1:1000")))
+
+	keyFunc = ekaweb_ratelimit.KeyCombine(
+		ekaweb_ratelimit.KeyByIP, func(*http.Request) string { return "" })
+
+	require.Empty(t, keyFunc(newRequest("192.0.2.1:1000")))
+}
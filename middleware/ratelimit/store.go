@@ -0,0 +1,221 @@
+package ekaweb_ratelimit
+
+import (
+	"context"
+	"hash/maphash"
+	"math"
+	"sync"
+	"time"
+)
+
+type (
+	// _Shards is a sharded in-memory storage of rate limiting counters.
+	// Counters that are idle longer than 'ttl' are swept lazily.
+	_Shards[T any] struct {
+		seed   maphash.Seed
+		ttl    time.Duration
+		shards [shardsNum]_Shard[T]
+	}
+
+	_Shard[T any] struct {
+		mu        sync.Mutex
+		entries   map[string]*_ShardEntry[T]
+		nextSweep time.Time
+	}
+
+	_ShardEntry[T any] struct {
+		value    T
+		lastSeen time.Time
+	}
+
+	// _TokenBucketStore is a Store, that implements token bucket algorithm.
+	_TokenBucketStore struct {
+		shards _Shards[_TokenBucket]
+		rate   float64 // tokens per second
+		burst  int
+		now    func() time.Time
+	}
+
+	_TokenBucket struct {
+		tokens float64
+		last   time.Time
+	}
+
+	// _SlidingWindowStore is a Store, that implements sliding window
+	// counter algorithm (the weighted sum of the previous
+	// and the current fixed windows).
+	_SlidingWindowStore struct {
+		shards _Shards[_SlidingWindow]
+		limit  int
+		window time.Duration
+		now    func() time.Time
+	}
+
+	_SlidingWindow struct {
+		start      time.Time
+		curr, prev int
+	}
+)
+
+// shardsNum is a number of shards of the in-memory Store.
+// More shards mean less lock contention.
+const shardsNum = 64
+
+// NewTokenBucketStore returns a new in-memory Store, that allows 'limit'
+// requests per 'period' for each key, with bursts up to 'burst' requests.
+// If 'burst' <= 0, it's the same as 'limit'.
+// Panics if 'limit' or 'period' is not positive.
+func NewTokenBucketStore(limit int, period time.Duration, burst int) Store {
+
+	if limit <= 0 || period <= 0 {
+		panic("Middleware.RateLimit: Limit and period must be positive")
+	}
+	if burst <= 0 {
+		burst = limit
+	}
+
+	var s = _TokenBucketStore{
+		rate:  float64(limit) / period.Seconds(),
+		burst: burst,
+		now:   time.Now,
+	}
+
+	var refill = time.Duration(float64(burst) / s.rate * float64(time.Second))
+	s.shards.init(refill)
+
+	return &s
+}
+
+// NewSlidingWindowStore returns a new in-memory Store, that allows 'limit'
+// requests per sliding 'window' for each key.
+// Panics if 'limit' or 'window' is not positive.
+func NewSlidingWindowStore(limit int, window time.Duration) Store {
+
+	if limit <= 0 || window <= 0 {
+		panic("Middleware.RateLimit: Limit and window must be positive")
+	}
+
+	var s = _SlidingWindowStore{limit: limit, window: window, now: time.Now}
+	s.shards.init(2 * window)
+
+	return &s
+}
+
+func (s *_TokenBucketStore) Take(_ context.Context, key string) (Result, error) {
+
+	var now = s.now()
+	var res = Result{Limit: s.burst}
+	var burst = float64(s.burst)
+
+	s.shards.with(key, now, func(b *_TokenBucket, isNew bool) {
+
+		if isNew {
+			b.tokens, b.last = burst, now
+		} else if elapsed := now.Sub(b.last); elapsed > 0 {
+			b.tokens = min(burst, b.tokens+elapsed.Seconds()*s.rate)
+			b.last = now
+		}
+
+		if b.tokens >= 1 {
+			b.tokens--
+			res.Allowed = true
+		} else {
+			res.RetryAfter = s.duration(1 - b.tokens)
+		}
+
+		res.Remaining = int(b.tokens)
+		res.Reset = s.duration(burst - b.tokens)
+	})
+
+	return res, nil
+}
+
+func (s *_SlidingWindowStore) Take(_ context.Context, key string) (Result, error) {
+
+	var now = s.now()
+	var start = now.Truncate(s.window)
+	var res = Result{Limit: s.limit}
+
+	s.shards.with(key, now, func(w *_SlidingWindow, isNew bool) {
+
+		if isNew {
+			w.start = start
+		} else if !w.start.Equal(start) {
+			if start.Sub(w.start) == s.window {
+				w.prev = w.curr
+			} else {
+				w.prev = 0
+			}
+			w.curr, w.start = 0, start
+		}
+
+		var elapsed = now.Sub(start)
+		var weight = float64(s.window-elapsed) / float64(s.window)
+		var count = float64(w.prev)*weight + float64(w.curr)
+
+		res.Reset = s.window - elapsed
+
+		if count+1 <= float64(s.limit) {
+			w.curr++
+			count++
+			res.Allowed = true
+		} else if w.prev == 0 || w.curr+1 > s.limit {
+			res.RetryAfter = s.window - elapsed
+		} else {
+			// Find when the weight of previous window drops enough:
+			// prev * (window - elapsed - t) / window + curr + 1 <= limit.
+			var free = float64(s.limit-w.curr-1) * float64(s.window) / float64(w.prev)
+			res.RetryAfter = s.window - elapsed - time.Duration(free)
+		}
+
+		res.Remaining = max(0, s.limit-int(math.Ceil(count)))
+	})
+
+	return res, nil
+}
+
+////////////////////////////////////////////////////////////////////////////////
+///// PRIVATE METHODS //////////////////////////////////////////////////////////
+////////////////////////////////////////////////////////////////////////////////
+
+func (s *_Shards[T]) init(ttl time.Duration) {
+	s.seed = maphash.MakeSeed()
+	s.ttl = ttl
+	for i := range s.shards {
+		s.shards[i].entries = make(map[string]*_ShardEntry[T])
+	}
+}
+
+// with calls 'cb' with the counter of given 'key' under the shard's lock.
+// Creates a new counter if there's no such (reporting it by 'isNew').
+func (s *_Shards[T]) with(key string, now time.Time, cb func(v *T, isNew bool)) {
+
+	var shard = &s.shards[maphash.String(s.seed, key)%shardsNum]
+
+	shard.mu.Lock()
+	defer shard.mu.Unlock()
+
+	if now.After(shard.nextSweep) {
+		for k, entry := range shard.entries {
+			if now.Sub(entry.lastSeen) > s.ttl {
+				delete(shard.entries, k)
+			}
+		}
+		shard.nextSweep = now.Add(s.ttl)
+	}
+
+	var entry, found = shard.entries[key]
+	if !found {
+		entry = new(_ShardEntry[T])
+		shard.entries[key] = entry
+	}
+
+	entry.lastSeen = now
+	cb(&entry.value, !found)
+}
+
+// duration returns the time.Duration, that is required to refill
+// given number of tokens.
+func (s *_TokenBucketStore) duration(tokens float64) time.Duration {
+	return time.Duration(tokens / s.rate * float64(time.Second))
+}
@@ -0,0 +1,120 @@
+package ekaweb_ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// clock is a manually advanced time source for the stores.
+type clock struct{ now time.Time }
+
+func newClock() *clock {
+	return &clock{time.Unix(1_700_000_000, 0).Truncate(time.Hour)}
+}
+
+func (c *clock) Now() time.Time          { return c.now }
+func (c *clock) Advance(d time.Duration) { c.now = c.now.Add(d) }
+
+func take(t *testing.T, s Store) Result {
+	return takeKey(t, s, "key")
+}
+
+func takeKey(t *testing.T, s Store, key string) Result {
+	var res, err = s.Take(context.Background(), key)
+	require.NoError(t, err)
+	return res
+}
+
+func TestTokenBucketStore(t *testing.T) {
+
+	var c = newClock()
+	var s = NewTokenBucketStore(2, time.Second, 0).(*_TokenBucketStore)
+	s.now = c.Now
+
+	// Burst is the same as limit.
+
+	require.Equal(t, Result{Allowed: true, Limit: 2, Remaining: 1, Reset: 500 * time.Millisecond}, take(t, s))
+	require.Equal(t, Result{Allowed: true, Limit: 2, Remaining: 0, Reset: time.Second}, take(t, s))
+	require.Equal(t, Result{Limit: 2, RetryAfter: 500 * time.Millisecond, Reset: time.Second}, take(t, s))
+
+	// Keys are independent.
+
+	require.True(t, takeKey(t, s, "other").Allowed)
+
+	// Tokens are refilled at the rate, but not above the burst.
+
+	c.Advance(500 * time.Millisecond)
+	require.True(t, take(t, s).Allowed)
+	require.False(t, take(t, s).Allowed)
+
+	c.Advance(time.Hour)
+	require.Equal(t, 1, take(t, s).Remaining)
+}
+
+func TestTokenBucketStore_Burst(t *testing.T) {
+
+	var c = newClock()
+	var s = NewTokenBucketStore(1, time.Second, 3).(*_TokenBucketStore)
+	s.now = c.Now
+
+	for i := 0; i < 3; i++ {
+		require.True(t, take(t, s).Allowed)
+	}
+
+	var res = take(t, s)
+	require.False(t, res.Allowed)
+	require.Equal(t, 3, res.Limit)
+	require.Equal(t, time.Second, res.RetryAfter)
+	require.Equal(t, 3*time.Second, res.Reset)
+}
+
+func TestSlidingWindowStore(t *testing.T) {
+
+	var c = newClock()
+	var s = NewSlidingWindowStore(10, time.Minute).(*_SlidingWindowStore)
+	s.now = c.Now
+
+	for i := 0; i < 10; i++ {
+		require.True(t, take(t, s).Allowed)
+	}
+
+	// No previous window, so the whole current one must pass.
+
+	require.Equal(t, Result{Limit: 10, RetryAfter: time.Minute, Reset: time.Minute}, take(t, s))
+
+	// The previous window is weighted by the remaining part of the current one:
+	// 10 * 0.5 = 5 requests are counted.
+
+	c.Advance(time.Minute + 30*time.Second)
+
+	for i := 0; i < 5; i++ {
+		require.True(t, take(t, s).Allowed)
+	}
+
+	var res = take(t, s)
+	require.False(t, res.Allowed)
+	require.Equal(t, 0, res.Remaining)
+	require.Equal(t, 30*time.Second, res.Reset)
+
+	// 10 * (60-36)/60 + 5 + 1 = 10, so the next request is allowed after 6s.
+
+	require.Equal(t, 6*time.Second, res.RetryAfter)
+
+	c.Advance(res.RetryAfter)
+	require.True(t, take(t, s).Allowed)
+
+	// The previous window is dropped, if the gap is longer than the window.
+
+	c.Advance(2 * time.Minute)
+	require.Equal(t, 9, take(t, s).Remaining)
+}
+
+func TestNewStore_Panic(t *testing.T) {
+	require.Panics(t, func() { NewTokenBucketStore(0, time.Second, 0) })
+	require.Panics(t, func() { NewTokenBucketStore(1, 0, 0) })
+	require.Panics(t, func() { NewSlidingWindowStore(0, time.Second) })
+	require.Panics(t, func() { NewSlidingWindowStore(1, 0) })
+}
@@ -0,0 +1,278 @@
+package ekaweb_middleware
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/inaneverb/ekaweb/v2"
+	"github.com/inaneverb/ekaweb/v2/private"
+)
+
+type (
+	// TimeoutError is an error, that is stored by Timeout() middleware,
+	// when HTTP handler didn't manage to respond in time.
+	TimeoutError struct {
+		Timeout    time.Duration
+		StatusCode int // 503 or 504, see WithTimeoutStatus()
+	}
+
+	// TimeoutOption is a callback that allows to modify Timeout() middleware
+	// under its construction.
+	TimeoutOption func(t *_Timeout)
+
+	_Timeout struct {
+		timeout    time.Duration
+		statusCode int
+	}
+
+	// _TimeoutWriter wraps original http.ResponseWriter,
+	// rejecting all writes after the timeout. It's stored to UKVS
+	// by the outermost Timeout() middleware, allowing nested ones
+	// to override the timeout (see setContext()).
+	_TimeoutWriter struct {
+		orig        http.ResponseWriter
+		mu          sync.Mutex
+		ctx         context.Context // its deadline is the current timeout
+		wroteHeader bool
+	}
+
+	_TimeoutStateKey struct{}
+)
+
+// ErrTimeout is a typed TimeoutError "pattern".
+// Use errors.Is(err, ErrTimeout) to check whether an error is TimeoutError.
+var ErrTimeout = (*TimeoutError)(nil)
+
+// Timeout returns a new HTTP middleware, that limits the time of HTTP request
+// processing by 'timeout', using context.WithTimeout() for the http.Request's
+// context.Context. Non-positive 'timeout' means no middleware at all.
+//
+// The HTTP handler is executed in the same goroutine, so it must respect
+// the context.Context's cancellation. After the timeout, all writes
+// to http.ResponseWriter are rejected with http.ErrHandlerTimeout.
+// If the HTTP handler didn't write anything before the timeout,
+// TimeoutError is stored to the http.Request's context.Context
+// (see ErrorApply()), replacing any other error.
+//
+// The nested Timeout() middleware (e.g. registered for the route group)
+// overrides the outer one, so the timeout could be increased for some routes.
+// Only the deadline is replaced: the context.Context values and cancellations
+// (e.g. client disconnect), that are added between them, are kept.
+// The context.Cause() of the timed out context.Context is TimeoutError.
+// Has no error check before.
+func Timeout(timeout time.Duration, options ...TimeoutOption) ekaweb.Middleware {
+
+	if timeout <= 0 {
+		return ekaweb_private.NewEmptyMiddleware()
+	}
+
+	var t = _Timeout{timeout, http.StatusServiceUnavailable}
+
+	for _, option := range options {
+		if option != nil {
+			option(&t)
+		}
+	}
+
+	var m = func(next ekaweb.Handler) ekaweb.Handler {
+		return ekaweb.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			t.serveHTTP(next, w, r)
+		})
+	}
+
+	return ekaweb.MiddlewareFuncNoErrorCheck(m)
+}
+
+// WithTimeoutStatus sets HTTP status code, that is stored in TimeoutError.
+// Only 503 (default) and 504 HTTP status codes are allowed.
+func WithTimeoutStatus(statusCode int) TimeoutOption {
+	return func(t *_Timeout) {
+		if statusCode == http.StatusServiceUnavailable ||
+			statusCode == http.StatusGatewayTimeout {
+
+			t.statusCode = statusCode
+		}
+	}
+}
+
+func (e *TimeoutError) Error() string {
+	const D = "Middleware.Timeout: Request processing timed out"
+	if e == nil || e.Timeout <= 0 {
+		return D
+	}
+	return D + " (" + e.Timeout.String() + ")"
+}
+
+func (e *TimeoutError) Is(other error) bool {
+	var _, ok = other.(*TimeoutError)
+	return ok
+}
+
+////////////////////////////////////////////////////////////////////////////////
+///// _TimeoutWriter ///////////////////////////////////////////////////////////
+////////////////////////////////////////////////////////////////////////////////
+
+func (tw *_TimeoutWriter) Header() http.Header {
+	return tw.orig.Header()
+}
+
+func (tw *_TimeoutWriter) WriteHeader(statusCode int) {
+	if tw.markWritten() {
+		tw.orig.WriteHeader(statusCode)
+	}
+}
+
+func (tw *_TimeoutWriter) Write(b []byte) (int, error) {
+	if !tw.markWritten() {
+		return 0, http.ErrHandlerTimeout
+	}
+	return tw.orig.Write(b)
+}
+
+func (tw *_TimeoutWriter) Flush() {
+	if flusher, ok := tw.orig.(http.Flusher); ok && !tw.isTimedOut() {
+		flusher.Flush()
+	}
+}
+
+func (tw *_TimeoutWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	if hijacker, ok := tw.orig.(http.Hijacker); ok {
+		return hijacker.Hijack()
+	}
+	return nil, nil, http.ErrNotSupported
+}
+
+// Unwrap returns the original http.ResponseWriter.
+// It's used by http.ResponseController.
+func (tw *_TimeoutWriter) Unwrap() http.ResponseWriter {
+	return tw.orig
+}
+
+////////////////////////////////////////////////////////////////////////////////
+///// PRIVATE METHODS //////////////////////////////////////////////////////////
+////////////////////////////////////////////////////////////////////////////////
+
+func (t *_Timeout) serveHTTP(
+	next ekaweb.Handler, w http.ResponseWriter, r *http.Request) {
+
+	var ctx = r.Context()
+	var key = (*_TimeoutStateKey)(nil)
+
+	// The outer Timeout() middleware is found. Override its timeout.
+
+	if outer, _ := ekaweb_private.UkvsGet(ctx, key).(*_TimeoutWriter); outer != nil {
+		if ctx.Err() != nil {
+			next.ServeHTTP(w, r) // too late to override
+			return
+		}
+
+		var ctxTimeout, cancel = t.withOverriddenTimeout(ctx)
+		defer cancel()
+
+		if !outer.setContext(ctxTimeout) {
+			next.ServeHTTP(w, r) // the outer timeout is exceeded meanwhile
+			return
+		}
+
+		next.ServeHTTP(w, r.WithContext(ctxTimeout))
+		return
+	}
+
+	var ctxTimeout, cancel = context.WithTimeoutCause(ctx, t.timeout, t.newError())
+	defer cancel()
+
+	var tw = _TimeoutWriter{orig: w, ctx: ctxTimeout}
+
+	ekaweb_private.UkvsInsert(ctx, key, &tw)
+	next.ServeHTTP(&tw, r.WithContext(ctxTimeout))
+	ekaweb_private.UkvsRemove(ctx, key)
+
+	if tw.isTimedOutSilently() && !ekaweb_private.UkvsIsConnectionHijacked(ctx) {
+
+		ekaweb_private.UkvsInsertUserError(ctx, t.newError())
+	}
+}
+
+// withOverriddenTimeout returns a copy of 'ctx' (that is a context.Context
+// of the outer Timeout() middleware), replacing its deadline by the new one.
+// All values of 'ctx' are kept and all its cancellations are propagated
+// (e.g. client disconnect), except the deadline of the outer Timeout().
+func (t *_Timeout) withOverriddenTimeout(
+	ctx context.Context) (context.Context, context.CancelFunc) {
+
+	var ctxTimeout, cancelTimeout = context.WithTimeoutCause(
+		context.WithoutCancel(ctx), t.timeout, t.newError())
+
+	var ctxCancel, cancel = context.WithCancelCause(ctxTimeout)
+
+	var stop = context.AfterFunc(ctx, func() {
+		if cause := context.Cause(ctx); !errors.Is(cause, ErrTimeout) {
+			cancel(cause)
+		}
+	})
+
+	return ctxCancel, func() {
+		stop()
+		cancel(nil)
+		cancelTimeout()
+	}
+}
+
+// newError returns TimeoutError, that is stored to UKVS and is used
+// as the cause of context.Context's cancellation (see context.Cause()).
+func (t *_Timeout) newError() *TimeoutError {
+	return &TimeoutError{t.timeout, t.statusCode}
+}
+
+// setContext replaces the context.Context, which deadline is the current
+// timeout, unless that timeout is already exceeded. Reports whether it's done.
+func (tw *_TimeoutWriter) setContext(ctx context.Context) bool {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+
+	if tw.ctx.Err() == context.DeadlineExceeded {
+		return false
+	}
+
+	tw.ctx = ctx
+	return true
+}
+
+// markWritten marks the response as started, if the timeout
+// is not exceeded yet. Reports whether it's done.
+//
+// The deadline is checked synchronously, so the HTTP handler,
+// that has observed the timeout, can't write anything after that.
+func (tw *_TimeoutWriter) markWritten() bool {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+
+	if tw.ctx.Err() == context.DeadlineExceeded {
+		return false
+	}
+
+	tw.wroteHeader = true
+	return true
+}
+
+// isTimedOut reports whether the current timeout is exceeded.
+func (tw *_TimeoutWriter) isTimedOut() bool {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+
+	return tw.ctx.Err() == context.DeadlineExceeded
+}
+
+// isTimedOutSilently reports whether the current timeout is exceeded
+// and nothing is written before that.
+func (tw *_TimeoutWriter) isTimedOutSilently() bool {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+
+	return tw.ctx.Err() == context.DeadlineExceeded && !tw.wroteHeader
+}
@@ -0,0 +1,152 @@
+package ekaweb_middleware_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/inaneverb/ekaweb/v2"
+	"github.com/inaneverb/ekaweb/v2/middleware"
+)
+
+type ctxKey struct{}
+
+func TestTimeout(t *testing.T) {
+
+	var handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+		require.ErrorIs(t, context.Cause(r.Context()), ekaweb_middleware.ErrTimeout)
+	})
+
+	var w, err = serve(httptest.NewRequest("GET", "/", nil), handler,
+		ekaweb_middleware.Timeout(20*time.Millisecond,
+			ekaweb_middleware.WithTimeoutStatus(http.StatusGatewayTimeout)))
+
+	var timeoutErr *ekaweb_middleware.TimeoutError
+	require.ErrorAs(t, err, &timeoutErr)
+	require.Equal(t, http.StatusGatewayTimeout, timeoutErr.StatusCode)
+	require.Equal(t, 20*time.Millisecond, timeoutErr.Timeout)
+	require.Zero(t, w.Body.Len())
+}
+
+func TestTimeout_LateWrite(t *testing.T) {
+
+	var lateWriteErr = make(chan error, 1)
+
+	var handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+		w.WriteHeader(http.StatusOK) // ignored
+		var _, err = w.Write([]byte("late"))
+		lateWriteErr <- err
+	})
+
+	var w, err = serve(httptest.NewRequest("GET", "/", nil), handler,
+		ekaweb_middleware.Timeout(10*time.Millisecond))
+
+	require.ErrorIs(t, <-lateWriteErr, http.ErrHandlerTimeout)
+	require.ErrorIs(t, err, ekaweb_middleware.ErrTimeout)
+	require.Zero(t, w.Body.Len())
+
+	// The response, that is written in time, is kept and no error is stored.
+
+	handler = func(w http.ResponseWriter, r *http.Request) {
+		ekaweb.SendString(w, http.StatusOK, "in time")
+		<-r.Context().Done()
+		var _, err = w.Write([]byte("late"))
+		lateWriteErr <- err
+	}
+
+	w, err = serve(httptest.NewRequest("GET", "/", nil), handler,
+		ekaweb_middleware.Timeout(10*time.Millisecond))
+
+	require.ErrorIs(t, <-lateWriteErr, http.ErrHandlerTimeout)
+	require.NoError(t, err)
+	require.Equal(t, "in time", w.Body.String())
+}
+
+func TestTimeout_Nested(t *testing.T) {
+
+	// The value, that is added between the outer and the nested Timeout().
+
+	var withValue = ekaweb.MiddlewareFunc(func(next ekaweb.Handler) ekaweb.Handler {
+		return ekaweb.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			var ctx = context.WithValue(r.Context(), ctxKey{}, "value")
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	})
+
+	var handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "value", r.Context().Value(ctxKey{}))
+
+		var deadline, ok = r.Context().Deadline()
+		require.True(t, ok)
+		require.Greater(t, time.Until(deadline), 500*time.Millisecond)
+
+		// The outer timeout is exceeded, but the nested one is not.
+
+		time.Sleep(30 * time.Millisecond)
+		require.NoError(t, r.Context().Err())
+		ekaweb.SendString(w, http.StatusOK, "ok")
+	})
+
+	var w, err = serve(httptest.NewRequest("GET", "/", nil), handler,
+		ekaweb_middleware.Timeout(10*time.Millisecond),
+		withValue,
+		ekaweb_middleware.Timeout(time.Second))
+
+	require.NoError(t, err)
+	require.Equal(t, "ok", w.Body.String())
+
+	// The nested timeout could be tightened as well.
+
+	handler = func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "value", r.Context().Value(ctxKey{}))
+		<-r.Context().Done()
+	}
+
+	var start = time.Now()
+
+	_, err = serve(httptest.NewRequest("GET", "/", nil), handler,
+		ekaweb_middleware.Timeout(time.Second),
+		withValue,
+		ekaweb_middleware.Timeout(10*time.Millisecond))
+
+	require.ErrorIs(t, err, ekaweb_middleware.ErrTimeout)
+	require.Less(t, time.Since(start), 500*time.Millisecond)
+}
+
+func TestTimeout_NestedCancel(t *testing.T) {
+
+	// The cancellation, that is not a timeout of the outer Timeout()
+	// (e.g. client disconnect), is propagated through the nested one.
+
+	var cancelErr = errors.New("client has gone")
+
+	var withCancel = ekaweb.MiddlewareFunc(func(next ekaweb.Handler) ekaweb.Handler {
+		return ekaweb.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			var ctx, cancel = context.WithCancelCause(r.Context())
+			time.AfterFunc(10*time.Millisecond, func() { cancel(cancelErr) })
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	})
+
+	var handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+		require.ErrorIs(t, context.Cause(r.Context()), cancelErr)
+	})
+
+	var start = time.Now()
+
+	var _, err = serve(httptest.NewRequest("GET", "/", nil), handler,
+		ekaweb_middleware.Timeout(time.Second),
+		withCancel,
+		ekaweb_middleware.Timeout(2*time.Second))
+
+	require.NoError(t, err)
+	require.Less(t, time.Since(start), 500*time.Millisecond)
+}
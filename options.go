@@ -1,9 +1,13 @@
 package ekaweb
 
 import (
+	"crypto/tls"
+	"crypto/x509"
 	"encoding/json"
 	"io"
+	"net"
 	"net/http"
+	"os"
 	"time"
 
 	"github.com/inaneverb/ekaweb/v2/private"
@@ -42,6 +46,63 @@ func WithCodec[E ekaweb_private.Encoder, D ekaweb_private.Decoder](
 	}
 }
 
+// WithCodecs returns an Option, that registers a set of codecs, each of them
+// is bound to its MIME type (see NewCodec()). The order matters: the first
+// codec has the highest priority and is used as a default one.
+//
+// SendEncoded() chooses an encoder based on the Accept HTTP header,
+// and ScanEncoded() chooses a decoder based on the Content-Type HTTP header.
+// Codecs with empty MIME type or w/o both encoder and decoder are ignored.
+// If there's no codecs at all, it's the same as WithCodec(nil, nil).
+func WithCodecs(codecs ...Codec) RouterOption {
+
+	var opt ekaweb_private.RouterOptionCodec
+
+	for _, codec := range codecs {
+		if codec.MIMEType == "" ||
+			(codec.EncoderGetter == nil && codec.DecoderGetter == nil) {
+
+			continue
+		}
+		if opt.EncoderGetter == nil {
+			opt.EncoderGetter = codec.EncoderGetter
+		}
+		if opt.DecoderGetter == nil {
+			opt.DecoderGetter = codec.DecoderGetter
+		}
+		opt.Codecs = append(opt.Codecs, codec)
+	}
+
+	if opt.EncoderGetter == nil {
+		opt.EncoderGetter = wrapEncGetter(json.NewEncoder)
+	}
+	if opt.DecoderGetter == nil {
+		opt.DecoderGetter = wrapDecGetter(json.NewDecoder)
+	}
+
+	return &opt
+}
+
+// NewCodec returns a Codec, that is bound to the given 'mimeType'
+// (like MIMEApplicationJSON or "application/msgpack") and may be registered
+// by the WithCodecs(). Any of getters may be nil, meaning that this codec
+// is used only for encoding responses or only for decoding requests.
+func NewCodec[E ekaweb_private.Encoder, D ekaweb_private.Decoder](
+	mimeType string,
+	encGetter func(w io.Writer) E, decGetter func(r io.Reader) D) Codec {
+
+	var codec = Codec{MIMEType: mimeType}
+
+	if encGetter != nil {
+		codec.EncoderGetter = wrapEncGetter(encGetter)
+	}
+	if decGetter != nil {
+		codec.DecoderGetter = wrapDecGetter(decGetter)
+	}
+
+	return codec
+}
+
 // wrapEncGetter returns _EncoderGetter from its generic variant.
 func wrapEncGetter[E ekaweb_private.Encoder](
 	encGetter func(w io.Writer) E) ekaweb_private.EncoderGetter {
@@ -82,26 +143,115 @@ func WithTimeouts(readTimeout, writeTimeout time.Duration) ClientServerOption {
 	}
 }
 
+// WithTransport returns an Option, that sets http.RoundTripper
+// for the Client implementations, that are based on net/http.
+func WithTransport(transport http.RoundTripper) ClientServerOption {
+	return &ekaweb_private.ClientServerOptionTransport{Transport: transport}
+}
+
+// WithHTTP2 returns an Option, that enables HTTP/2 over TLS
+// with given tuning for the Server and Client implementations,
+// that are based on net/http. The 'maxConcurrentStreams' limits
+// the number of concurrent streams per connection, the 'maxReadFrameSize'
+// is the largest frame, that is accepted. Zero means default.
+func WithHTTP2(maxConcurrentStreams, maxReadFrameSize int) ClientServerOption {
+	return &ekaweb_private.ClientServerOptionHTTP2{
+		MaxConcurrentStreams: maxConcurrentStreams, MaxReadFrameSize: maxReadFrameSize,
+	}
+}
+
+// WithH2C is the same as WithHTTP2(), but enables cleartext HTTP/2 (h2c)
+// with prior knowledge as well, e.g. for internal service mesh traffic.
+// The Server still serves HTTP/1, while the Client uses HTTP/2 only.
+// The net/http based implementations support h2c and HTTP/2 tuning
+// only if they're built by Go 1.24 or newer.
+func WithH2C(maxConcurrentStreams, maxReadFrameSize int) ClientServerOption {
+	return &ekaweb_private.ClientServerOptionHTTP2{
+		H2C: true, MaxConcurrentStreams: maxConcurrentStreams, MaxReadFrameSize: maxReadFrameSize,
+	}
+}
+
 ////////////////////////////////////////////////////////////////////////////////
 ///// SERVER OPTIONS ///////////////////////////////////////////////////////////
 ////////////////////////////////////////////////////////////////////////////////
 
-func WithTransport(transport http.RoundTripper) ServerOption {
-	return &ekaweb_private.ServerOptionTransport{Transport: transport}
-}
-
 func WithHandler(handler ekaweb_private.Handler) ServerOption {
 	return &ekaweb_private.ServerOptionHandler{Handler: handler}
 }
 
+// WithListenAddr returns an Option, that adds listening address:
+// TCP "host:port" or Unix socket path with "unix:" prefix
+// (e.g. "unix:/run/app.sock", see also WithUnixSocket()).
+// It may be used multiple times to serve on multiple addresses.
 func WithListenAddr(addr string) ServerOption {
 	return &ekaweb_private.ServerOptionListenAddr{Addr: addr}
 }
 
+// WithUnixSocket returns an Option, that adds Unix socket listening address.
+// The stale socket file (e.g. after crash) is replaced. If 'mode' is not 0,
+// it's applied to the socket file (e.g. 0660 to restrict access to a group).
+func WithUnixSocket(path string, mode os.FileMode) ServerOption {
+	return &ekaweb_private.ServerOptionListenAddr{
+		Addr: ekaweb_private.UnixAddrPrefix + path, Mode: mode,
+	}
+}
+
+// WithListener returns an Option, that adds pre-opened listener
+// (e.g. inherited from a parent process). The Server closes it when stopped.
+// nbio based Server supports only TCP and Unix socket listeners.
+func WithListener(ln net.Listener) ServerOption {
+	return &ekaweb_private.ServerOptionListener{Listener: ln}
+}
+
+// WithSystemdListeners returns an Option, that adds listeners,
+// passed by systemd socket activation (LISTEN_FDS, see sd_listen_fds(3)).
+// It may be combined with WithListenAddr() as a fallback, that is used
+// only when the process is started w/o socket activation.
+func WithSystemdListeners() ServerOption {
+	return &ekaweb_private.ServerOptionSystemd{}
+}
+
 func WithKeepAlive(enabled bool, duration time.Duration) ServerOption {
 	return &ekaweb_private.ServerOptionKeepAlive{Enabled: enabled, Duration: duration}
 }
 
+// WithShutdown returns an Option, that configures the graceful shutdown
+// (see Server.StopContext()). The 'drainPeriod' is a time between flipping
+// readiness (see ReadinessHandler()) and the actual shutdown,
+// while HTTP requests are still served. The 'timeout' is used by Server.Stop().
+// Defaults: no drain period, 5s timeout.
+func WithShutdown(drainPeriod, timeout time.Duration) ServerOption {
+	return &ekaweb_private.ServerOptionShutdown{DrainPeriod: drainPeriod, Timeout: timeout}
+}
+
+// WithTLS returns an Option, that enables HTTPS using PEM encoded
+// certificate and key files. If 'reloadInterval' is positive, the files
+// are checked each 'reloadInterval' and the certificate is replaced
+// w/o restarting the Server, if they're changed (see CertReloader).
+// The files are loaded by the Server constructor; the loading error
+// is returned by Server.AsyncStart().
+func WithTLS(certFile, keyFile string, reloadInterval time.Duration) ServerOption {
+	return &ekaweb_private.ServerOptionTLS{
+		CertFile: certFile, KeyFile: keyFile, ReloadInterval: reloadInterval,
+	}
+}
+
+// WithTLSConfig returns an Option, that enables HTTPS using given tls.Config.
+// The 'config' must provide a certificate (e.g. CertReloader.GetCertificate).
+// It's cloned, so it's safe to modify it after.
+func WithTLSConfig(config *tls.Config) ServerOption {
+	return &ekaweb_private.ServerOptionTLS{Config: config}
+}
+
+// WithClientAuth returns an Option, that enables mutual TLS: client
+// certificates are verified using 'cas' (see LoadCertPool()).
+// If 'required' is false, clients w/o certificate are allowed as well.
+// Use ClientCertificate() to get the verified client certificate.
+// It requires WithTLS() or WithTLSConfig().
+func WithClientAuth(cas *x509.CertPool, required bool) ServerOption {
+	return &ekaweb_private.ServerOptionClientAuth{CAs: cas, Required: required}
+}
+
 ////////////////////////////////////////////////////////////////////////////////
 ///// CLIENT OPTIONS ///////////////////////////////////////////////////////////
 ////////////////////////////////////////////////////////////////////////////////
@@ -113,3 +263,9 @@ func WithHostAddr(addr string) ClientOption {
 func WithUserAgent(userAgent string) ClientOption {
 	return &ekaweb_private.ClientOptionUserAgent{UserAgent: userAgent}
 }
+
+// WithInterceptors returns an Option, that wraps the Client by given
+// interceptors (see WrapClient()). The first interceptor is the outermost one.
+func WithInterceptors(interceptors ...ClientInterceptor) ClientOption {
+	return &ekaweb_private.ClientOptionInterceptors{Interceptors: interceptors}
+}
@@ -0,0 +1,106 @@
+package ekaweb_private
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+)
+
+type (
+	// ClientResponseRecorder wraps original ClientResponse, recording
+	// HTTP status code and headers of the HTTP response. It's a common part
+	// of client interceptors, that analyze results of Client.Do() calls.
+	ClientResponseRecorder struct {
+		orig       ClientResponse
+		statusCode int
+		header     http.Header
+		skip       func(statusCode int, header http.Header) bool
+		skipped    bool
+	}
+
+	// clientResponseRecorderStream is a ClientResponseRecorder,
+	// that wraps original ClientResponseStream.
+	clientResponseRecorderStream struct {
+		*ClientResponseRecorder
+	}
+)
+
+// NewClientResponseRecorder returns a new ClientResponseRecorder,
+// wrapping given 'orig' (it may be nil). If 'skip' is not nil and returns
+// true, the data is not passed to the original ClientResponse (see Skipped()).
+func NewClientResponseRecorder(
+	orig ClientResponse,
+	skip func(statusCode int, header http.Header) bool) *ClientResponseRecorder {
+
+	return &ClientResponseRecorder{orig: orig, skip: skip}
+}
+
+// Wrap returns ClientResponseRecorder as ClientResponse, that implements
+// ClientResponseStream only if the original one does.
+func (p *ClientResponseRecorder) Wrap() ClientResponse {
+	if _, ok := p.orig.(ClientResponseStream); ok {
+		return clientResponseRecorderStream{p}
+	}
+	return p
+}
+
+// StatusCode returns HTTP status code of the response or 0,
+// if there's no response (e.g. network error).
+func (p *ClientResponseRecorder) StatusCode() int {
+	return p.statusCode
+}
+
+// Header returns HTTP headers of the response, if they're passed by Client
+// (see ClientResponseHeaders, ClientResponseStream).
+func (p *ClientResponseRecorder) Header() http.Header {
+	return p.header
+}
+
+// Skipped reports whether the response is skipped
+// (not passed to the original ClientResponse).
+func (p *ClientResponseRecorder) Skipped() bool {
+	return p.skipped
+}
+
+func (p *ClientResponseRecorder) FromHeaders(statusCode int, header, trailer http.Header) {
+	p.statusCode, p.header = statusCode, header
+	if respHeaders, ok := p.orig.(ClientResponseHeaders); ok {
+		respHeaders.FromHeaders(statusCode, header, trailer)
+	}
+}
+
+// FromData records HTTP status code and passes the data to the original
+// ClientResponse. If there's no one, it behaves like Client does
+// w/o ClientResponse: non 2xx HTTP status code is an error.
+func (p *ClientResponseRecorder) FromData(statusCode int, data []byte) error {
+	p.statusCode = statusCode
+
+	switch {
+	case p.skip != nil && p.skip(statusCode, p.header):
+		p.skipped = true
+		return nil
+
+	case p.orig != nil:
+		return p.orig.FromData(statusCode, data)
+
+	case statusCode < 200 || statusCode > 299:
+		const E = "HTTP status code is %d, but response is not declared"
+		return fmt.Errorf(E, statusCode)
+
+	default:
+		return nil
+	}
+}
+
+func (p clientResponseRecorderStream) FromStream(
+	statusCode int, header http.Header, body io.Reader) error {
+
+	p.statusCode, p.header = statusCode, header
+
+	if p.skip != nil && p.skip(statusCode, header) {
+		p.skipped = true
+		return nil
+	}
+
+	return p.orig.(ClientResponseStream).FromStream(statusCode, header, body)
+}
@@ -0,0 +1,86 @@
+package ekaweb_private_test
+
+import (
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/inaneverb/ekaweb/v2/private"
+)
+
+type (
+	// dataResponse is ekaweb_private.ClientResponse, that keeps the data.
+	dataResponse struct {
+		data string
+	}
+
+	// streamResponse is ekaweb_private.ClientResponseStream,
+	// that keeps the body.
+	streamResponse struct {
+		dataResponse
+	}
+)
+
+func (r *dataResponse) FromData(_ int, data []byte) error {
+	r.data = string(data)
+	return nil
+}
+
+func (r *streamResponse) FromStream(_ int, _ http.Header, body io.Reader) error {
+	var data, err = io.ReadAll(body)
+	r.data = string(data)
+	return err
+}
+
+func TestClientResponseRecorder(t *testing.T) {
+
+	var resp dataResponse
+	var p = ekaweb_private.NewClientResponseRecorder(&resp, nil)
+
+	var wrapped = p.Wrap()
+	var _, isStream = wrapped.(ekaweb_private.ClientResponseStream)
+	require.False(t, isStream)
+
+	var header = http.Header{"X-Test": {"1"}}
+	wrapped.(ekaweb_private.ClientResponseHeaders).FromHeaders(http.StatusOK, header, nil)
+
+	require.NoError(t, wrapped.FromData(http.StatusOK, []byte("data")))
+	require.Equal(t, http.StatusOK, p.StatusCode())
+	require.Equal(t, header, p.Header())
+	require.Equal(t, "data", resp.data)
+	require.False(t, p.Skipped())
+
+	// Non 2xx HTTP status code is an error, if there's no response.
+
+	p = ekaweb_private.NewClientResponseRecorder(nil, nil)
+	require.ErrorContains(t, p.Wrap().FromData(http.StatusNotFound, nil), "404")
+	require.Equal(t, http.StatusNotFound, p.StatusCode())
+}
+
+func TestClientResponseRecorder_Stream(t *testing.T) {
+
+	var resp streamResponse
+	var skip = func(statusCode int, _ http.Header) bool {
+		return statusCode == http.StatusServiceUnavailable
+	}
+
+	var p = ekaweb_private.NewClientResponseRecorder(&resp, skip)
+	var wrapped = p.Wrap().(ekaweb_private.ClientResponseStream)
+
+	var err = wrapped.FromStream(http.StatusServiceUnavailable, nil, strings.NewReader("skipped"))
+	require.NoError(t, err)
+	require.True(t, p.Skipped())
+	require.Empty(t, resp.data)
+
+	p = ekaweb_private.NewClientResponseRecorder(&resp, skip)
+	wrapped = p.Wrap().(ekaweb_private.ClientResponseStream)
+
+	err = wrapped.FromStream(http.StatusOK, http.Header{}, strings.NewReader("data"))
+	require.NoError(t, err)
+	require.False(t, p.Skipped())
+	require.Equal(t, http.StatusOK, p.StatusCode())
+	require.Equal(t, "data", resp.data)
+}
@@ -0,0 +1,136 @@
+package ekaweb_private
+
+import (
+	"context"
+	"errors"
+	"io"
+	"mime"
+	"strings"
+)
+
+var (
+	// ErrNotAcceptable is stored as an error, when there's no registered
+	// codec, that can encode a response with any MIME type, that is
+	// acceptable by the client (Accept HTTP header). Suits 406 HTTP status.
+	ErrNotAcceptable = errors.New("ekaweb: no codec for acceptable MIME types")
+
+	// ErrUnsupportedMediaType is stored as an error, when there's no
+	// registered codec, that can decode a request body of its MIME type
+	// (Content-Type HTTP header). Suits 415 HTTP status.
+	ErrUnsupportedMediaType = errors.New("ekaweb: no codec for request MIME type")
+)
+
+// NegotiateEncoder returns MIME type and EncoderGetter of the codec,
+// that is the best for given 'accept' (values of Accept HTTP header).
+// Codecs registry is taken from the context.Context.
+//
+// If there's no codecs registry, the stored default EncoderGetter and
+// an empty MIME type are returned. If there's no suitable codec,
+// ErrNotAcceptable is returned.
+//
+// NOTE.
+// If the codec with the highest priority is chosen, the stored default
+// EncoderGetter is returned, because it may be wrapped by the router
+// (like jRPC router does).
+func NegotiateEncoder(
+	ctx context.Context, accept []string) (string, EncoderGetter, error) {
+
+	var codec = UkvsGetCodec(ctx)
+	if len(codec.Codecs) == 0 {
+		return "", codec.EncoderGetter, nil
+	}
+
+	// Avoid allocations for the most common case: a few codecs.
+
+	var offersBuf [8]string
+	var indicesBuf [8]int
+
+	var offers, indices = offersBuf[:0], indicesBuf[:0]
+	if len(codec.Codecs) > len(offersBuf) {
+		offers = make([]string, 0, len(codec.Codecs))
+		indices = make([]int, 0, len(codec.Codecs))
+	}
+
+	for i := range codec.Codecs {
+		if codec.Codecs[i].EncoderGetter != nil {
+			indices = append(indices, i)
+			offers = append(offers, codec.Codecs[i].MIMEType)
+		}
+	}
+
+	var best = NegotiateMediaType(accept, offers)
+	if best == "" {
+		return "", nil, ErrNotAcceptable
+	}
+
+	for i := range offers {
+		if offers[i] != best {
+			continue
+		}
+		var chosen = &codec.Codecs[indices[i]]
+		if i == 0 && codec.EncoderGetter != nil {
+			return chosen.MIMEType, codec.EncoderGetter, nil
+		}
+		return chosen.MIMEType, chosen.EncoderGetter, nil
+	}
+
+	return "", nil, ErrNotAcceptable // unreachable
+}
+
+// NegotiateDecoder returns DecoderGetter of the codec, which MIME type
+// is the same as given 'contentType' (value of Content-Type HTTP header).
+// Codecs registry is taken from the context.Context.
+//
+// If there's no codecs registry or 'contentType' is empty, the stored
+// default DecoderGetter is returned. If there's no suitable codec,
+// ErrUnsupportedMediaType is returned.
+func NegotiateDecoder(
+	ctx context.Context, contentType string) (DecoderGetter, error) {
+
+	var codec = UkvsGetCodec(ctx)
+	if len(codec.Codecs) == 0 || contentType == "" {
+		return codec.DecoderGetter, nil
+	}
+
+	var mediaType, _, err = mime.ParseMediaType(contentType)
+	if err != nil {
+		return nil, ErrUnsupportedMediaType
+	}
+
+	for i := range codec.Codecs {
+		if codec.Codecs[i].DecoderGetter != nil &&
+			baseMediaType(codec.Codecs[i].MIMEType) == mediaType {
+
+			return codec.Codecs[i].DecoderGetter, nil
+		}
+	}
+
+	return nil, ErrUnsupportedMediaType
+}
+
+// DecodeStreamWithMIME is the same as just DecodeStream(), but uses
+// the decoder of codec, that is chosen by NegotiateDecoder().
+func DecodeStreamWithMIME(
+	ctx context.Context, contentType string, r io.Reader, to any) error {
+
+	var decoderGetter, err = NegotiateDecoder(ctx, contentType)
+	if err != nil {
+		return err
+	}
+
+	if decoderGetter == nil {
+		return DecodeStream(ctx, r, to)
+	}
+
+	if err = decoderGetter(r).Decode(to); err == io.EOF {
+		err = nil
+	}
+
+	return err
+}
+
+// baseMediaType returns lowercased MIME type w/o parameters.
+func baseMediaType(mimeType string) string {
+	mimeType, _, _ = strings.Cut(mimeType, ";")
+	return strings.ToLower(strings.TrimSpace(mimeType))
+}
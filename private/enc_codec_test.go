@@ -0,0 +1,86 @@
+package ekaweb_private_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"testing"
+
+	"github.com/inaneverb/ekaweb/v2/private"
+)
+
+func newCodec(mimeType string) ekaweb_private.Codec {
+	return ekaweb_private.Codec{
+		MIMEType: mimeType,
+		EncoderGetter: func(w io.Writer) ekaweb_private.Encoder {
+			return json.NewEncoder(w)
+		},
+		DecoderGetter: func(r io.Reader) ekaweb_private.Decoder {
+			return json.NewDecoder(r)
+		},
+	}
+}
+
+func newCodecContext(codecs ...ekaweb_private.Codec) context.Context {
+	var opt = ekaweb_private.RouterOptionCodec{Codecs: codecs}
+	if len(codecs) > 0 {
+		opt.EncoderGetter = codecs[0].EncoderGetter
+		opt.DecoderGetter = codecs[0].DecoderGetter
+	}
+	var gen = ekaweb_private.NewUkvsMapGeneratorSlice()
+	return ekaweb_private.NewUkvsManager(gen, opt).InjectUkvs(context.Background())
+}
+
+func TestNegotiateEncoder(t *testing.T) {
+
+	var ctx = newCodecContext(
+		newCodec("application/json"), newCodec("application/msgpack"))
+
+	var tests = []struct {
+		accept []string
+		want   string
+		err    error
+	}{
+		{nil, "application/json", nil},
+		{[]string{"application/msgpack"}, "application/msgpack", nil},
+		{[]string{"application/*;q=0.5, application/msgpack"}, "application/msgpack", nil},
+		{[]string{"text/html"}, "", ekaweb_private.ErrNotAcceptable},
+	}
+
+	for _, tt := range tests {
+		var mimeType, encoderGetter, err = ekaweb_private.NegotiateEncoder(ctx, tt.accept)
+		if mimeType != tt.want || err != tt.err || (err == nil && encoderGetter == nil) {
+			t.Errorf("accept %q: got %q, %v, want %q, %v",
+				tt.accept, mimeType, err, tt.want, tt.err)
+		}
+	}
+
+	var mimeType, _, err = ekaweb_private.NegotiateEncoder(newCodecContext(), nil)
+	if mimeType != "" || err != nil {
+		t.Errorf("no registry: got %q, %v", mimeType, err)
+	}
+}
+
+func TestNegotiateDecoder(t *testing.T) {
+
+	var ctx = newCodecContext(
+		newCodec("application/json; charset=utf-8"), newCodec("application/msgpack"))
+
+	var tests = []struct {
+		contentType string
+		err         error
+	}{
+		{"", nil},
+		{"application/json", nil},
+		{"Application/MsgPack", nil},
+		{"text/plain", ekaweb_private.ErrUnsupportedMediaType},
+		{"malformed;;", ekaweb_private.ErrUnsupportedMediaType},
+	}
+
+	for _, tt := range tests {
+		var decoderGetter, err = ekaweb_private.NegotiateDecoder(ctx, tt.contentType)
+		if err != tt.err || (err == nil && decoderGetter == nil) {
+			t.Errorf("content type %q: got %v, want %v", tt.contentType, err, tt.err)
+		}
+	}
+}
@@ -0,0 +1,35 @@
+package ekaweb_private
+
+import (
+	"net/http"
+	"slices"
+	"sync"
+)
+
+// serveUkvsManager is UkvsManager of ServeMiddlewares().
+// It's created only if it's used.
+var serveUkvsManager = sync.OnceValue(func() *UkvsManager {
+	return NewUkvsManager(NewUkvsMapGeneratorGoMap(), RouterOptionCodec{})
+})
+
+// ServeMiddlewares performs HTTP request 'r' by 'handler', wrapped
+// by 'middlewares' (the first one is the outermost), the same way routers do:
+// UKVS is prepared before and is returned after. Returns the stored error.
+//
+// It allows to use middlewares w/o router. Mostly, in their tests.
+func ServeMiddlewares(
+	w http.ResponseWriter, r *http.Request,
+	handler Handler, middlewares ...Middleware) error {
+
+	var manager = serveUkvsManager()
+
+	var ctx = manager.InjectUkvs(r.Context())
+	defer manager.ReturnUkvs(ctx)
+
+	// MergeMiddlewares() reverses the slice in place.
+
+	var h = MergeMiddlewares(slices.Clone(middlewares), handler)
+	h.ServeHTTP(w, r.WithContext(ctx))
+
+	return UkvsGetUserError(ctx)
+}
@@ -0,0 +1,50 @@
+package ekaweb_private_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/inaneverb/ekaweb/v2/private"
+)
+
+func TestServeMiddlewares(t *testing.T) {
+
+	var order []string
+	var trace = func(name string) ekaweb_private.Middleware {
+		return ekaweb_private.MiddlewareFunc(func(next ekaweb_private.Handler) ekaweb_private.Handler {
+			return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				order = append(order, name)
+				next.ServeHTTP(w, r)
+			})
+		})
+	}
+
+	var handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		order = append(order, "handler")
+		ekaweb_private.UkvsInsertUserError(r.Context(), errors.New("failed"))
+		w.WriteHeader(http.StatusTeapot)
+	})
+
+	var middlewares = []ekaweb_private.Middleware{trace("first"), trace("second")}
+	var w = httptest.NewRecorder()
+
+	var err = ekaweb_private.ServeMiddlewares(w,
+		httptest.NewRequest(http.MethodGet, "/", nil), handler, middlewares...)
+
+	require.EqualError(t, err, "failed")
+	require.Equal(t, http.StatusTeapot, w.Code)
+	require.Equal(t, []string{"first", "second", "handler"}, order)
+
+	// The given middlewares are left as is and can be used again.
+
+	order = nil
+	err = ekaweb_private.ServeMiddlewares(httptest.NewRecorder(),
+		httptest.NewRequest(http.MethodGet, "/", nil), handler, middlewares...)
+
+	require.EqualError(t, err, "failed")
+	require.Equal(t, []string{"first", "second", "handler"}, order)
+}
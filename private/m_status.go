@@ -0,0 +1,93 @@
+package ekaweb_private
+
+import (
+	"bufio"
+	"context"
+	"net"
+	"net/http"
+)
+
+// StatusRecorder wraps original http.ResponseWriter, recording HTTP status
+// code and the size of HTTP response body. It's a common part of middlewares,
+// that observe HTTP responses (like metrics and tracing ones).
+type StatusRecorder struct {
+	orig       http.ResponseWriter
+	statusCode int
+	size       int64
+}
+
+// NewStatusRecorder returns a new StatusRecorder, wrapping given 'orig'.
+func NewStatusRecorder(orig http.ResponseWriter) *StatusRecorder {
+	return &StatusRecorder{orig: orig}
+}
+
+func (p *StatusRecorder) Header() http.Header {
+	return p.orig.Header()
+}
+
+func (p *StatusRecorder) WriteHeader(statusCode int) {
+	if p.statusCode == 0 && statusCode >= 200 {
+		p.statusCode = statusCode
+	}
+	p.orig.WriteHeader(statusCode)
+}
+
+func (p *StatusRecorder) Write(b []byte) (int, error) {
+	if p.statusCode == 0 {
+		p.statusCode = http.StatusOK
+	}
+	var n, err = p.orig.Write(b)
+	p.size += int64(n)
+	return n, err
+}
+
+func (p *StatusRecorder) Flush() {
+	if flusher, ok := p.orig.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+func (p *StatusRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	if hijacker, ok := p.orig.(http.Hijacker); ok {
+		return hijacker.Hijack()
+	}
+	return nil, nil, http.ErrNotSupported
+}
+
+// Unwrap returns the original http.ResponseWriter.
+// It's used by http.ResponseController.
+func (p *StatusRecorder) Unwrap() http.ResponseWriter {
+	return p.orig
+}
+
+// Size returns the number of written bytes of HTTP response body.
+func (p *StatusRecorder) Size() int64 {
+	return p.size
+}
+
+// StatusCode returns HTTP status code of the response. If nothing is
+// written yet, the response is up to the error handler (or the server,
+// that responds with 200 by default), so the status code is resolved
+// using the error from 'ctx' and 'errorStatusGet' (500 if it's nil).
+func (p *StatusRecorder) StatusCode(
+	ctx context.Context, errorStatusGet func(err error) int) int {
+
+	var err = UkvsGetUserError(ctx)
+
+	switch {
+	case p.statusCode != 0:
+		return p.statusCode
+
+	case UkvsIsConnectionHijacked(ctx):
+		return http.StatusSwitchingProtocols
+
+	case err != nil && errorStatusGet != nil:
+		return errorStatusGet(err)
+
+	case err != nil:
+		return http.StatusInternalServerError
+
+	default:
+		return http.StatusOK
+	}
+}
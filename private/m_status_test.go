@@ -0,0 +1,51 @@
+package ekaweb_private_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/inaneverb/ekaweb/v2/private"
+)
+
+func TestStatusRecorder(t *testing.T) {
+
+	var m = ekaweb_private.NewUkvsManager(
+		ekaweb_private.NewUkvsMapGeneratorGoMap(), ekaweb_private.RouterOptionCodec{})
+
+	var ctx = m.InjectUkvs(context.Background())
+	defer m.ReturnUkvs(ctx)
+
+	var w = httptest.NewRecorder()
+	var p = ekaweb_private.NewStatusRecorder(w)
+
+	require.Equal(t, http.StatusOK, p.StatusCode(ctx, nil))
+
+	// The error is stored, but nothing is written yet.
+
+	ekaweb_private.UkvsInsertUserError(ctx, errors.New("error"))
+	require.Equal(t, http.StatusInternalServerError, p.StatusCode(ctx, nil))
+	require.Equal(t, http.StatusTeapot, p.StatusCode(ctx, func(error) int { return http.StatusTeapot }))
+
+	// The first status code is the final one.
+
+	p.WriteHeader(http.StatusAccepted)
+	p.WriteHeader(http.StatusOK)
+
+	var n, err = p.Write([]byte("data"))
+	require.NoError(t, err)
+	require.Equal(t, 4, n)
+
+	require.Equal(t, http.StatusAccepted, p.StatusCode(ctx, nil))
+	require.Equal(t, int64(4), p.Size())
+	require.Same(t, w, http.ResponseWriter(p).(interface{ Unwrap() http.ResponseWriter }).Unwrap())
+
+	// The original http.ResponseWriter doesn't support hijacking.
+
+	_, _, err = p.Hijack()
+	require.ErrorIs(t, err, http.ErrNotSupported)
+}
@@ -0,0 +1,274 @@
+package ekaweb_private
+
+import (
+	"strconv"
+	"strings"
+)
+
+type (
+	// _AcceptSpec is one element of Accept-like HTTP header, like
+	// "text/html;level=1;q=0.5", "gzip;q=1.0", "en-US", etc.
+	_AcceptSpec struct {
+		value  string   // lowercased value w/o parameters
+		params []string // lowercased "key=value" parameters (media types only)
+		q      float64  // quality (weight) in range [0..1]
+	}
+
+	// _AcceptMatcher reports whether given _AcceptSpec matches given 'offer'
+	// (already lowercased), and how specific that match is.
+	// The greater specificity is, the more specific match is.
+	_AcceptMatcher = func(spec *_AcceptSpec, offer string) (specificity int, ok bool)
+)
+
+// qualityIdentityImplicit is a quality of "identity" content coding,
+// if it's not mentioned in Accept-Encoding header.
+// It's acceptable, but any explicitly mentioned coding is preferred.
+const qualityIdentityImplicit = 0.001
+
+// NegotiateMediaType returns the best offer (MIME type) from given 'offers'
+// based on Accept HTTP header's values (RFC 9110, section 12.5.1).
+// Wildcards (*/*, text/*), q-values and media type parameters are supported.
+//
+// If 'header' is empty (no Accept header), the first offer is returned.
+// If no offer is acceptable, an empty string is returned.
+func NegotiateMediaType(header []string, offers []string) string {
+	var specs = parseAcceptHeader(header, true)
+	if len(specs) == 0 {
+		return firstOffer(offers)
+	}
+	return negotiate(specs, offers, matchMediaType, false)
+}
+
+// NegotiateCharset returns the best offer (charset) from given 'offers'
+// based on Accept-Charset HTTP header's values (RFC 9110, section 12.5.2).
+//
+// If 'header' is empty (no Accept-Charset header), the first offer
+// is returned. If no offer is acceptable, an empty string is returned.
+func NegotiateCharset(header []string, offers []string) string {
+	var specs = parseAcceptHeader(header, false)
+	if len(specs) == 0 {
+		return firstOffer(offers)
+	}
+	return negotiate(specs, offers, matchToken, false)
+}
+
+// NegotiateEncoding returns the best offer (content coding) from given
+// 'offers' based on Accept-Encoding HTTP header's values
+// (RFC 9110, section 12.5.3).
+//
+// If there's no Accept-Encoding header ('header' is nil), the first offer
+// is returned. If the header is present but empty, only "identity" is
+// acceptable. The "identity" is always acceptable unless it's explicitly
+// excluded by "identity;q=0" or "*;q=0".
+// If no offer is acceptable, an empty string is returned.
+func NegotiateEncoding(header []string, offers []string) string {
+	if header == nil {
+		return firstOffer(offers)
+	}
+	return negotiate(parseAcceptHeader(header, false), offers, matchToken, true)
+}
+
+// NegotiateLanguage returns the best offer (language tag) from given 'offers'
+// based on Accept-Language HTTP header's values (RFC 9110, section 12.5.4).
+// Language ranges are matched using basic filtering (RFC 4647, section 3.3.1),
+// so "en" matches "en-US".
+//
+// If 'header' is empty (no Accept-Language header), the first offer
+// is returned. If no offer is acceptable, an empty string is returned.
+func NegotiateLanguage(header []string, offers []string) string {
+	var specs = parseAcceptHeader(header, false)
+	if len(specs) == 0 {
+		return firstOffer(offers)
+	}
+	return negotiate(specs, offers, matchLanguage, false)
+}
+
+////////////////////////////////////////////////////////////////////////////////
+///// PRIVATE FUNCTIONS ////////////////////////////////////////////////////////
+////////////////////////////////////////////////////////////////////////////////
+
+// negotiate returns an offer from 'offers' with the highest quality.
+// The quality of each offer is the quality of the most specific _AcceptSpec,
+// that matches the offer. If qualities are the same, the offer with more
+// specific match wins. If they're the same too, the first offer wins.
+//
+// If 'implicitIdentity' is set, the "identity" offer is acceptable
+// even if there's no matched _AcceptSpec (content codings only).
+func negotiate(
+	specs []_AcceptSpec, offers []string,
+	matcher _AcceptMatcher, implicitIdentity bool) string {
+
+	var bestOffer string
+	var bestQ, bestSpecificity = 0.0, -1
+
+	for _, offer := range offers {
+		var offerLowered = strings.ToLower(strings.TrimSpace(offer))
+		var q, specificity = 0.0, -1
+
+		for i := range specs {
+			if s, ok := matcher(&specs[i], offerLowered); ok && s > specificity {
+				q, specificity = specs[i].q, s
+			}
+		}
+
+		if specificity == -1 && implicitIdentity && offerLowered == "identity" {
+			q = qualityIdentityImplicit
+		}
+
+		if q > bestQ || (q == bestQ && q > 0 && specificity > bestSpecificity) {
+			bestOffer, bestQ, bestSpecificity = offer, q, specificity
+		}
+	}
+
+	return bestOffer
+}
+
+// parseAcceptHeader parses all given Accept-like HTTP header's values,
+// returning a list of _AcceptSpec. Malformed elements are skipped.
+// If 'withParams' is set, parameters before "q" are kept (media types).
+func parseAcceptHeader(header []string, withParams bool) []_AcceptSpec {
+
+	var specs []_AcceptSpec
+
+	for _, value := range header {
+		for value != "" {
+			var elem string
+			elem, value, _ = strings.Cut(value, ",")
+
+			var spec, ok = parseAcceptSpec(elem, withParams)
+			if ok {
+				specs = append(specs, spec)
+			}
+		}
+	}
+
+	return specs
+}
+
+// parseAcceptSpec parses one element of Accept-like HTTP header.
+func parseAcceptSpec(elem string, withParams bool) (_AcceptSpec, bool) {
+
+	var spec = _AcceptSpec{q: 1}
+	var rest string
+
+	spec.value, rest, _ = strings.Cut(elem, ";")
+	if spec.value = strings.ToLower(strings.TrimSpace(spec.value)); spec.value == "" {
+		return spec, false
+	}
+
+	for rest != "" {
+		var param string
+		param, rest, _ = strings.Cut(rest, ";")
+
+		var key, value, _ = strings.Cut(param, "=")
+		key = strings.ToLower(strings.TrimSpace(key))
+		value = strings.Trim(strings.TrimSpace(value), `"`)
+
+		switch {
+		case key == "":
+			continue
+
+		case key == "q":
+			var q, err = strconv.ParseFloat(value, 64)
+			if err != nil || q < 0 || q > 1 {
+				return spec, false
+			}
+			spec.q = q
+			return spec, true // the rest are accept-ext params, ignore them
+
+		case withParams:
+			spec.params = append(spec.params, key+"="+strings.ToLower(value))
+		}
+	}
+
+	return spec, true
+}
+
+// matchMediaType is a _AcceptMatcher for media types (MIME).
+func matchMediaType(spec *_AcceptSpec, offer string) (int, bool) {
+
+	var offerType, offerParams, _ = strings.Cut(offer, ";")
+	offerType = strings.TrimSpace(offerType)
+
+	var specType, specSubtype, _ = strings.Cut(spec.value, "/")
+	var typ, subtype, _ = strings.Cut(offerType, "/")
+
+	switch {
+	case specType == "*" && (specSubtype == "*" || specSubtype == ""):
+		return 0, true
+
+	case specType != typ:
+		return 0, false
+
+	case specSubtype == "*":
+		return 1, true
+
+	case specSubtype != subtype:
+		return 0, false
+	}
+
+	// Exact type/subtype match. All params of spec must be presented in offer.
+
+	for _, param := range spec.params {
+		if !hasMediaTypeParam(offerParams, param) {
+			return 0, false
+		}
+	}
+
+	return 2 + len(spec.params), true
+}
+
+// matchToken is a _AcceptMatcher for simple tokens (charsets, codings).
+func matchToken(spec *_AcceptSpec, offer string) (int, bool) {
+	switch spec.value {
+	case "*":
+		return 0, true
+	case offer:
+		return 1, true
+	default:
+		return 0, false
+	}
+}
+
+// matchLanguage is a _AcceptMatcher for language ranges.
+func matchLanguage(spec *_AcceptSpec, offer string) (int, bool) {
+
+	switch {
+	case spec.value == "*":
+		return 0, true
+
+	case spec.value == offer,
+		strings.HasPrefix(offer, spec.value) && offer[len(spec.value)] == '-':
+
+		return 1 + strings.Count(spec.value, "-"), true
+
+	default:
+		return 0, false
+	}
+}
+
+// hasMediaTypeParam reports whether given 'params' (the parameters part
+// of media type, like "charset=utf-8; level=1") contains 'param' (key=value).
+func hasMediaTypeParam(params, param string) bool {
+	for params != "" {
+		var p string
+		p, params, _ = strings.Cut(params, ";")
+
+		var key, value, _ = strings.Cut(p, "=")
+		key = strings.TrimSpace(key)
+		value = strings.Trim(strings.TrimSpace(value), `"`)
+
+		if key+"="+value == param {
+			return true
+		}
+	}
+	return false
+}
+
+// firstOffer returns the first offer or an empty string if there's no offers.
+func firstOffer(offers []string) string {
+	if len(offers) == 0 {
+		return ""
+	}
+	return offers[0]
+}
@@ -0,0 +1,76 @@
+package ekaweb_private_test
+
+import (
+	"testing"
+
+	"github.com/inaneverb/ekaweb/v2/private"
+)
+
+type negotiateTestCase struct {
+	header []string
+	offers []string
+	want   string
+}
+
+func runNegotiateTests(
+	t *testing.T, f func([]string, []string) string, tests []negotiateTestCase) {
+
+	for _, tt := range tests {
+		if got := f(tt.header, tt.offers); got != tt.want {
+			t.Errorf("header %q, offers %q: got %q, want %q",
+				tt.header, tt.offers, got, tt.want)
+		}
+	}
+}
+
+func TestNegotiateMediaType(t *testing.T) {
+	runNegotiateTests(t, ekaweb_private.NegotiateMediaType, []negotiateTestCase{
+		{nil, []string{"application/json", "text/html"}, "application/json"},
+		{[]string{""}, []string{"text/html"}, "text/html"},
+		{[]string{"text/html"}, []string{"application/json", "text/html"}, "text/html"},
+		{[]string{"*/*"}, []string{"application/json", "text/html"}, "application/json"},
+		{[]string{"text/*"}, []string{"application/json", "text/plain"}, "text/plain"},
+		{[]string{"text/*, application/json"}, []string{"text/html", "application/json"}, "application/json"},
+		{[]string{"application/json;q=0.5, application/xml"}, []string{"application/json", "application/xml"}, "application/xml"},
+		{[]string{"application/*;q=0.5", "application/xml;q=0"}, []string{"application/xml"}, ""},
+		{[]string{"text/*;q=0.3, text/html;q=0.7, text/html;level=1, */*;q=0.5"}, []string{"text/plain", "image/png", "text/html;level=1"}, "text/html;level=1"},
+		{[]string{"text/html;level=1"}, []string{"text/html"}, ""},
+		{[]string{"TEXT/HTML"}, []string{"text/html"}, "text/html"},
+		{[]string{"application/json;q=abc", "text/html"}, []string{"application/json"}, ""},
+		{[]string{"image/png"}, nil, ""},
+	})
+}
+
+func TestNegotiateCharset(t *testing.T) {
+	runNegotiateTests(t, ekaweb_private.NegotiateCharset, []negotiateTestCase{
+		{nil, []string{"utf-8"}, "utf-8"},
+		{[]string{"iso-8859-5, unicode-1-1;q=0.8"}, []string{"unicode-1-1", "iso-8859-5"}, "iso-8859-5"},
+		{[]string{"utf-8;q=0, *"}, []string{"utf-8", "koi8-r"}, "koi8-r"},
+		{[]string{"UTF-8"}, []string{"utf-8"}, "utf-8"},
+	})
+}
+
+func TestNegotiateEncoding(t *testing.T) {
+	runNegotiateTests(t, ekaweb_private.NegotiateEncoding, []negotiateTestCase{
+		{nil, []string{"gzip", "identity"}, "gzip"},
+		{[]string{""}, []string{"gzip", "identity"}, "identity"},
+		{[]string{"gzip"}, []string{"identity", "gzip"}, "gzip"},
+		{[]string{"br;q=1.0, gzip;q=0.8, *;q=0.1"}, []string{"gzip", "br"}, "br"},
+		{[]string{"deflate"}, []string{"gzip", "identity"}, "identity"},
+		{[]string{"identity;q=0"}, []string{"identity"}, ""},
+		{[]string{"*;q=0"}, []string{"identity", "gzip"}, ""},
+		{[]string{"*;q=0, identity;q=0.5"}, []string{"identity", "gzip"}, "identity"},
+	})
+}
+
+func TestNegotiateLanguage(t *testing.T) {
+	runNegotiateTests(t, ekaweb_private.NegotiateLanguage, []negotiateTestCase{
+		{nil, []string{"en"}, "en"},
+		{[]string{"da, en-gb;q=0.8, en;q=0.7"}, []string{"en-US", "en-GB", "da"}, "da"},
+		{[]string{"da, en-gb;q=0.8, en;q=0.7"}, []string{"en-US", "en-GB"}, "en-GB"},
+		{[]string{"en"}, []string{"ru", "en-US"}, "en-US"},
+		{[]string{"en-US"}, []string{"en"}, ""},
+		{[]string{"*;q=0.1, ru"}, []string{"de", "ru"}, "ru"},
+		{[]string{"ru;q=0, *"}, []string{"ru"}, ""},
+	})
+}
@@ -0,0 +1,38 @@
+package ekaweb_private
+
+import (
+	"sync"
+)
+
+// ServerDone is a common part of Server implementations, that reports
+// the server is stopped and why (see Server.Wait(), Server.Done()).
+type ServerDone struct {
+	ch   chan struct{}
+	once sync.Once
+	err  error
+}
+
+// NewServerDone returns a new ServerDone.
+func NewServerDone() *ServerDone {
+	return &ServerDone{ch: make(chan struct{})}
+}
+
+// Finish marks the server as stopped with the terminal error 'err'
+// (nil if it's stopped gracefully). Only the first call takes effect.
+func (d *ServerDone) Finish(err error) {
+	d.once.Do(func() {
+		d.err = err
+		close(d.ch)
+	})
+}
+
+// Done returns a channel, that is closed when the server is stopped.
+func (d *ServerDone) Done() <-chan struct{} {
+	return d.ch
+}
+
+// Wait blocks until the server is stopped and returns its terminal error.
+func (d *ServerDone) Wait() error {
+	<-d.ch
+	return d.err
+}
@@ -0,0 +1,29 @@
+package ekaweb_private_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/inaneverb/ekaweb/v2/private"
+)
+
+func TestServerDone(t *testing.T) {
+
+	var d = ekaweb_private.NewServerDone()
+
+	select {
+	case <-d.Done():
+		t.Fatal("done before Finish()")
+	default:
+	}
+
+	var errServe = errors.New("serve failed")
+
+	d.Finish(errServe)
+	d.Finish(nil) // ignored
+
+	<-d.Done()
+	require.ErrorIs(t, d.Wait(), errServe)
+}
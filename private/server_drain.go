@@ -0,0 +1,163 @@
+package ekaweb_private
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+type (
+	// ServerDrainer is a common part of the graceful shutdown
+	// of Server implementations. It keeps readiness state, counts in-flight
+	// HTTP requests (if Server cannot do it by itself) and holds callbacks,
+	// that should be called when the server is going away
+	// (e.g. to close hijacked or WebSocket connections).
+	//
+	// The graceful shutdown (see Server.StopContext()) is:
+	//   1. Drain(): flip readiness and wait the drain period, serving requests;
+	//   2. GoAway(): call shutdown callbacks;
+	//   3. Server specific shutdown, that waits in-flight requests.
+	ServerDrainer struct {
+		draining atomic.Bool
+		inFlight atomic.Int64
+
+		mu        sync.Mutex
+		callbacks map[uint64]func()
+		nextID    uint64
+		goneAway  bool
+	}
+
+	// _ServerDrainerKey is a key for context.Context to store ServerDrainer.
+	_ServerDrainerKey struct{}
+)
+
+// NewServerDrainer returns a new ServerDrainer.
+func NewServerDrainer() *ServerDrainer {
+	return &ServerDrainer{callbacks: make(map[uint64]func())}
+}
+
+// ServerDrainerFromContext returns ServerDrainer, that is stored
+// in 'ctx' by ServerDrainer.WithContext(), or nil if there's no one.
+func ServerDrainerFromContext(ctx context.Context) *ServerDrainer {
+	var d, _ = ctx.Value((*_ServerDrainerKey)(nil)).(*ServerDrainer)
+	return d
+}
+
+// ServerDrainerContextKey returns a key of context.Context, that holds
+// ServerDrainer. It's used by Server implementations, that cannot use
+// WithContext(), but can store values by keys in their own contexts.
+func ServerDrainerContextKey() any {
+	return (*_ServerDrainerKey)(nil)
+}
+
+// WithContext returns a copy of 'ctx', that holds ServerDrainer.
+// Server implementations should use it as a base context of HTTP requests.
+func (d *ServerDrainer) WithContext(ctx context.Context) context.Context {
+	return context.WithValue(ctx, (*_ServerDrainerKey)(nil), d)
+}
+
+// IsDraining reports whether the graceful shutdown is started.
+func (d *ServerDrainer) IsDraining() bool {
+	return d.draining.Load()
+}
+
+// Drain flips the readiness state and waits 'period' or until 'ctx' is done.
+// Meanwhile, HTTP requests are still served, so load balancers have a chance
+// to notice the server is not ready.
+func (d *ServerDrainer) Drain(ctx context.Context, period time.Duration) error {
+
+	d.draining.Store(true)
+
+	if period <= 0 {
+		return nil
+	}
+
+	var timer = time.NewTimer(period)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// OnShutdown registers a callback, that is called by GoAway().
+// If GoAway() has been called already, the callback is called immediately.
+// The returned function unregisters the callback (e.g. if the connection
+// is closed before the shutdown).
+func (d *ServerDrainer) OnShutdown(cb func()) (cancel func()) {
+
+	d.mu.Lock()
+
+	if d.goneAway {
+		d.mu.Unlock()
+		cb()
+		return func() {}
+	}
+
+	d.nextID++
+	var id = d.nextID
+	d.callbacks[id] = cb
+	d.mu.Unlock()
+
+	return func() {
+		d.mu.Lock()
+		delete(d.callbacks, id)
+		d.mu.Unlock()
+	}
+}
+
+// GoAway calls all callbacks, registered by OnShutdown(). Each callback
+// is called only once, even if GoAway() is called multiple times.
+func (d *ServerDrainer) GoAway() {
+
+	d.draining.Store(true)
+
+	d.mu.Lock()
+	var callbacks = d.callbacks
+	d.callbacks, d.goneAway = make(map[uint64]func()), true
+	d.mu.Unlock()
+
+	for _, cb := range callbacks {
+		cb()
+	}
+}
+
+// Handler returns http.Handler, that counts in-flight HTTP requests
+// (see Wait()) and closes keep-alive connections while draining.
+// It's used by the Server implementations, that don't do it by themselves.
+func (d *ServerDrainer) Handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+
+		d.inFlight.Add(1)
+		defer d.inFlight.Add(-1)
+
+		if d.draining.Load() {
+			w.Header().Set("Connection", "close")
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// Wait waits until there's no in-flight HTTP requests, counted by Handler(),
+// or until 'ctx' is done.
+func (d *ServerDrainer) Wait(ctx context.Context) error {
+
+	var ticker = time.NewTicker(50 * time.Millisecond)
+	defer ticker.Stop()
+
+	for d.inFlight.Load() > 0 {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+
+	return nil
+}
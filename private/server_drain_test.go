@@ -0,0 +1,82 @@
+package ekaweb_private_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/inaneverb/ekaweb/v2/private"
+)
+
+func TestServerDrainer_Drain(t *testing.T) {
+
+	var d = ekaweb_private.NewServerDrainer()
+	var ctx = d.WithContext(context.Background())
+
+	require.Same(t, d, ekaweb_private.ServerDrainerFromContext(ctx))
+	require.Nil(t, ekaweb_private.ServerDrainerFromContext(context.Background()))
+	require.False(t, d.IsDraining())
+
+	var start = time.Now()
+	require.NoError(t, d.Drain(context.Background(), 50*time.Millisecond))
+	require.GreaterOrEqual(t, time.Since(start), 50*time.Millisecond)
+	require.True(t, d.IsDraining())
+
+	var ctxCanceled, cancel = context.WithCancel(context.Background())
+	cancel()
+	require.ErrorIs(t, d.Drain(ctxCanceled, time.Hour), context.Canceled)
+}
+
+func TestServerDrainer_GoAway(t *testing.T) {
+
+	var d = ekaweb_private.NewServerDrainer()
+	var called [3]int
+
+	d.OnShutdown(func() { called[0]++ })
+	var cancel = d.OnShutdown(func() { called[1]++ })
+	cancel()
+
+	d.GoAway()
+	d.GoAway()
+
+	// Registered after GoAway(), called immediately.
+	d.OnShutdown(func() { called[2]++ })
+
+	require.Equal(t, [3]int{1, 0, 1}, called)
+	require.True(t, d.IsDraining())
+}
+
+func TestServerDrainer_Wait(t *testing.T) {
+
+	var d = ekaweb_private.NewServerDrainer()
+	var release = make(chan struct{})
+	var started = make(chan struct{}, 2)
+
+	var h = d.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		started <- struct{}{}
+		<-release
+	}))
+
+	var w = httptest.NewRecorder()
+	go h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+	<-started
+
+	var ctx, cancel = context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	require.ErrorIs(t, d.Wait(ctx), context.DeadlineExceeded)
+
+	close(release)
+	require.NoError(t, d.Wait(context.Background()))
+
+	// Keep-alive connections are closed while draining.
+
+	d.GoAway()
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+	require.Equal(t, "close", w.Header().Get("Connection"))
+}
@@ -0,0 +1,181 @@
+package ekaweb_private
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// ServerListeners is a common part of Server implementations, that collects
+// listening addresses (TCP and Unix sockets), pre-opened listeners
+// and systemd socket activation listeners, and opens all of them at once.
+type ServerListeners struct {
+	addrs     []ServerOptionListenAddr
+	listeners []net.Listener
+	systemd   bool
+}
+
+// UnixAddrPrefix is a prefix of listening address, that is Unix socket path
+// (e.g. "unix:/run/app.sock").
+const UnixAddrPrefix = "unix:"
+
+// AddAddr adds listening address (see ServerOptionListenAddr).
+// Empty address is ignored.
+func (l *ServerListeners) AddAddr(option *ServerOptionListenAddr) {
+	if option.Addr = strings.TrimSpace(option.Addr); option.Addr != "" {
+		l.addrs = append(l.addrs, *option)
+	}
+}
+
+// AddListener adds pre-opened listener. Nil listener is ignored.
+func (l *ServerListeners) AddListener(option *ServerOptionListener) {
+	if option.Listener != nil {
+		l.listeners = append(l.listeners, option.Listener)
+	}
+}
+
+// EnableSystemd enables listeners, passed by systemd socket activation.
+func (l *ServerListeners) EnableSystemd() {
+	l.systemd = true
+}
+
+// IsEmpty reports whether there's no listening addresses or listeners.
+// Server implementations use it to add their default listening address.
+func (l *ServerListeners) IsEmpty() bool {
+	return len(l.addrs) == 0 && len(l.listeners) == 0 && !l.systemd
+}
+
+// Listen opens all listeners. TCP addresses are opened using 'network'.
+// If systemd socket activation is enabled and there are passed listeners,
+// the listening addresses are not opened, since they're a fallback
+// for the process, that is started w/o socket activation.
+// If any listener cannot be opened, the already opened ones are closed
+// (except pre-opened ones) and the error is returned.
+func (l *ServerListeners) Listen(network string) ([]net.Listener, error) {
+
+	var opened []net.Listener
+	var closeOpened = func() {
+		for i, n := 0, len(opened); i < n; i++ {
+			_ = opened[i].Close()
+		}
+	}
+
+	var addrs = l.addrs
+
+	if l.systemd {
+		var listeners, err = SystemdListeners()
+		if err != nil {
+			return nil, err
+		}
+		if len(listeners) > 0 {
+			opened, addrs = listeners, nil
+		}
+	}
+
+	for i, n := 0, len(addrs); i < n; i++ {
+		var ln, err = listenAddr(network, &addrs[i])
+		if err != nil {
+			closeOpened()
+			return nil, err
+		}
+		opened = append(opened, ln)
+	}
+
+	var listeners = append(opened, l.listeners...)
+	if len(listeners) == 0 {
+		return nil, errors.New("no listeners (see WithListenAddr())")
+	}
+
+	return listeners, nil
+}
+
+// SystemdListeners returns listeners, passed by systemd socket activation
+// (see sd_listen_fds(3)), or nil if there's no one. The environment variables
+// are unset, so the listeners are not inherited by child processes.
+func SystemdListeners() ([]net.Listener, error) {
+
+	var pid, _ = strconv.Atoi(os.Getenv("LISTEN_PID"))
+	if pid != os.Getpid() {
+		return nil, nil
+	}
+
+	var fds = os.Getenv("LISTEN_FDS")
+	var n, err = strconv.Atoi(fds)
+	var names = strings.Split(os.Getenv("LISTEN_FDNAMES"), ":")
+
+	_ = os.Unsetenv("LISTEN_PID")
+	_ = os.Unsetenv("LISTEN_FDS")
+	_ = os.Unsetenv("LISTEN_FDNAMES")
+
+	if err != nil || n <= 0 {
+		return nil, fmt.Errorf("invalid LISTEN_FDS: %q", fds)
+	}
+
+	// The first passed file descriptor is always 3 (SD_LISTEN_FDS_START).
+	const FdStart = 3
+
+	var listeners = make([]net.Listener, 0, n)
+
+	for i := 0; i < n; i++ {
+		var name = "LISTEN_FD_" + strconv.Itoa(FdStart+i)
+		if i < len(names) && names[i] != "" {
+			name = names[i]
+		}
+
+		// net.FileListener() duplicates the file descriptor,
+		// so the original one is closed anyway.
+
+		var f = os.NewFile(uintptr(FdStart+i), name)
+		var ln, err = net.FileListener(f)
+		_ = f.Close()
+
+		if err != nil {
+			for j, m := 0, len(listeners); j < m; j++ {
+				_ = listeners[j].Close()
+			}
+			return nil, fmt.Errorf("invalid systemd listener (name: %s): %w", name, err)
+		}
+
+		listeners = append(listeners, ln)
+	}
+
+	return listeners, nil
+}
+
+////////////////////////////////////////////////////////////////////////////////
+///// PRIVATE METHODS //////////////////////////////////////////////////////////
+////////////////////////////////////////////////////////////////////////////////
+
+// listenAddr opens a listener for the address with UnixAddrPrefix
+// as Unix socket, replacing stale socket file and applying its mode,
+// or as TCP one using 'network' otherwise.
+func listenAddr(network string, option *ServerOptionListenAddr) (net.Listener, error) {
+
+	var path, isUnix = strings.CutPrefix(option.Addr, UnixAddrPrefix)
+	if !isUnix {
+		return net.Listen(network, option.Addr)
+	}
+
+	// The socket file is left, if the previous process has been crashed.
+
+	if fi, err := os.Lstat(path); err == nil && fi.Mode()&os.ModeSocket != 0 {
+		_ = os.Remove(path)
+	}
+
+	var ln, err = net.Listen("unix", path)
+	if err != nil {
+		return nil, err
+	}
+
+	if option.Mode != 0 {
+		if err = os.Chmod(path, option.Mode); err != nil {
+			_ = ln.Close()
+			return nil, err
+		}
+	}
+
+	return ln, nil
+}
@@ -0,0 +1,159 @@
+package ekaweb_private_test
+
+import (
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/inaneverb/ekaweb/v2/private"
+)
+
+func TestServerListeners(t *testing.T) {
+
+	var l ekaweb_private.ServerListeners
+	require.True(t, l.IsEmpty())
+
+	var preOpened, err = net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	var socket = filepath.Join(t.TempDir(), "app.sock")
+
+	// Stale socket file is replaced.
+
+	stale, err := net.Listen("unix", socket)
+	require.NoError(t, err)
+	stale.(*net.UnixListener).SetUnlinkOnClose(false)
+	require.NoError(t, stale.Close())
+
+	l.AddAddr(&ekaweb_private.ServerOptionListenAddr{Addr: " "})
+	require.True(t, l.IsEmpty())
+
+	l.AddAddr(&ekaweb_private.ServerOptionListenAddr{Addr: "127.0.0.1:0"})
+	l.AddAddr(&ekaweb_private.ServerOptionListenAddr{
+		Addr: ekaweb_private.UnixAddrPrefix + socket, Mode: 0o600,
+	})
+	l.AddListener(&ekaweb_private.ServerOptionListener{Listener: preOpened})
+	l.AddListener(&ekaweb_private.ServerOptionListener{})
+	require.False(t, l.IsEmpty())
+
+	listeners, err := l.Listen("tcp")
+	require.NoError(t, err)
+	require.Len(t, listeners, 3)
+
+	require.Equal(t, "tcp", listeners[0].Addr().Network())
+	require.Equal(t, "unix", listeners[1].Addr().Network())
+	require.Same(t, preOpened, listeners[2])
+
+	fi, err := os.Stat(socket)
+	require.NoError(t, err)
+	require.Equal(t, os.FileMode(0o600), fi.Mode().Perm())
+
+	conn, err := net.Dial("unix", socket)
+	require.NoError(t, err)
+	_ = conn.Close()
+
+	for i := range listeners {
+		require.NoError(t, listeners[i].Close())
+	}
+}
+
+func TestServerListeners_Error(t *testing.T) {
+
+	var busy, err = net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer busy.Close()
+
+	var socket = filepath.Join(t.TempDir(), "app.sock")
+
+	var l ekaweb_private.ServerListeners
+	l.AddAddr(&ekaweb_private.ServerOptionListenAddr{Addr: "unix:" + socket})
+	l.AddAddr(&ekaweb_private.ServerOptionListenAddr{Addr: busy.Addr().String()})
+
+	_, err = l.Listen("tcp")
+	require.ErrorContains(t, err, "address already in use")
+
+	// The already opened listener is closed and its socket file is removed.
+
+	_, err = os.Stat(socket)
+	require.ErrorIs(t, err, os.ErrNotExist)
+
+	l = ekaweb_private.ServerListeners{}
+	l.EnableSystemd()
+	require.False(t, l.IsEmpty())
+
+	_, err = l.Listen("tcp")
+	require.ErrorContains(t, err, "no listeners")
+}
+
+func TestSystemdListeners(t *testing.T) {
+
+	t.Setenv("LISTEN_PID", strconv.Itoa(os.Getpid()+1))
+	t.Setenv("LISTEN_FDS", "1")
+
+	var listeners, err = ekaweb_private.SystemdListeners()
+	require.NoError(t, err)
+	require.Nil(t, listeners)
+
+	t.Setenv("LISTEN_PID", strconv.Itoa(os.Getpid()))
+	t.Setenv("LISTEN_FDS", "x")
+
+	_, err = ekaweb_private.SystemdListeners()
+	require.ErrorContains(t, err, `invalid LISTEN_FDS: "x"`)
+
+	var _, ok = os.LookupEnv("LISTEN_PID")
+	require.False(t, ok)
+}
+
+func TestServerListeners_SystemdFallback(t *testing.T) {
+
+	// The child process gets the socket as the file descriptor 3
+	// (SD_LISTEN_FDS_START), the same way systemd passes it.
+
+	if addr := os.Getenv("EKAWEB_TEST_SYSTEMD_ADDR"); addr != "" {
+		t.Setenv("LISTEN_PID", strconv.Itoa(os.Getpid()))
+		t.Setenv("LISTEN_FDS", "1")
+
+		// The same address as the fallback: it's busy by the passed socket.
+
+		var l ekaweb_private.ServerListeners
+		l.EnableSystemd()
+		l.AddAddr(&ekaweb_private.ServerOptionListenAddr{Addr: addr})
+
+		var listeners, err = l.Listen("tcp")
+		require.NoError(t, err)
+		require.Len(t, listeners, 1)
+		require.Equal(t, addr, listeners[0].Addr().String())
+		return
+	}
+
+	var ln, err = net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer ln.Close()
+
+	f, err := ln.(*net.TCPListener).File()
+	require.NoError(t, err)
+	defer f.Close()
+
+	var cmd = exec.Command(os.Args[0], "-test.run=^TestServerListeners_SystemdFallback$")
+	cmd.Env = append(os.Environ(), "EKAWEB_TEST_SYSTEMD_ADDR="+ln.Addr().String())
+	cmd.ExtraFiles = []*os.File{f}
+
+	var out, errRun = cmd.CombinedOutput()
+	require.NoError(t, errRun, string(out))
+
+	// W/o socket activation the fallback address is opened.
+
+	var l ekaweb_private.ServerListeners
+	l.EnableSystemd()
+	l.AddAddr(&ekaweb_private.ServerOptionListenAddr{Addr: "127.0.0.1:0"})
+
+	listeners, err := l.Listen("tcp")
+	require.NoError(t, err)
+	require.Len(t, listeners, 1)
+	require.NoError(t, listeners[0].Close())
+}
@@ -0,0 +1,227 @@
+package ekaweb_private
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+type (
+	// CertReloader holds TLS certificate, loaded from PEM encoded
+	// certificate and key files, and reloads it when the files are changed,
+	// so certificates can be rotated without restarting the Server.
+	//
+	// Use GetCertificate() as tls.Config.GetCertificate.
+	CertReloader struct {
+		certFile string
+		keyFile  string
+
+		cert atomic.Pointer[tls.Certificate]
+
+		mu      sync.Mutex
+		certMod _CertFileStamp
+		keyMod  _CertFileStamp
+	}
+
+	// ServerTLS is a common part of Server implementations, that serve HTTPS.
+	// It's built from ServerOptionTLS, ServerOptionClientAuth
+	// and runs CertReloader while the Server is running.
+	//
+	// All methods are no-op for nil ServerTLS (TLS is not enabled).
+	ServerTLS struct {
+		Config *tls.Config
+
+		reloader *CertReloader
+		interval time.Duration
+		cancel   context.CancelFunc
+	}
+
+	// _CertFileStamp is a state of file, that is used to detect its changes.
+	_CertFileStamp struct {
+		modTime time.Time
+		size    int64
+	}
+)
+
+// NewCertReloader loads the certificate from 'certFile', 'keyFile'
+// and returns CertReloader, that holds it. Call Reload() or Watch()
+// to reload the certificate, when the files are changed.
+func NewCertReloader(certFile, keyFile string) (*CertReloader, error) {
+
+	var r = CertReloader{certFile: certFile, keyFile: keyFile}
+	if _, err := r.Reload(); err != nil {
+		return nil, err
+	}
+
+	return &r, nil
+}
+
+// Certificate returns the current certificate.
+func (r *CertReloader) Certificate() *tls.Certificate {
+	return r.cert.Load()
+}
+
+// GetCertificate is a tls.Config.GetCertificate callback,
+// that returns the current certificate.
+func (r *CertReloader) GetCertificate(_ *tls.ClientHelloInfo) (*tls.Certificate, error) {
+	return r.cert.Load(), nil
+}
+
+// Reload loads the certificate again, if any of the files is changed
+// since the last successful loading. Reports whether the certificate
+// is replaced. If the files are invalid (e.g. they're written
+// right at the moment), the current certificate is kept.
+func (r *CertReloader) Reload() (bool, error) {
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var certMod, errCert = statCertFile(r.certFile)
+	var keyMod, errKey = statCertFile(r.keyFile)
+
+	if err := errors.Join(errCert, errKey); err != nil {
+		return false, fmt.Errorf("failed to stat TLS certificate: %w", err)
+	}
+
+	if r.cert.Load() != nil && certMod == r.certMod && keyMod == r.keyMod {
+		return false, nil
+	}
+
+	var cert, err = tls.LoadX509KeyPair(r.certFile, r.keyFile)
+	if err != nil {
+		return false, fmt.Errorf("failed to load TLS certificate: %w", err)
+	}
+
+	r.cert.Store(&cert)
+	r.certMod, r.keyMod = certMod, keyMod
+
+	return true, nil
+}
+
+// Watch calls Reload() each 'interval' until 'ctx' is done.
+// Reloading errors are passed to 'onError', if it's not nil.
+func (r *CertReloader) Watch(
+	ctx context.Context, interval time.Duration, onError func(error)) {
+
+	var ticker = time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		if _, err := r.Reload(); err != nil && onError != nil {
+			onError(err)
+		}
+	}
+}
+
+// LoadCertPool returns x509.CertPool with PEM encoded certificates
+// from 'files'. It's intended for ServerOptionClientAuth.
+func LoadCertPool(files ...string) (*x509.CertPool, error) {
+
+	var pool = x509.NewCertPool()
+
+	for i, n := 0, len(files); i < n; i++ {
+		var data, err = os.ReadFile(files[i])
+		if err != nil {
+			return nil, err
+		}
+		if !pool.AppendCertsFromPEM(data) {
+			return nil, fmt.Errorf("no PEM certificates (file: %s)", files[i])
+		}
+	}
+
+	return pool, nil
+}
+
+// NewServerTLS returns ServerTLS, built from given options,
+// or nil if there's no ServerOptionTLS. The 'tlsOption.Config' is not modified.
+func NewServerTLS(
+	tlsOption *ServerOptionTLS, authOption *ServerOptionClientAuth) (*ServerTLS, error) {
+
+	if tlsOption == nil {
+		if authOption != nil {
+			return nil, errors.New("client auth requires TLS (see WithTLS())")
+		}
+		return nil, nil
+	}
+
+	var t = ServerTLS{Config: new(tls.Config)}
+	if tlsOption.Config != nil {
+		t.Config = tlsOption.Config.Clone()
+	}
+
+	if tlsOption.CertFile != "" || tlsOption.KeyFile != "" {
+		var err error
+		if t.reloader, err = NewCertReloader(tlsOption.CertFile, tlsOption.KeyFile); err != nil {
+			return nil, err
+		}
+		t.Config.GetCertificate = t.reloader.GetCertificate
+		t.interval = tlsOption.ReloadInterval
+	}
+
+	if len(t.Config.Certificates) == 0 && t.Config.GetCertificate == nil &&
+		t.Config.GetConfigForClient == nil {
+		return nil, errors.New("no TLS certificate")
+	}
+
+	if authOption != nil {
+		t.Config.ClientCAs = authOption.CAs
+		t.Config.ClientAuth = tls.VerifyClientCertIfGiven
+
+		if authOption.Required {
+			t.Config.ClientAuth = tls.RequireAndVerifyClientCert
+		}
+	}
+
+	if t.Config.MinVersion == 0 {
+		t.Config.MinVersion = tls.VersionTLS12
+	}
+
+	return &t, nil
+}
+
+// Start starts reloading the certificate files in the background,
+// if it's enabled. Reloading errors are passed to 'onError'.
+func (t *ServerTLS) Start(onError func(error)) {
+
+	if t == nil || t.reloader == nil || t.interval <= 0 || t.cancel != nil {
+		return
+	}
+
+	var ctx context.Context
+	ctx, t.cancel = context.WithCancel(context.Background())
+
+	go t.reloader.Watch(ctx, t.interval, onError)
+}
+
+// Stop stops reloading the certificate files.
+func (t *ServerTLS) Stop() {
+	if t != nil && t.cancel != nil {
+		t.cancel()
+	}
+}
+
+////////////////////////////////////////////////////////////////////////////////
+///// PRIVATE METHODS //////////////////////////////////////////////////////////
+////////////////////////////////////////////////////////////////////////////////
+
+func statCertFile(name string) (_CertFileStamp, error) {
+
+	var fi, err = os.Stat(name)
+	if err != nil {
+		return _CertFileStamp{}, err
+	}
+
+	return _CertFileStamp{modTime: fi.ModTime(), size: fi.Size()}, nil
+}
@@ -0,0 +1,142 @@
+package ekaweb_private_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/inaneverb/ekaweb/v2/private"
+)
+
+// writeCert writes a new self-signed certificate with 'cn' common name
+// and its key to the PEM encoded files in 'dir'.
+func writeCert(t *testing.T, dir, cn string) (certFile, keyFile string) {
+
+	var key, err = ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	var tmpl = x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: cn},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		IsCA:         true,
+		KeyUsage:     x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+
+		BasicConstraintsValid: true,
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, &tmpl, &tmpl, &key.PublicKey, key)
+	require.NoError(t, err)
+
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+
+	certFile = filepath.Join(dir, "cert.pem")
+	keyFile = filepath.Join(dir, "key.pem")
+
+	var certPEM = pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	var keyPEM = pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+
+	require.NoError(t, os.WriteFile(certFile, certPEM, 0o600))
+	require.NoError(t, os.WriteFile(keyFile, keyPEM, 0o600))
+
+	return certFile, keyFile
+}
+
+func commonName(t *testing.T, cert *tls.Certificate) string {
+	var leaf, err = x509.ParseCertificate(cert.Certificate[0])
+	require.NoError(t, err)
+	return leaf.Subject.CommonName
+}
+
+func TestCertReloader(t *testing.T) {
+
+	var dir = t.TempDir()
+	var certFile, keyFile = writeCert(t, dir, "first")
+
+	var r, err = ekaweb_private.NewCertReloader(certFile, keyFile)
+	require.NoError(t, err)
+	require.Equal(t, "first", commonName(t, r.Certificate()))
+
+	reloaded, err := r.Reload()
+	require.NoError(t, err)
+	require.False(t, reloaded)
+
+	// Make sure the modification time is changed even on coarse filesystems.
+
+	writeCert(t, dir, "second")
+	var future = time.Now().Add(time.Minute)
+	require.NoError(t, os.Chtimes(certFile, future, future))
+
+	reloaded, err = r.Reload()
+	require.NoError(t, err)
+	require.True(t, reloaded)
+
+	var cert, _ = r.GetCertificate(nil)
+	require.Equal(t, "second", commonName(t, cert))
+
+	// Broken files don't replace the current certificate.
+
+	require.NoError(t, os.WriteFile(keyFile, []byte("broken"), 0o600))
+
+	reloaded, err = r.Reload()
+	require.ErrorContains(t, err, "failed to load TLS certificate")
+	require.False(t, reloaded)
+	require.Equal(t, "second", commonName(t, r.Certificate()))
+
+	_, err = ekaweb_private.NewCertReloader(filepath.Join(dir, "none"), keyFile)
+	require.ErrorContains(t, err, "failed to stat TLS certificate")
+}
+
+func TestNewServerTLS(t *testing.T) {
+
+	var certFile, keyFile = writeCert(t, t.TempDir(), "server")
+
+	var s, err = ekaweb_private.NewServerTLS(nil, nil)
+	require.NoError(t, err)
+	require.Nil(t, s)
+
+	s.Start(nil) // no-op for nil
+	s.Stop()
+
+	_, err = ekaweb_private.NewServerTLS(nil, &ekaweb_private.ServerOptionClientAuth{})
+	require.ErrorContains(t, err, "client auth requires TLS")
+
+	_, err = ekaweb_private.NewServerTLS(&ekaweb_private.ServerOptionTLS{}, nil)
+	require.ErrorContains(t, err, "no TLS certificate")
+
+	var cas, _ = ekaweb_private.LoadCertPool(certFile)
+	require.NotNil(t, cas)
+
+	s, err = ekaweb_private.NewServerTLS(
+		&ekaweb_private.ServerOptionTLS{
+			CertFile: certFile, KeyFile: keyFile, ReloadInterval: time.Second,
+		},
+		&ekaweb_private.ServerOptionClientAuth{CAs: cas, Required: true},
+	)
+
+	require.NoError(t, err)
+	require.NotNil(t, s.Config.GetCertificate)
+	require.Equal(t, tls.RequireAndVerifyClientCert, s.Config.ClientAuth)
+	require.Same(t, cas, s.Config.ClientCAs)
+	require.Equal(t, uint16(tls.VersionTLS12), s.Config.MinVersion)
+
+	s.Start(nil)
+	s.Stop()
+
+	_, err = ekaweb_private.LoadCertPool(keyFile)
+	require.ErrorContains(t, err, "no PEM certificates")
+}
@@ -2,6 +2,7 @@ package ekaweb_private
 
 import (
 	"context"
+	"io"
 	"net/http"
 )
 
@@ -14,7 +15,57 @@ type ClientResponse interface {
 	FromData(statusCode int, data []byte) error
 }
 
+// ClientResponseHeaders is an optional interface of ClientResponse.
+// If it's implemented, Client calls FromHeaders() before FromData(),
+// passing HTTP status code, headers and trailers (if any) of the HTTP response.
+type ClientResponseHeaders interface {
+	FromHeaders(statusCode int, header, trailer http.Header)
+}
+
+// ClientRequestStream is an optional interface of ClientRequest.
+// If it's implemented, Client sends HTTP request body from the io.Reader
+// returned by Stream() w/o full buffering, instead of calling Data().
+// The 'length' is the size of the body or -1 if it's unknown.
+type ClientRequestStream interface {
+	Stream() (body io.Reader, length int64, err error)
+}
+
+// ClientResponseStream is an optional interface of ClientResponse.
+// If it's implemented, Client calls FromStream() instead of FromData(),
+// passing HTTP response body as io.Reader w/o full buffering.
+// The body is valid only until FromStream() returns.
+type ClientResponseStream interface {
+	FromStream(statusCode int, header http.Header, body io.Reader) error
+}
+
 type Client interface {
 	Do(ctx context.Context, method, path string, headers http.Header,
 		req ClientRequest, resp ClientResponse) error
 }
+
+// ClientFunc is an adapter to allow the use of ordinary functions as Client.
+type ClientFunc func(ctx context.Context, method, path string,
+	headers http.Header, req ClientRequest, resp ClientResponse) error
+
+// ClientInterceptor wraps Client, like Middleware wraps Handler,
+// allowing to modify outgoing requests (e.g. headers) and analyze results
+// of each Client.Do() call.
+type ClientInterceptor func(next Client) Client
+
+func (f ClientFunc) Do(ctx context.Context, method, path string,
+	headers http.Header, req ClientRequest, resp ClientResponse) error {
+
+	return f(ctx, method, path, headers, req, resp)
+}
+
+// WrapClient wraps given Client by interceptors. The first interceptor
+// is the outermost one: it's called first and it sees the final result.
+// Nil interceptors are ignored.
+func WrapClient(client Client, interceptors ...ClientInterceptor) Client {
+	for i := len(interceptors) - 1; i >= 0; i-- {
+		if interceptors[i] != nil {
+			client = interceptors[i](client)
+		}
+	}
+	return client
+}
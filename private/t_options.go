@@ -1,7 +1,11 @@
 package ekaweb_private
 
 import (
+	"crypto/tls"
+	"crypto/x509"
+	"net"
 	"net/http"
+	"os"
 	"time"
 )
 
@@ -18,6 +22,10 @@ type ClientOptionUserAgent struct {
 	UserAgent string
 }
 
+type ClientOptionInterceptors struct {
+	Interceptors []ClientInterceptor
+}
+
 func (o *ClientOptionHostAddr) Name() string {
 	return "WithHostAddr"
 }
@@ -26,8 +34,13 @@ func (o *ClientOptionUserAgent) Name() string {
 	return "WithUserAgent"
 }
 
-func (o *ClientOptionHostAddr) noOneCanImplementClientOptionInterface()  {}
-func (o *ClientOptionUserAgent) noOneCanImplementClientOptionInterface() {}
+func (o *ClientOptionInterceptors) Name() string {
+	return "WithInterceptors"
+}
+
+func (o *ClientOptionHostAddr) noOneCanImplementClientOptionInterface()     {}
+func (o *ClientOptionUserAgent) noOneCanImplementClientOptionInterface()    {}
+func (o *ClientOptionInterceptors) noOneCanImplementClientOptionInterface() {}
 
 ////////////////////////////////////////////////////////////////////////////////
 
@@ -36,25 +49,41 @@ type ServerOption interface {
 	noOneCanImplementServerOptionInterface()
 }
 
-type ServerOptionTransport struct {
-	Transport http.RoundTripper
-}
-
 type ServerOptionHandler struct {
 	Handler Handler
 }
 
 type ServerOptionListenAddr struct {
 	Addr string
+	Mode os.FileMode // Unix socket file mode, if not 0
+}
+
+type ServerOptionListener struct {
+	Listener net.Listener
 }
 
+type ServerOptionSystemd struct{}
+
 type ServerOptionKeepAlive struct {
 	Enabled  bool
 	Duration time.Duration
 }
 
-func (o *ServerOptionTransport) Name() string {
-	return "WithTransport"
+type ServerOptionShutdown struct {
+	DrainPeriod time.Duration
+	Timeout     time.Duration
+}
+
+type ServerOptionTLS struct {
+	Config         *tls.Config
+	CertFile       string
+	KeyFile        string
+	ReloadInterval time.Duration
+}
+
+type ServerOptionClientAuth struct {
+	CAs      *x509.CertPool
+	Required bool
 }
 
 func (o *ServerOptionHandler) Name() string {
@@ -65,14 +94,38 @@ func (o *ServerOptionListenAddr) Name() string {
 	return "WithListenAddr"
 }
 
+func (o *ServerOptionListener) Name() string {
+	return "WithListener"
+}
+
+func (o *ServerOptionSystemd) Name() string {
+	return "WithSystemdListeners"
+}
+
 func (o *ServerOptionKeepAlive) Name() string {
 	return "WithKeepAlive"
 }
 
-func (o *ServerOptionTransport) noOneCanImplementServerOptionInterface()  {}
+func (o *ServerOptionShutdown) Name() string {
+	return "WithShutdown"
+}
+
+func (o *ServerOptionTLS) Name() string {
+	return "WithTLS"
+}
+
+func (o *ServerOptionClientAuth) Name() string {
+	return "WithClientAuth"
+}
+
 func (o *ServerOptionHandler) noOneCanImplementServerOptionInterface()    {}
 func (o *ServerOptionListenAddr) noOneCanImplementServerOptionInterface() {}
+func (o *ServerOptionListener) noOneCanImplementServerOptionInterface()   {}
+func (o *ServerOptionSystemd) noOneCanImplementServerOptionInterface()    {}
 func (o *ServerOptionKeepAlive) noOneCanImplementServerOptionInterface()  {}
+func (o *ServerOptionShutdown) noOneCanImplementServerOptionInterface()   {}
+func (o *ServerOptionTLS) noOneCanImplementServerOptionInterface()        {}
+func (o *ServerOptionClientAuth) noOneCanImplementServerOptionInterface() {}
 
 ////////////////////////////////////////////////////////////////////////////////
 
@@ -91,6 +144,16 @@ type ClientServerOptionLogger struct {
 	Log Logger
 }
 
+type ClientServerOptionTransport struct {
+	Transport http.RoundTripper
+}
+
+type ClientServerOptionHTTP2 struct {
+	H2C                  bool
+	MaxConcurrentStreams int
+	MaxReadFrameSize     int
+}
+
 func (o *ClientServerOptionTimeout) Name() string {
 	return "WithTimeouts"
 }
@@ -99,7 +162,22 @@ func (o *ClientServerOptionLogger) Name() string {
 	return "WithLogger"
 }
 
-func (o *ClientServerOptionTimeout) noOneCanImplementClientOptionInterface() {}
-func (o *ClientServerOptionTimeout) noOneCanImplementServerOptionInterface() {}
-func (o *ClientServerOptionLogger) noOneCanImplementClientOptionInterface()  {}
-func (o *ClientServerOptionLogger) noOneCanImplementServerOptionInterface()  {}
+func (o *ClientServerOptionTransport) Name() string {
+	return "WithTransport"
+}
+
+func (o *ClientServerOptionHTTP2) Name() string {
+	if o.H2C {
+		return "WithH2C"
+	}
+	return "WithHTTP2"
+}
+
+func (o *ClientServerOptionTimeout) noOneCanImplementClientOptionInterface()   {}
+func (o *ClientServerOptionTimeout) noOneCanImplementServerOptionInterface()   {}
+func (o *ClientServerOptionLogger) noOneCanImplementClientOptionInterface()    {}
+func (o *ClientServerOptionLogger) noOneCanImplementServerOptionInterface()    {}
+func (o *ClientServerOptionTransport) noOneCanImplementClientOptionInterface() {}
+func (o *ClientServerOptionTransport) noOneCanImplementServerOptionInterface() {}
+func (o *ClientServerOptionHTTP2) noOneCanImplementClientOptionInterface()     {}
+func (o *ClientServerOptionHTTP2) noOneCanImplementServerOptionInterface()     {}
@@ -50,6 +50,15 @@ type (
 
 	EncoderGetter = func(w io.Writer) Encoder // generic-less aliases
 	DecoderGetter = func(r io.Reader) Decoder // generic-less aliases
+
+	// Codec is an encoder and decoder getters, that are bound to some
+	// MIME type. Any of getters may be nil, meaning that codec is only
+	// for decoding or encoding.
+	Codec struct {
+		MIMEType      string
+		EncoderGetter EncoderGetter
+		DecoderGetter DecoderGetter
+	}
 )
 
 ////////////////////////////////////////////////////////////////////////////////
@@ -68,6 +77,7 @@ type (
 	RouterOptionCodec struct {
 		EncoderGetter EncoderGetter
 		DecoderGetter DecoderGetter
+		Codecs        []Codec // optional, ordered by priority
 	}
 
 	RouterOptionServerName struct {
@@ -1,6 +1,29 @@
 package ekaweb_private
 
+import (
+	"context"
+)
+
 type Server interface {
+
+	// AsyncStart binds the listener synchronously, returning its error,
+	// and then serves HTTP requests in the background.
 	AsyncStart() error
+
+	// Stop is the same as StopContext() with the shutdown timeout
+	// (see ServerOptionShutdown).
 	Stop() error
+
+	// StopContext gracefully shuts down the server: it flips readiness,
+	// waits the drain period, notifies hijacked (e.g. WebSocket) connections
+	// and waits in-flight HTTP requests until 'ctx' is done.
+	StopContext(ctx context.Context) error
+
+	// Done returns a channel, that is closed when the server is stopped,
+	// either by Stop(), StopContext() or because of serving error.
+	Done() <-chan struct{}
+
+	// Wait blocks until the server is stopped and returns the terminal
+	// serving error, or nil if the server is stopped by Stop(), StopContext().
+	Wait() error
 }
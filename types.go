@@ -30,5 +30,15 @@ type ClientServerOption = ekaweb_private.ClientServerOption
 type ErrorHandler = ekaweb_private.ErrorHandler
 type ErrorHandlerHTTP = ekaweb_private.ErrorHandlerHTTP
 
+type Codec = ekaweb_private.Codec
+
 type ClientRequest = ekaweb_private.ClientRequest
 type ClientResponse = ekaweb_private.ClientResponse
+type ClientResponseHeaders = ekaweb_private.ClientResponseHeaders
+type ClientRequestStream = ekaweb_private.ClientRequestStream
+type ClientResponseStream = ekaweb_private.ClientResponseStream
+
+type ClientFunc = ekaweb_private.ClientFunc
+type ClientInterceptor = ekaweb_private.ClientInterceptor
+
+type CertReloader = ekaweb_private.CertReloader